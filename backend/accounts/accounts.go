@@ -0,0 +1,325 @@
+// Package accounts purges the data of users who have confirmed that they want
+// to delete their What Got Done accounts.
+package accounts
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// errEmptyTeam stops a team update that would leave the team without members,
+// so that the purge deletes the team instead.
+var errEmptyTeam = errors.New("team has no remaining members")
+
+// Purger deletes users' data.
+type Purger struct {
+	datastore datastore.Datastore
+}
+
+// New creates a Purger that deletes data from the given datastore.
+func New(ds datastore.Datastore) Purger {
+	return Purger{
+		datastore: ds,
+	}
+}
+
+// PurgeConfirmed purges the data of every user who has confirmed a request to
+// delete their account, then marks each request completed. If a purge fails
+// partway through, its request stays confirmed so that the next call retries
+// it. Every step of a purge tolerates data that is already gone, so retrying
+// is safe.
+func (p Purger) PurgeConfirmed(now time.Time) error {
+	deletions, err := p.datastore.GetConfirmedAccountDeletions()
+	if err != nil {
+		return err
+	}
+	for _, d := range deletions {
+		deleted, err := p.Purge(d.Username)
+		if err != nil {
+			// Log and continue so that one failure doesn't block other deletions.
+			log.Printf("Failed to purge data for %s: %s", d.Username, err)
+			continue
+		}
+		d.Status = types.AccountDeletionCompleted
+		d.CompletedAt = now.UTC().Format(time.RFC3339)
+		d.Deleted = deleted
+		if err := p.datastore.SetAccountDeletion(d); err != nil {
+			log.Printf("Failed to record completed deletion for %s: %s", d.Username, err)
+		}
+	}
+	return nil
+}
+
+// Purge deletes the user's entries, drafts, and profile, the reactions,
+// comments, and page views on their entries, and the reactions and comments
+// they left on other users' entries. It also deletes their notifications,
+// follows, digest subscription, webhooks, Slack integration, and email
+// settings, and it removes them from teams and from other users' follows and
+// digests. It returns the number of records that it deleted, by kind.
+func (p Purger) Purge(username string) (map[string]int, error) {
+	deleted := map[string]int{}
+
+	entries, err := p.datastore.GetEntries(username)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range entries {
+		reactions, err := p.datastore.GetReactions(username, j.Date)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reactions {
+			if err := p.datastore.DeleteReaction(username, j.Date, r.Username); err != nil {
+				return nil, err
+			}
+			deleted["reactionsReceived"]++
+		}
+		comments, err := p.datastore.GetComments(username, j.Date)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			if err := p.datastore.DeleteComment(username, j.Date, c.ID); err != nil {
+				return nil, err
+			}
+			deleted["commentsReceived"]++
+		}
+		path := "/" + username + "/" + j.Date
+		_, err = p.datastore.GetPageViews(path)
+		if err == nil {
+			deleted["pageViews"]++
+		} else if _, ok := err.(datastore.PageViewsNotFoundError); !ok {
+			return nil, err
+		}
+		if err := p.datastore.DeletePageViews(path); err != nil {
+			return nil, err
+		}
+	}
+	// Delete the entries only after the data that hangs off them, since we find
+	// that data through the entries.
+	if err := p.datastore.DeleteEntries(username); err != nil {
+		return nil, err
+	}
+	deleted["entries"] = len(entries)
+
+	drafts, err := p.datastore.GetDrafts(username)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.datastore.DeleteDrafts(username); err != nil {
+		return nil, err
+	}
+	deleted["drafts"] = len(drafts)
+
+	_, err = p.datastore.GetUserProfile(username)
+	if err == nil {
+		deleted["profile"] = 1
+	} else if _, ok := err.(datastore.UserProfileNotFoundError); !ok {
+		return nil, err
+	}
+	if err := p.datastore.DeleteUserProfile(username); err != nil {
+		return nil, err
+	}
+
+	given, err := p.datastore.GetReactionsByUser(username)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range given {
+		if err := p.datastore.DeleteReaction(r.EntryAuthor, r.EntryDate, username); err != nil {
+			return nil, err
+		}
+		deleted["reactionsGiven"]++
+	}
+
+	written, err := p.datastore.GetCommentsByUser(username)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range written {
+		if err := p.removeComment(c); err != nil {
+			return nil, err
+		}
+		deleted["commentsWritten"]++
+	}
+
+	notifications, err := p.datastore.GetNotificationsInvolving(username)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notifications {
+		if err := p.datastore.DeleteNotification(n.ID); err != nil {
+			return nil, err
+		}
+		deleted["notifications"]++
+	}
+
+	if err := p.datastore.DeleteUserStats(username); err != nil {
+		return nil, err
+	}
+
+	if err := p.leaveTeams(username, deleted); err != nil {
+		return nil, err
+	}
+	if err := p.removeSubscriptions(username, deleted); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// removeComment deletes a comment that the user left on another user's entry.
+// If other comments reply to it, it leaves the same content-free placeholder
+// that deleting a comment through the site does, so that the replies stay in
+// the thread.
+func (p Purger) removeComment(c types.EntryComment) error {
+	comments, err := p.datastore.GetComments(c.EntryAuthor, c.EntryDate)
+	if err != nil {
+		return err
+	}
+	for _, other := range comments {
+		if other.ParentID == c.ID {
+			return p.datastore.SetComment(c.EntryAuthor, c.EntryDate, types.Comment{
+				ID:        c.ID,
+				ParentID:  c.ParentID,
+				Timestamp: c.Timestamp,
+				Deleted:   true,
+			})
+		}
+	}
+	return p.datastore.DeleteComment(c.EntryAuthor, c.EntryDate, c.ID)
+}
+
+// leaveTeams removes the user from every team that they belong to or have been
+// invited to join. If the user was a team's only admin, the team's earliest
+// remaining member becomes its admin. If no members remain, it deletes the
+// team.
+func (p Purger) leaveTeams(username string, deleted map[string]int) error {
+	teams, err := p.datastore.GetTeamsForUser(username)
+	if err != nil {
+		return err
+	}
+	for _, team := range teams {
+		_, err := p.datastore.UpdateTeam(team.ID, func(t *types.Team) error {
+			members := []types.TeamMember{}
+			hasAdmin := false
+			for _, m := range t.Members {
+				if m.Username == username {
+					continue
+				}
+				if m.Role == types.TeamRoleAdmin {
+					hasAdmin = true
+				}
+				members = append(members, m)
+			}
+			if len(members) == 0 {
+				return errEmptyTeam
+			}
+			if !hasAdmin {
+				members[0].Role = types.TeamRoleAdmin
+			}
+			invitees := []string{}
+			for _, invitee := range t.Invitees {
+				if invitee != username {
+					invitees = append(invitees, invitee)
+				}
+			}
+			t.Members = members
+			t.Invitees = invitees
+			return nil
+		})
+		if _, ok := err.(datastore.TeamNotFoundError); ok {
+			continue
+		} else if err == errEmptyTeam {
+			if err := p.datastore.DeleteSlackIntegration(types.TeamSlackOwner(team.ID)); err != nil {
+				return err
+			}
+			if err := p.datastore.DeleteTeam(team.ID); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		deleted["teamMemberships"]++
+	}
+	return nil
+}
+
+// removeSubscriptions stops everything that would otherwise keep contacting
+// the user or acting on their behalf after their account is gone.
+func (p Purger) removeSubscriptions(username string, deleted map[string]int) error {
+	following, err := p.datastore.GetFollowing(username)
+	if err != nil {
+		return err
+	}
+	for _, followee := range following {
+		if err := p.datastore.Unfollow(username, followee); err != nil {
+			return err
+		}
+		deleted["follows"]++
+	}
+	followers, err := p.datastore.GetFollowers(username)
+	if err != nil {
+		return err
+	}
+	for _, follower := range followers {
+		if err := p.datastore.Unfollow(follower, username); err != nil {
+			return err
+		}
+		deleted["followers"]++
+	}
+
+	if err := p.datastore.DeleteDigestSubscription(username); err != nil {
+		return err
+	}
+	subs, err := p.datastore.GetDigestSubscriptions()
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		authors := []string{}
+		for _, author := range sub.Authors {
+			if author != username {
+				authors = append(authors, author)
+			}
+		}
+		if len(authors) == len(sub.Authors) {
+			continue
+		}
+		deleted["digestAuthors"]++
+		// A digest with no authors covers everyone the subscriber follows, so
+		// rather than widen a digest of only this user, delete it.
+		if len(authors) == 0 {
+			if err := p.datastore.DeleteDigestSubscription(sub.Username); err != nil {
+				return err
+			}
+			continue
+		}
+		sub.Authors = authors
+		if err := p.datastore.SetDigestSubscription(sub.Username, sub); err != nil {
+			return err
+		}
+	}
+	if err := p.datastore.DeleteSlackIntegration(types.UserSlackOwner(username)); err != nil {
+		return err
+	}
+
+	hooks, err := p.datastore.GetWebhooks(username)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if err := p.datastore.DeleteWebhook(username, hook.ID); err != nil {
+			return err
+		}
+		deleted["webhooks"]++
+	}
+
+	if err := p.datastore.DeleteReminderPreferences(username); err != nil {
+		return err
+	}
+	return p.datastore.DeleteNotificationPreferences(username)
+}
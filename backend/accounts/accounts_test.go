@@ -0,0 +1,526 @@
+package accounts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that purges use.
+	datastore.Datastore
+	entries       map[string][]types.JournalEntry
+	drafts        map[string][]types.JournalEntry
+	profiles      map[string]types.UserProfile
+	reactions     map[string][]types.Reaction
+	comments      map[string][]types.Comment
+	pageViews     map[string]int
+	notifications map[string]types.Notification
+	teams         map[string]types.Team
+	following     map[string][]string
+	digests       map[string]types.DigestSubscription
+	webhooks      []types.Webhook
+	deliveries    []types.WebhookDelivery
+	slack         map[string]bool
+	reminders     map[string]types.ReminderPreferences
+	notifyPrefs   map[string]types.NotificationPreferences
+	deletions     map[string]types.AccountDeletion
+	deletedStats  []string
+}
+
+func reactionKey(entryAuthor, entryDate string) string {
+	return entryAuthor + ":" + entryDate
+}
+
+func (ds *mockDatastore) GetEntries(username string) ([]types.JournalEntry, error) {
+	return ds.entries[username], nil
+}
+
+func (ds *mockDatastore) DeleteEntries(username string) error {
+	delete(ds.entries, username)
+	return nil
+}
+
+func (ds *mockDatastore) GetDrafts(username string) ([]types.JournalEntry, error) {
+	return ds.drafts[username], nil
+}
+
+func (ds *mockDatastore) DeleteDrafts(username string) error {
+	delete(ds.drafts, username)
+	return nil
+}
+
+func (ds *mockDatastore) GetUserProfile(username string) (types.UserProfile, error) {
+	p, ok := ds.profiles[username]
+	if !ok {
+		return types.UserProfile{}, datastore.UserProfileNotFoundError{Username: username}
+	}
+	return p, nil
+}
+
+func (ds *mockDatastore) DeleteUserProfile(username string) error {
+	delete(ds.profiles, username)
+	return nil
+}
+
+func (ds *mockDatastore) GetReactions(entryAuthor string, entryDate string) ([]types.Reaction, error) {
+	return ds.reactions[reactionKey(entryAuthor, entryDate)], nil
+}
+
+func (ds *mockDatastore) GetReactionsByUser(username string) ([]types.EntryReaction, error) {
+	given := []types.EntryReaction{}
+	for key, reactions := range ds.reactions {
+		for _, r := range reactions {
+			if r.Username == username {
+				parts := strings.SplitN(key, ":", 2)
+				given = append(given, types.EntryReaction{EntryAuthor: parts[0], EntryDate: parts[1], Reaction: r})
+			}
+		}
+	}
+	return given, nil
+}
+
+func (ds *mockDatastore) DeleteReaction(entryAuthor string, entryDate string, username string) error {
+	key := reactionKey(entryAuthor, entryDate)
+	remaining := []types.Reaction{}
+	for _, r := range ds.reactions[key] {
+		if r.Username != username {
+			remaining = append(remaining, r)
+		}
+	}
+	ds.reactions[key] = remaining
+	return nil
+}
+
+func (ds *mockDatastore) GetComments(entryAuthor string, entryDate string) ([]types.Comment, error) {
+	return ds.comments[reactionKey(entryAuthor, entryDate)], nil
+}
+
+func (ds *mockDatastore) GetCommentsByUser(username string) ([]types.EntryComment, error) {
+	written := []types.EntryComment{}
+	for key, comments := range ds.comments {
+		for _, c := range comments {
+			if c.Username == username {
+				parts := strings.SplitN(key, ":", 2)
+				written = append(written, types.EntryComment{EntryAuthor: parts[0], EntryDate: parts[1], Comment: c})
+			}
+		}
+	}
+	return written, nil
+}
+
+func (ds *mockDatastore) SetComment(entryAuthor string, entryDate string, c types.Comment) error {
+	key := reactionKey(entryAuthor, entryDate)
+	for i := range ds.comments[key] {
+		if ds.comments[key][i].ID == c.ID {
+			ds.comments[key][i] = c
+			return nil
+		}
+	}
+	ds.comments[key] = append(ds.comments[key], c)
+	return nil
+}
+
+func (ds *mockDatastore) DeleteComment(entryAuthor string, entryDate string, commentID string) error {
+	key := reactionKey(entryAuthor, entryDate)
+	remaining := []types.Comment{}
+	for _, c := range ds.comments[key] {
+		if c.ID != commentID {
+			remaining = append(remaining, c)
+		}
+	}
+	ds.comments[key] = remaining
+	return nil
+}
+
+func (ds *mockDatastore) GetPageViews(path string) (int, error) {
+	count, ok := ds.pageViews[path]
+	if !ok {
+		return 0, datastore.PageViewsNotFoundError{Path: path}
+	}
+	return count, nil
+}
+
+func (ds *mockDatastore) DeletePageViews(path string) error {
+	delete(ds.pageViews, path)
+	return nil
+}
+
+func (ds *mockDatastore) DeleteUserStats(username string) error {
+	ds.deletedStats = append(ds.deletedStats, username)
+	return nil
+}
+
+func (ds *mockDatastore) GetNotificationsInvolving(username string) ([]types.Notification, error) {
+	notifications := []types.Notification{}
+	for _, n := range ds.notifications {
+		if n.Recipient == username || n.Actor == username || n.EntryAuthor == username {
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications, nil
+}
+
+func (ds *mockDatastore) DeleteNotification(id string) error {
+	delete(ds.notifications, id)
+	return nil
+}
+
+func (ds *mockDatastore) GetTeamsForUser(username string) ([]types.Team, error) {
+	teams := []types.Team{}
+	for _, team := range ds.teams {
+		if _, ok := team.Member(username); ok || isStringInSlice(username, team.Invitees) {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func (ds *mockDatastore) UpdateTeam(teamID string, update func(*types.Team) error) (types.Team, error) {
+	team, ok := ds.teams[teamID]
+	if !ok {
+		return types.Team{}, datastore.TeamNotFoundError{TeamID: teamID}
+	}
+	team.Members = append([]types.TeamMember{}, team.Members...)
+	team.Invitees = append([]string{}, team.Invitees...)
+	if err := update(&team); err != nil {
+		return types.Team{}, err
+	}
+	ds.teams[teamID] = team
+	return team, nil
+}
+
+func (ds *mockDatastore) DeleteTeam(teamID string) error {
+	delete(ds.teams, teamID)
+	return nil
+}
+
+func (ds *mockDatastore) GetFollowing(username string) ([]string, error) {
+	return ds.following[username], nil
+}
+
+func (ds *mockDatastore) GetFollowers(username string) ([]string, error) {
+	followers := []string{}
+	for follower, following := range ds.following {
+		if isStringInSlice(username, following) {
+			followers = append(followers, follower)
+		}
+	}
+	return followers, nil
+}
+
+func (ds *mockDatastore) Unfollow(follower, followee string) error {
+	remaining := []string{}
+	for _, f := range ds.following[follower] {
+		if f != followee {
+			remaining = append(remaining, f)
+		}
+	}
+	ds.following[follower] = remaining
+	return nil
+}
+
+func (ds *mockDatastore) GetDigestSubscriptions() ([]types.DigestSubscription, error) {
+	subs := []types.DigestSubscription{}
+	for _, sub := range ds.digests {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (ds *mockDatastore) SetDigestSubscription(username string, sub types.DigestSubscription) error {
+	sub.Username = username
+	ds.digests[username] = sub
+	return nil
+}
+
+func (ds *mockDatastore) DeleteDigestSubscription(username string) error {
+	delete(ds.digests, username)
+	return nil
+}
+
+func (ds *mockDatastore) DeleteSlackIntegration(owner string) error {
+	delete(ds.slack, owner)
+	return nil
+}
+
+func (ds *mockDatastore) GetWebhooks(owner string) ([]types.Webhook, error) {
+	hooks := []types.Webhook{}
+	for _, hook := range ds.webhooks {
+		if hook.Owner == owner {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (ds *mockDatastore) DeleteWebhook(owner string, webhookID string) error {
+	hooks := []types.Webhook{}
+	for _, hook := range ds.webhooks {
+		if hook.Owner != owner || hook.ID != webhookID {
+			hooks = append(hooks, hook)
+		}
+	}
+	ds.webhooks = hooks
+	deliveries := []types.WebhookDelivery{}
+	for _, d := range ds.deliveries {
+		if d.WebhookID != webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	ds.deliveries = deliveries
+	return nil
+}
+
+func (ds *mockDatastore) DeleteReminderPreferences(username string) error {
+	delete(ds.reminders, username)
+	return nil
+}
+
+func (ds *mockDatastore) DeleteNotificationPreferences(username string) error {
+	delete(ds.notifyPrefs, username)
+	return nil
+}
+
+func (ds *mockDatastore) GetConfirmedAccountDeletions() ([]types.AccountDeletion, error) {
+	deletions := []types.AccountDeletion{}
+	for _, d := range ds.deletions {
+		if d.Status == types.AccountDeletionConfirmed {
+			deletions = append(deletions, d)
+		}
+	}
+	return deletions, nil
+}
+
+func (ds *mockDatastore) SetAccountDeletion(d types.AccountDeletion) error {
+	ds.deletions[d.ID] = d
+	return nil
+}
+
+func isStringInSlice(s string, ss []string) bool {
+	for _, x := range ss {
+		if s == x {
+			return true
+		}
+	}
+	return false
+}
+
+func newMockDatastore() *mockDatastore {
+	return &mockDatastore{
+		entries: map[string][]types.JournalEntry{
+			"alice": {{Date: "2019-11-22"}, {Date: "2019-11-29"}},
+			"bob":   {{Date: "2019-11-29"}},
+		},
+		drafts: map[string][]types.JournalEntry{
+			"alice": {{Date: "2019-12-06"}},
+			"bob":   {{Date: "2019-12-06"}},
+		},
+		profiles: map[string]types.UserProfile{
+			"alice": {AboutMarkdown: "I'm Alice"},
+			"bob":   {AboutMarkdown: "I'm Bob"},
+		},
+		reactions: map[string][]types.Reaction{
+			"alice:2019-11-29": {{Username: "bob", Symbol: "👍"}},
+			"bob:2019-11-29":   {{Username: "alice", Symbol: "🎉"}, {Username: "carol", Symbol: "👍"}},
+		},
+		comments: map[string][]types.Comment{
+			"alice:2019-11-29": {{ID: "c1", Username: "bob", Markdown: "Nice work", Timestamp: "2019-11-30T10:00:00Z"}},
+			"bob:2019-11-29": {
+				{ID: "c2", Username: "alice", Markdown: "Congrats!", Timestamp: "2019-11-30T11:00:00Z"},
+				{ID: "c3", Username: "carol", ParentID: "c2", Markdown: "Agreed", Timestamp: "2019-11-30T12:00:00Z"},
+				{ID: "c4", Username: "alice", Markdown: "Also, thanks", Timestamp: "2019-11-30T13:00:00Z"},
+			},
+		},
+		pageViews: map[string]int{
+			"/alice/2019-11-29": 12,
+			"/bob/2019-11-29":   4,
+		},
+		notifications: map[string]types.Notification{
+			"n1": {ID: "n1", Recipient: "alice", Actor: "bob", EntryAuthor: "alice", EntryDate: "2019-11-29"},
+			"n2": {ID: "n2", Recipient: "bob", Actor: "alice", EntryAuthor: "bob", EntryDate: "2019-11-29"},
+			"n3": {ID: "n3", Recipient: "bob", Actor: "carol", EntryAuthor: "alice", EntryDate: "2019-11-29"},
+			"n4": {ID: "n4", Recipient: "bob", Actor: "carol", EntryAuthor: "bob", EntryDate: "2019-11-29"},
+		},
+		teams: map[string]types.Team{
+			"lighthouse": {ID: "lighthouse", Members: []types.TeamMember{
+				{Username: "alice", Role: types.TeamRoleAdmin},
+				{Username: "bob", Role: types.TeamRoleMember},
+				{Username: "carol", Role: types.TeamRoleMember},
+			}},
+			"solo":       {ID: "solo", Members: []types.TeamMember{{Username: "alice", Role: types.TeamRoleAdmin}}, Invitees: []string{"dave"}},
+			"skunkworks": {ID: "skunkworks", Members: []types.TeamMember{{Username: "bob", Role: types.TeamRoleAdmin}}, Invitees: []string{"alice", "carol"}},
+		},
+		following: map[string][]string{
+			"alice": {"bob", "carol"},
+			"bob":   {"alice"},
+			"carol": {"alice", "bob"},
+		},
+		digests: map[string]types.DigestSubscription{
+			"alice": {Username: "alice", Frequency: types.DigestWeekly, Authors: []string{}},
+			"bob":   {Username: "bob", Frequency: types.DigestWeekly, Authors: []string{"alice"}},
+			"carol": {Username: "carol", Frequency: types.DigestDaily, Authors: []string{"alice", "bob"}},
+		},
+		webhooks: []types.Webhook{
+			{ID: "h1", Owner: "alice"},
+			{ID: "h2", Owner: "bob"},
+		},
+		deliveries: []types.WebhookDelivery{
+			{ID: "d1", WebhookID: "h1"},
+			{ID: "d2", WebhookID: "h1"},
+			{ID: "d3", WebhookID: "h2"},
+		},
+		slack: map[string]bool{
+			types.UserSlackOwner("alice"): true,
+			types.UserSlackOwner("bob"):   true,
+			types.TeamSlackOwner("solo"):  true,
+		},
+		reminders: map[string]types.ReminderPreferences{
+			"alice": {Username: "alice", Enabled: true},
+			"bob":   {Username: "bob", Enabled: true},
+		},
+		notifyPrefs: map[string]types.NotificationPreferences{
+			"alice": {Username: "alice", EmailEnabled: true},
+			"bob":   {Username: "bob", EmailEnabled: true},
+		},
+		deletions: map[string]types.AccountDeletion{
+			"abc123": {ID: "abc123", Username: "alice", Status: types.AccountDeletionConfirmed},
+		},
+	}
+}
+
+func TestPurgeConfirmed(t *testing.T) {
+	ds := newMockDatastore()
+	now := time.Date(2019, 12, 2, 9, 0, 0, 0, time.UTC)
+
+	if err := New(ds).PurgeConfirmed(now); err != nil {
+		t.Fatalf("PurgeConfirmed failed: %v", err)
+	}
+
+	d := ds.deletions["abc123"]
+	if d.Status != types.AccountDeletionCompleted || d.CompletedAt != "2019-12-02T09:00:00Z" {
+		t.Errorf("expected deletion to be completed, got %+v", d)
+	}
+	deletedExpected := map[string]int{
+		"entries":           2,
+		"drafts":            1,
+		"profile":           1,
+		"reactionsReceived": 1,
+		"reactionsGiven":    1,
+		"commentsReceived":  1,
+		"commentsWritten":   2,
+		"pageViews":         1,
+		"notifications":     3,
+		"teamMemberships":   3,
+		"follows":           2,
+		"followers":         2,
+		"digestAuthors":     2,
+		"webhooks":          1,
+	}
+	if !reflect.DeepEqual(d.Deleted, deletedExpected) {
+		t.Errorf("deleted=%v, want %v", d.Deleted, deletedExpected)
+	}
+
+	// Purging again finds nothing left to delete.
+	deleted, err := New(ds).Purge("alice")
+	if err != nil {
+		t.Fatalf("second purge failed: %v", err)
+	}
+	if !reflect.DeepEqual(deleted, map[string]int{"entries": 0, "drafts": 0}) {
+		t.Errorf("expected second purge to delete nothing, got %v", deleted)
+	}
+}
+
+func TestPurgeRemovesUserFromEveryCollection(t *testing.T) {
+	ds := newMockDatastore()
+
+	if _, err := New(ds).Purge("alice"); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+
+	if _, ok := ds.entries["alice"]; ok {
+		t.Errorf("expected alice's entries to be deleted")
+	}
+	if _, ok := ds.drafts["alice"]; ok {
+		t.Errorf("expected alice's drafts to be deleted")
+	}
+	if _, ok := ds.profiles["alice"]; ok {
+		t.Errorf("expected alice's profile to be deleted")
+	}
+	if len(ds.reactions["alice:2019-11-29"]) != 0 {
+		t.Errorf("expected reactions to alice's entries to be deleted, got %+v", ds.reactions["alice:2019-11-29"])
+	}
+	if !reflect.DeepEqual(ds.reactions["bob:2019-11-29"], []types.Reaction{{Username: "carol", Symbol: "👍"}}) {
+		t.Errorf("expected only alice's reaction to bob's entry to be deleted, got %+v", ds.reactions["bob:2019-11-29"])
+	}
+	if len(ds.comments["alice:2019-11-29"]) != 0 {
+		t.Errorf("expected comments on alice's entries to be deleted, got %+v", ds.comments["alice:2019-11-29"])
+	}
+	// Carol's reply keeps its parent as a placeholder, but alice's other
+	// comment disappears entirely.
+	commentsExpected := []types.Comment{
+		{ID: "c2", Timestamp: "2019-11-30T11:00:00Z", Deleted: true},
+		{ID: "c3", Username: "carol", ParentID: "c2", Markdown: "Agreed", Timestamp: "2019-11-30T12:00:00Z"},
+	}
+	if !reflect.DeepEqual(ds.comments["bob:2019-11-29"], commentsExpected) {
+		t.Errorf("comments=%+v, want %+v", ds.comments["bob:2019-11-29"], commentsExpected)
+	}
+	if !reflect.DeepEqual(ds.pageViews, map[string]int{"/bob/2019-11-29": 4}) {
+		t.Errorf("expected alice's page views to be deleted, got %v", ds.pageViews)
+	}
+	if len(ds.notifications) != 1 || ds.notifications["n4"].ID != "n4" {
+		t.Errorf("expected only notifications unrelated to alice to remain, got %+v", ds.notifications)
+	}
+	if !reflect.DeepEqual(ds.deletedStats, []string{"alice"}) {
+		t.Errorf("expected alice's stats to be deleted")
+	}
+
+	teamsExpected := map[string]types.Team{
+		"lighthouse": {ID: "lighthouse", Members: []types.TeamMember{
+			{Username: "bob", Role: types.TeamRoleAdmin},
+			{Username: "carol", Role: types.TeamRoleMember},
+		}, Invitees: []string{}},
+		"skunkworks": {ID: "skunkworks", Members: []types.TeamMember{{Username: "bob", Role: types.TeamRoleAdmin}}, Invitees: []string{"carol"}},
+	}
+	if !reflect.DeepEqual(ds.teams, teamsExpected) {
+		t.Errorf("teams=%+v, want %+v", ds.teams, teamsExpected)
+	}
+
+	followingExpected := map[string][]string{
+		"alice": {},
+		"bob":   {},
+		"carol": {"bob"},
+	}
+	if !reflect.DeepEqual(ds.following, followingExpected) {
+		t.Errorf("following=%v, want %v", ds.following, followingExpected)
+	}
+	digestsExpected := map[string]types.DigestSubscription{
+		"carol": {Username: "carol", Frequency: types.DigestDaily, Authors: []string{"bob"}},
+	}
+	if !reflect.DeepEqual(ds.digests, digestsExpected) {
+		t.Errorf("digests=%+v, want %+v", ds.digests, digestsExpected)
+	}
+	if !reflect.DeepEqual(ds.webhooks, []types.Webhook{{ID: "h2", Owner: "bob"}}) {
+		t.Errorf("expected alice's webhooks to be deleted, got %+v", ds.webhooks)
+	}
+	if !reflect.DeepEqual(ds.deliveries, []types.WebhookDelivery{{ID: "d3", WebhookID: "h2"}}) {
+		t.Errorf("expected alice's webhook deliveries to be deleted, got %+v", ds.deliveries)
+	}
+	if !reflect.DeepEqual(ds.slack, map[string]bool{types.UserSlackOwner("bob"): true}) {
+		t.Errorf("expected alice's Slack integrations to be deleted, got %v", ds.slack)
+	}
+	if _, ok := ds.reminders["alice"]; ok || len(ds.reminders) != 1 {
+		t.Errorf("expected only alice's reminder preferences to be deleted, got %+v", ds.reminders)
+	}
+	if _, ok := ds.notifyPrefs["alice"]; ok || len(ds.notifyPrefs) != 1 {
+		t.Errorf("expected only alice's notification preferences to be deleted, got %+v", ds.notifyPrefs)
+	}
+	if len(ds.entries["bob"]) != 1 || len(ds.drafts["bob"]) != 1 {
+		t.Errorf("expected bob's data to remain")
+	}
+}
@@ -0,0 +1,171 @@
+// Package accesslog computes page view metrics from the access log that the
+// What Got Done server writes in Apache Combined Log Format.
+package accesslog
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+)
+
+// maxLineLength is the longest log line the parser accepts. Lines are usually
+// a few hundred bytes, but user agents and URLs have no fixed limit.
+const maxLineLength = 1024 * 1024
+
+// logLinePattern matches a line in Apache Common or Combined Log Format:
+//
+//	host ident user [time] "request" status size "referer" "user agent"
+//
+// The referer and user agent are only present in Combined Log Format.
+var logLinePattern = regexp.MustCompile(`^\S+ \S+ \S+ \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) \S+(?: "(?:[^"\\]|\\.)*" "((?:[^"\\]|\\.)*)")?`)
+
+// source implements analytics.Source by parsing an access log file.
+type source struct {
+	path string
+	now  func() time.Time
+}
+
+// New creates an analytics.Source that counts page views in the access log at
+// the given path.
+func New(path string) analytics.Source {
+	return source{
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// DailyPageViewsByPath retrieves the pageviews for each URL path on each day
+// over a given date range.
+func (s source) DailyPageViewsByPath(startDate, endDate string) ([]analytics.DailyPageViewCount, error) {
+	start, err := analytics.ParseDate(startDate, s.now())
+	if err != nil {
+		return []analytics.DailyPageViewCount{}, err
+	}
+	end, err := analytics.ParseDate(endDate, s.now())
+	if err != nil {
+		return []analytics.DailyPageViewCount{}, err
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return []analytics.DailyPageViewCount{}, err
+	}
+	defer f.Close()
+
+	return countDailyPageViews(f, start, end)
+}
+
+type request struct {
+	path      string
+	date      string
+	userAgent string
+}
+
+// countDailyPageViews counts the successful page loads from human readers in
+// each day of the log between start and end, inclusive. It skips lines that
+// don't record page loads.
+func countDailyPageViews(r io.Reader, start time.Time, end time.Time) ([]analytics.DailyPageViewCount, error) {
+	type pathDay struct {
+		path string
+		date string
+	}
+	first := start.Format("2006-01-02")
+	last := end.Format("2006-01-02")
+	counts := map[pathDay]int{}
+	skipped := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	for scanner.Scan() {
+		req, ok := parseLine(scanner.Text())
+		if !ok {
+			skipped++
+			continue
+		}
+		if req.date < first || req.date > last || analytics.IsCrawler(req.userAgent) {
+			continue
+		}
+		counts[pathDay{req.path, req.date}]++
+	}
+	if err := scanner.Err(); err != nil {
+		return []analytics.DailyPageViewCount{}, err
+	}
+	if skipped > 0 {
+		log.Printf("Skipped %d access log lines that were not page loads", skipped)
+	}
+
+	viewCounts := []analytics.DailyPageViewCount{}
+	for k, views := range counts {
+		viewCounts = append(viewCounts, analytics.DailyPageViewCount{
+			Path:  k.path,
+			Date:  k.date,
+			Views: views,
+		})
+	}
+	sort.Slice(viewCounts, func(i, j int) bool {
+		if viewCounts[i].Path != viewCounts[j].Path {
+			return viewCounts[i].Path < viewCounts[j].Path
+		}
+		return viewCounts[i].Date < viewCounts[j].Date
+	})
+	return viewCounts, nil
+}
+
+// parseLine extracts a page load from a single access log line. It returns
+// false if the line is malformed or doesn't record a successful GET request.
+func parseLine(line string) (request, bool) {
+	m := logLinePattern.FindStringSubmatch(line)
+	if m == nil {
+		return request{}, false
+	}
+
+	t, err := time.Parse("02/Jan/2006:15:04:05 -0700", m[1])
+	if err != nil {
+		return request{}, false
+	}
+
+	requestLine := strings.Split(m[2], " ")
+	if len(requestLine) != 3 || requestLine[0] != http.MethodGet {
+		return request{}, false
+	}
+	u, err := url.ParseRequestURI(requestLine[1])
+	if err != nil {
+		return request{}, false
+	}
+
+	// Browsers that revalidate a cached page receive a 304, but it still
+	// counts as a view.
+	status, err := strconv.Atoi(m[3])
+	if err != nil || (status != http.StatusOK && status != http.StatusNotModified) {
+		return request{}, false
+	}
+
+	return request{
+		path:      u.EscapedPath(),
+		date:      t.UTC().Format("2006-01-02"),
+		userAgent: unescape(m[4]),
+	}, true
+}
+
+// unescape reverses the backslash escaping that the logger applies to quoted
+// fields.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	unquoted, err := strconv.Unquote(`"` + s + `"`)
+	if err != nil {
+		return s
+	}
+	return unquoted
+}
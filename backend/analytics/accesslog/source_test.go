@@ -0,0 +1,90 @@
+package accesslog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+)
+
+const firefox = "Mozilla/5.0 (X11; Linux x86_64; rv:70.0) Gecko/20100101 Firefox/70.0"
+
+var testLog = strings.Join([]string{
+	`203.0.113.1 - - [29/Nov/2019:10:00:00 +0000] "GET /alice/2019-11-29 HTTP/1.1" 200 1024 "-" "` + firefox + `"`,
+	`203.0.113.2 - - [29/Nov/2019:11:00:00 +0000] "GET /alice/2019-11-29?utm_source=twitter HTTP/1.1" 200 1024 "https://twitter.com/" "` + firefox + `"`,
+	`203.0.113.3 - - [29/Nov/2019:12:00:00 +0000] "GET /alice/2019-11-29 HTTP/1.1" 304 0 "-" "` + firefox + `"`,
+	// Local time that falls on the next day in UTC.
+	`203.0.113.4 - - [29/Nov/2019:20:00:00 -0500] "GET /alice/2019-11-29 HTTP/1.1" 200 1024 "-" "` + firefox + `"`,
+	`203.0.113.5 - - [29/Nov/2019:13:00:00 +0000] "GET /bob/2019-11-22 HTTP/1.1" 200 1024 "-" "Mozilla/5.0 \"quoted\" Safari"`,
+	// Requests that aren't page loads by human readers.
+	`203.0.113.6 - - [29/Nov/2019:13:00:00 +0000] "GET /alice/2019-11-29 HTTP/1.1" 200 1024 "-" "Googlebot/2.1"`,
+	`203.0.113.7 - - [29/Nov/2019:13:00:00 +0000] "POST /api/draft/2019-11-29 HTTP/1.1" 200 2 "-" "` + firefox + `"`,
+	`203.0.113.8 - - [29/Nov/2019:13:00:00 +0000] "GET /alice/2019-11-15 HTTP/1.1" 404 10 "-" "` + firefox + `"`,
+	`203.0.113.9 - - [29/Nov/2019:13:00:00 +0000] "GET /alice/2019-11-29 HTTP/1.1" 200 1024`,
+	`203.0.113.10 - - [27/Nov/2019:13:00:00 +0000] "GET /alice/2019-11-22 HTTP/1.1" 200 1024 "-" "` + firefox + `"`,
+	`not a log line`,
+	``,
+}, "\n")
+
+func TestCountDailyPageViews(t *testing.T) {
+	start := time.Date(2019, 11, 28, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2019, 11, 30, 0, 0, 0, 0, time.UTC)
+	counts, err := countDailyPageViews(strings.NewReader(testLog), start, end)
+	if err != nil {
+		t.Fatalf("countDailyPageViews failed: %v", err)
+	}
+	expected := []analytics.DailyPageViewCount{
+		{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 3},
+		{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 1},
+		{Path: "/bob/2019-11-22", Date: "2019-11-29", Views: 1},
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("got %+v, want %+v", counts, expected)
+	}
+}
+
+func TestSourceReadsLogFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "accesslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "access.log")
+	if err := ioutil.WriteFile(logPath, []byte(testLog), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := source{
+		path: logPath,
+		now: func() time.Time {
+			return time.Date(2019, 11, 30, 9, 0, 0, 0, time.UTC)
+		},
+	}
+	counts, err := s.DailyPageViewsByPath("2019-01-01", "today")
+	if err != nil {
+		t.Fatalf("DailyPageViewsByPath failed: %v", err)
+	}
+	expected := []analytics.DailyPageViewCount{
+		{Path: "/alice/2019-11-22", Date: "2019-11-27", Views: 1},
+		{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 3},
+		{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 1},
+		{Path: "/bob/2019-11-22", Date: "2019-11-29", Views: 1},
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("got %+v, want %+v", counts, expected)
+	}
+
+	if _, err := s.DailyPageViewsByPath("2019-01-01", "someday"); err == nil {
+		t.Errorf("expected invalid end date to fail")
+	}
+
+	missing := source{path: filepath.Join(dir, "missing.log"), now: time.Now}
+	if _, err := missing.DailyPageViewsByPath("2019-01-01", "today"); err == nil {
+		t.Errorf("expected missing log file to fail")
+	}
+}
@@ -0,0 +1,75 @@
+// Package analytics defines the interface for sources of page view data, such
+// as Google Analytics or the server's own access logs.
+package analytics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Source retrieves page view metrics for What Got Done routes. Dates are in
+	// YYYY-MM-DD format, or one of the relative dates "today", "yesterday", or
+	// "NdaysAgo", where N is a non-negative integer.
+	Source interface {
+		// DailyPageViewsByPath retrieves the pageviews for each URL path on each
+		// day over a given date range.
+		DailyPageViewsByPath(startDate, endDate string) ([]DailyPageViewCount, error)
+	}
+
+	// PageViewCount represents the number of pageviews for a given URL path.
+	PageViewCount struct {
+		Path  string
+		Views int
+	}
+
+	// DailyPageViewCount represents the number of pageviews for a given URL path
+	// on a single day.
+	DailyPageViewCount struct {
+		Path string
+		// Date is the day of the pageviews in YYYY-MM-DD format.
+		Date  string
+		Views int
+	}
+)
+
+// crawlerUserAgentMarkers are substrings that identify automated clients,
+// whose requests shouldn't count as page views.
+var crawlerUserAgentMarkers = []string{"bot", "crawler", "spider", "slurp", "preview"}
+
+// IsCrawler returns true if the user agent belongs to an automated client
+// rather than a human reader.
+func IsCrawler(userAgent string) bool {
+	if userAgent == "" || userAgent == "-" {
+		return true
+	}
+	ua := strings.ToLower(userAgent)
+	for _, marker := range crawlerUserAgentMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDate converts a date in one of the formats that Source accepts into the
+// start of that day in UTC.
+func ParseDate(date string, now time.Time) (time.Time, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case date == "today":
+		return today, nil
+	case date == "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case strings.HasSuffix(date, "daysAgo"):
+		n, err := strconv.Atoi(strings.TrimSuffix(date, "daysAgo"))
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid relative date: %s", date)
+		}
+		return today.AddDate(0, 0, -n), nil
+	}
+	return time.Parse("2006-01-02", date)
+}
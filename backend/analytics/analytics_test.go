@@ -0,0 +1,55 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2019, 12, 3, 22, 15, 0, 0, time.UTC)
+	var tests = []struct {
+		date          string
+		validExpected bool
+		dateExpected  string
+	}{
+		{"2019-11-29", true, "2019-11-29"},
+		{"today", true, "2019-12-03"},
+		{"yesterday", true, "2019-12-02"},
+		{"0daysAgo", true, "2019-12-03"},
+		{"7daysAgo", true, "2019-11-26"},
+		{"-1daysAgo", false, ""},
+		{"daysAgo", false, ""},
+		{"tomorrow", false, ""},
+		{"2019/11/29", false, ""},
+		{"", false, ""},
+	}
+	for _, tt := range tests {
+		d, err := ParseDate(tt.date, now)
+		if (err == nil) != tt.validExpected {
+			t.Errorf("ParseDate(%q) err=%v, want valid=%v", tt.date, err, tt.validExpected)
+			continue
+		}
+		if err == nil && d.Format("2006-01-02") != tt.dateExpected {
+			t.Errorf("ParseDate(%q)=%s, want %s", tt.date, d.Format("2006-01-02"), tt.dateExpected)
+		}
+	}
+}
+
+func TestIsCrawler(t *testing.T) {
+	var tests = []struct {
+		userAgent string
+		expected  bool
+	}{
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:70.0) Gecko/20100101 Firefox/70.0", false},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"Mozilla/5.0 (compatible; Yahoo! Slurp)", true},
+		{"Slackbot-LinkExpanding 1.0", true},
+		{"", true},
+		{"-", true},
+	}
+	for _, tt := range tests {
+		if got := IsCrawler(tt.userAgent); got != tt.expected {
+			t.Errorf("IsCrawler(%q)=%v, want %v", tt.userAgent, got, tt.expected)
+		}
+	}
+}
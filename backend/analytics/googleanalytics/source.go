@@ -0,0 +1,134 @@
+// Package googleanalytics retrieves page view metrics from the Google
+// Analytics Reporting API.
+package googleanalytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/option"
+
+	ga "google.golang.org/api/analyticsreporting/v4"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+)
+
+// reportPageSize is the number of rows to request in each page of a report.
+// Reports with more rows than this span multiple requests.
+const reportPageSize = 10000
+
+// source implements analytics.Source using a real Google Analytics backend.
+type source struct {
+	svc    *ga.Service
+	viewID string
+}
+
+// New creates an analytics.Source that retrieves page views from Google
+// Analytics.
+func New() (analytics.Source, error) {
+	viewID := os.Getenv("GOOGLE_ANALYTICS_VIEW_ID")
+	if viewID == "" {
+		log.Printf("GOOGLE_ANALYTICS_VIEW_ID is not set, skipping Google Analytics updates")
+		return nil, errors.New("Can't create Google Analytics source without Google Analytics View ID")
+	}
+
+	const keyFilePath = "google-analytics-service-account.json"
+	svc, err := ga.NewService(context.Background(), option.WithCredentialsFile(keyFilePath))
+	if err != nil {
+		return nil, err
+	}
+	return source{svc, viewID}, nil
+}
+
+// DailyPageViewsByPath retrieves the pageviews for each URL path on each day
+// over a given date range.
+func (r source) DailyPageViewsByPath(startDate, endDate string) ([]analytics.DailyPageViewCount, error) {
+	rows, err := getReportRows(r.svc, r.viewID, startDate, endDate, []*ga.Dimension{
+		{Name: "ga:pagePath"},
+		{Name: "ga:date"},
+	})
+	if err != nil {
+		return []analytics.DailyPageViewCount{}, err
+	}
+
+	return extractDailyPageViews(rows)
+}
+
+// getReportRows retrieves every row of a pageviews report, requesting
+// additional pages until the report is complete.
+func getReportRows(svc *ga.Service, viewID string, startDate string, endDate string, dimensions []*ga.Dimension) ([]*ga.ReportRow, error) {
+	rows := []*ga.ReportRow{}
+	pageToken := ""
+	for {
+		report, err := getReportPage(svc, viewID, startDate, endDate, dimensions, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		if report.Data != nil {
+			rows = append(rows, report.Data.Rows...)
+		}
+		if report.NextPageToken == "" {
+			return rows, nil
+		}
+		pageToken = report.NextPageToken
+	}
+}
+
+func getReportPage(svc *ga.Service, viewID string, startDate string, endDate string, dimensions []*ga.Dimension, pageToken string) (*ga.Report, error) {
+	req := &ga.GetReportsRequest{
+		ReportRequests: []*ga.ReportRequest{
+			{
+				ViewId: viewID,
+				DateRanges: []*ga.DateRange{
+					{StartDate: startDate, EndDate: endDate},
+				},
+				Metrics: []*ga.Metric{
+					{Expression: "ga:pageviews"},
+				},
+				Dimensions: dimensions,
+				PageSize:   reportPageSize,
+				PageToken:  pageToken,
+			},
+		},
+	}
+
+	res, err := svc.Reports.BatchGet(req).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.HTTPStatusCode != 200 {
+		return nil, fmt.Errorf("request to Google Analytics failed with %d", res.HTTPStatusCode)
+	}
+
+	if len(res.Reports) != 1 {
+		return nil, fmt.Errorf("unexpected report count. wanted %d, got %d", 1, len(res.Reports))
+	}
+	return res.Reports[0], nil
+}
+
+func extractDailyPageViews(rows []*ga.ReportRow) ([]analytics.DailyPageViewCount, error) {
+	viewCounts := []analytics.DailyPageViewCount{}
+	for _, row := range rows {
+		// Google Analytics formats dates as YYYYMMDD.
+		d, err := time.Parse("20060102", row.Dimensions[1])
+		if err != nil {
+			return []analytics.DailyPageViewCount{}, err
+		}
+		pageViews, err := strconv.Atoi(row.Metrics[0].Values[0])
+		if err != nil {
+			return []analytics.DailyPageViewCount{}, err
+		}
+		viewCounts = append(viewCounts, analytics.DailyPageViewCount{
+			Path:  row.Dimensions[0],
+			Date:  d.Format("2006-01-02"),
+			Views: pageViews,
+		})
+	}
+	return viewCounts, nil
+}
@@ -0,0 +1,76 @@
+package googleanalytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/option"
+
+	ga "google.golang.org/api/analyticsreporting/v4"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+)
+
+func dailyRow(path string, date string, views string) *ga.ReportRow {
+	return &ga.ReportRow{
+		Dimensions: []string{path, date},
+		Metrics:    []*ga.DateRangeValues{{Values: []string{views}}},
+	}
+}
+
+func TestDailyPageViewsByPathFollowsPageTokens(t *testing.T) {
+	pages := map[string]*ga.Report{
+		"": {
+			Data:          &ga.ReportData{Rows: []*ga.ReportRow{dailyRow("/alice/2019-11-29", "20191129", "4")}},
+			NextPageToken: "page2",
+		},
+		"page2": {
+			Data:          &ga.ReportData{Rows: []*ga.ReportRow{dailyRow("/alice/2019-11-29", "20191130", "2")}},
+			NextPageToken: "page3",
+		},
+		"page3": {
+			Data: &ga.ReportData{Rows: []*ga.ReportRow{dailyRow("/bob/2019-11-22", "20191130", "7")}},
+		},
+	}
+	requestedTokens := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ga.GetReportsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		token := req.ReportRequests[0].PageToken
+		requestedTokens = append(requestedTokens, token)
+		if err := json.NewEncoder(w).Encode(ga.GetReportsResponse{
+			Reports: []*ga.Report{pages[token]},
+		}); err != nil {
+			panic(err)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := ga.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	s := source{svc: svc, viewID: "12345"}
+
+	counts, err := s.DailyPageViewsByPath("2019-11-29", "today")
+	if err != nil {
+		t.Fatalf("DailyPageViewsByPath failed: %v", err)
+	}
+	expected := []analytics.DailyPageViewCount{
+		{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 4},
+		{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 2},
+		{Path: "/bob/2019-11-22", Date: "2019-11-30", Views: 7},
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("got %+v, want %+v", counts, expected)
+	}
+	if !reflect.DeepEqual(requestedTokens, []string{"", "page2", "page3"}) {
+		t.Errorf("unexpected page tokens: %v", requestedTokens)
+	}
+}
@@ -0,0 +1,457 @@
+// Package backup exports What Got Done data to a portable archive and restores
+// it. It reads and writes only through the datastore.Datastore interface, so
+// an archive from one backend can be restored to any other.
+//
+// An archive is a gzipped tarball. Its first file is manifest.json, which
+// records the archive's format version. Each remaining file holds one kind of
+// record, one JSON object per line.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// FormatVersion is the version of the archive layout that Export writes.
+// Restore rejects archives with a newer version.
+const FormatVersion = 1
+
+const manifestFile = "manifest.json"
+
+// Names of the archive's record files, in the order that Export writes them.
+const (
+	profilesFile       = "profiles.jsonl"
+	entriesFile        = "entries.jsonl"
+	draftsFile         = "drafts.jsonl"
+	reactionsFile      = "reactions.jsonl"
+	commentsFile       = "comments.jsonl"
+	pageViewsFile      = "page-views.jsonl"
+	dailyPageViewsFile = "daily-page-views.jsonl"
+	pageViewSyncFile   = "page-view-sync.jsonl"
+	teamsFile          = "teams.jsonl"
+	followsFile        = "follows.jsonl"
+	remindersFile      = "reminders.jsonl"
+	digestsFile        = "digests.jsonl"
+	notifyPrefsFile    = "notification-preferences.jsonl"
+	notificationsFile  = "notifications.jsonl"
+	webhooksFile       = "webhooks.jsonl"
+	deliveriesFile     = "webhook-deliveries.jsonl"
+	slackFile          = "slack-integrations.jsonl"
+)
+
+// The date range that covers every daily page view count.
+const (
+	earliestPageViewDay = "0001-01-01"
+	latestPageViewDay   = "9999-12-31"
+)
+
+// deliveriesPageSize is the number of webhook delivery records that Export
+// reads from the datastore at a time.
+const deliveriesPageSize = 100
+
+var recordFiles = []string{
+	profilesFile,
+	entriesFile,
+	draftsFile,
+	reactionsFile,
+	commentsFile,
+	pageViewsFile,
+	dailyPageViewsFile,
+	pageViewSyncFile,
+	teamsFile,
+	followsFile,
+	remindersFile,
+	digestsFile,
+	notifyPrefsFile,
+	notificationsFile,
+	webhooksFile,
+	deliveriesFile,
+	slackFile,
+}
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	FormatVersion int    `json:"formatVersion"`
+	CreatedAt     string `json:"createdAt"`
+	// Records maps each record file in the archive to its number of records.
+	Records map[string]int `json:"records"`
+}
+
+// Records pair datastore types with the keys they're stored under. They carry
+// fields that the types hide from JSON explicitly, so that a restore
+// reproduces the original data.
+type (
+	profileRecord struct {
+		Username string            `json:"username"`
+		Profile  types.UserProfile `json:"profile"`
+	}
+
+	entryRecord struct {
+		Username string             `json:"username"`
+		Entry    types.JournalEntry `json:"entry"`
+	}
+
+	reactionRecord struct {
+		EntryAuthor string         `json:"entryAuthor"`
+		EntryDate   string         `json:"entryDate"`
+		Reaction    types.Reaction `json:"reaction"`
+	}
+
+	commentRecord struct {
+		EntryAuthor string        `json:"entryAuthor"`
+		EntryDate   string        `json:"entryDate"`
+		Comment     types.Comment `json:"comment"`
+	}
+
+	pageViewsRecord struct {
+		Path  string `json:"path"`
+		Views int    `json:"views"`
+	}
+
+	followRecord struct {
+		Follower string `json:"follower"`
+		Followee string `json:"followee"`
+	}
+
+	reminderRecord struct {
+		Username    string                    `json:"username"`
+		Preferences types.ReminderPreferences `json:"preferences"`
+		LastSentFor string                    `json:"lastSentFor,omitempty"`
+	}
+
+	digestRecord struct {
+		Username     string                   `json:"username"`
+		Subscription types.DigestSubscription `json:"subscription"`
+		LastSentAt   string                   `json:"lastSentAt,omitempty"`
+	}
+
+	notifyPrefsRecord struct {
+		Username    string                        `json:"username"`
+		Preferences types.NotificationPreferences `json:"preferences"`
+	}
+
+	notificationRecord struct {
+		Recipient    string             `json:"recipient"`
+		Notification types.Notification `json:"notification"`
+		Emailed      bool               `json:"emailed"`
+	}
+
+	webhookRecord struct {
+		Owner   string        `json:"owner"`
+		Webhook types.Webhook `json:"webhook"`
+		Secret  string        `json:"secret"`
+	}
+
+	slackRecord struct {
+		Owner       string                 `json:"owner"`
+		Integration types.SlackIntegration `json:"integration"`
+		WebhookURL  string                 `json:"webhookUrl"`
+	}
+)
+
+// exporter collects records in memory, grouped by record file.
+type exporter struct {
+	datastore datastore.Datastore
+	files     map[string]*bytes.Buffer
+	counts    map[string]int
+}
+
+// Export writes an archive of all the data in the datastore to w.
+//
+// Export includes every user who has data in any collection, whether or not
+// they've published. It omits data that the server derives or rebuilds on its
+// own: search indexes, cached user stats, trending rankings, and the state of
+// scheduled jobs.
+func Export(ds datastore.Datastore, w io.Writer, now time.Time) (Manifest, error) {
+	e := exporter{
+		datastore: ds,
+		files:     map[string]*bytes.Buffer{},
+		counts:    map[string]int{},
+	}
+	for _, f := range recordFiles {
+		e.files[f] = &bytes.Buffer{}
+	}
+	if err := e.exportAll(); err != nil {
+		return Manifest{}, err
+	}
+
+	m := Manifest{
+		FormatVersion: FormatVersion,
+		CreatedAt:     now.UTC().Format(time.RFC3339),
+		Records:       e.counts,
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeFile(tw, manifestFile, manifest, now); err != nil {
+		return Manifest{}, err
+	}
+	for _, f := range recordFiles {
+		if err := writeFile(tw, f, e.files[f].Bytes(), now); err != nil {
+			return Manifest{}, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return m, nil
+}
+
+func writeFile(tw *tar.Writer, name string, contents []byte, modified time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(contents)),
+		ModTime: modified,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(contents)
+	return err
+}
+
+func (e *exporter) add(file string, record interface{}) error {
+	if err := json.NewEncoder(e.files[file]).Encode(record); err != nil {
+		return err
+	}
+	e.counts[file]++
+	return nil
+}
+
+func (e *exporter) exportAll() error {
+	users, err := e.datastore.AllUsernames()
+	if err != nil {
+		return err
+	}
+
+	teams := map[string]types.Team{}
+	for _, username := range users {
+		if err := e.exportUser(username); err != nil {
+			return err
+		}
+		userTeams, err := e.datastore.GetTeamsForUser(username)
+		if err != nil {
+			return err
+		}
+		for _, t := range userTeams {
+			teams[t.ID] = t
+		}
+	}
+
+	teamIDs := []string{}
+	for id := range teams {
+		teamIDs = append(teamIDs, id)
+	}
+	sort.Strings(teamIDs)
+	for _, id := range teamIDs {
+		if err := e.add(teamsFile, teams[id]); err != nil {
+			return err
+		}
+		if err := e.exportSlackIntegration(types.TeamSlackOwner(id)); err != nil {
+			return err
+		}
+	}
+
+	subs, err := e.datastore.GetDigestSubscriptions()
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := e.add(digestsFile, digestRecord{
+			Username:     sub.Username,
+			Subscription: sub,
+			LastSentAt:   sub.LastSentAt,
+		}); err != nil {
+			return err
+		}
+	}
+
+	sync, err := e.datastore.GetPageViewSync()
+	if _, ok := err.(datastore.PageViewSyncNotFoundError); ok {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return e.add(pageViewSyncFile, sync)
+}
+
+func (e *exporter) exportUser(username string) error {
+	profile, err := e.datastore.GetUserProfile(username)
+	if err == nil {
+		if err := e.add(profilesFile, profileRecord{Username: username, Profile: profile}); err != nil {
+			return err
+		}
+	} else if _, ok := err.(datastore.UserProfileNotFoundError); !ok {
+		return err
+	}
+
+	entries, err := e.datastore.GetEntries(username)
+	if err != nil {
+		return err
+	}
+	for _, j := range entries {
+		if err := e.exportEntry(username, j); err != nil {
+			return err
+		}
+	}
+
+	drafts, err := e.datastore.GetDrafts(username)
+	if err != nil {
+		return err
+	}
+	for _, d := range drafts {
+		if err := e.add(draftsFile, entryRecord{Username: username, Entry: d}); err != nil {
+			return err
+		}
+	}
+
+	following, err := e.datastore.GetFollowing(username)
+	if err != nil {
+		return err
+	}
+	for _, followee := range following {
+		if err := e.add(followsFile, followRecord{Follower: username, Followee: followee}); err != nil {
+			return err
+		}
+	}
+
+	reminders, err := e.datastore.GetReminderPreferences(username)
+	if err == nil {
+		if err := e.add(remindersFile, reminderRecord{
+			Username:    username,
+			Preferences: reminders,
+			LastSentFor: reminders.LastSentFor,
+		}); err != nil {
+			return err
+		}
+	} else if _, ok := err.(datastore.ReminderPreferencesNotFoundError); !ok {
+		return err
+	}
+
+	notifyPrefs, err := e.datastore.GetNotificationPreferences(username)
+	if err == nil {
+		if err := e.add(notifyPrefsFile, notifyPrefsRecord{Username: username, Preferences: notifyPrefs}); err != nil {
+			return err
+		}
+	} else if _, ok := err.(datastore.NotificationPreferencesNotFoundError); !ok {
+		return err
+	}
+
+	notifications, err := e.datastore.GetNotifications(username)
+	if err != nil {
+		return err
+	}
+	for _, n := range notifications {
+		if err := e.add(notificationsFile, notificationRecord{
+			Recipient:    username,
+			Notification: n,
+			Emailed:      n.Emailed,
+		}); err != nil {
+			return err
+		}
+	}
+
+	hooks, err := e.datastore.GetWebhooks(username)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if err := e.add(webhooksFile, webhookRecord{Owner: username, Webhook: hook, Secret: hook.Secret}); err != nil {
+			return err
+		}
+		if err := e.exportWebhookDeliveries(hook.ID); err != nil {
+			return err
+		}
+	}
+
+	return e.exportSlackIntegration(types.UserSlackOwner(username))
+}
+
+func (e *exporter) exportWebhookDeliveries(webhookID string) error {
+	for start := 0; ; start += deliveriesPageSize {
+		deliveries, err := e.datastore.GetWebhookDeliveries(webhookID, start, deliveriesPageSize)
+		if err != nil {
+			return err
+		}
+		for _, d := range deliveries {
+			if err := e.add(deliveriesFile, d); err != nil {
+				return err
+			}
+		}
+		if len(deliveries) < deliveriesPageSize {
+			return nil
+		}
+	}
+}
+
+func (e *exporter) exportEntry(username string, j types.JournalEntry) error {
+	if err := e.add(entriesFile, entryRecord{Username: username, Entry: j}); err != nil {
+		return err
+	}
+
+	reactions, err := e.datastore.GetReactions(username, j.Date)
+	if err != nil {
+		return err
+	}
+	for _, r := range reactions {
+		if err := e.add(reactionsFile, reactionRecord{EntryAuthor: username, EntryDate: j.Date, Reaction: r}); err != nil {
+			return err
+		}
+	}
+
+	comments, err := e.datastore.GetComments(username, j.Date)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		if err := e.add(commentsFile, commentRecord{EntryAuthor: username, EntryDate: j.Date, Comment: c}); err != nil {
+			return err
+		}
+	}
+
+	path := "/" + username + "/" + j.Date
+	views, err := e.datastore.GetPageViews(path)
+	if err == nil {
+		if err := e.add(pageViewsFile, pageViewsRecord{Path: path, Views: views}); err != nil {
+			return err
+		}
+	} else if _, ok := err.(datastore.PageViewsNotFoundError); !ok {
+		return err
+	}
+
+	daily, err := e.datastore.GetDailyPageViews(path, earliestPageViewDay, latestPageViewDay)
+	if err != nil {
+		return err
+	}
+	for _, pv := range daily {
+		if err := e.add(dailyPageViewsFile, pv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportSlackIntegration(owner string) error {
+	i, err := e.datastore.GetSlackIntegration(owner)
+	if _, ok := err.(datastore.SlackIntegrationNotFoundError); ok {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return e.add(slackFile, slackRecord{Owner: owner, Integration: i, WebhookURL: i.WebhookURL})
+}
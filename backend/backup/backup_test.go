@@ -0,0 +1,496 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// memoryDatastore keeps data in maps keyed the same way that the Firestore
+// datastore keys its documents.
+type memoryDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that backups use.
+	datastore.Datastore
+	profiles      map[string]types.UserProfile
+	entries       map[string]map[string]types.JournalEntry
+	drafts        map[string]map[string]types.JournalEntry
+	reactions     map[string]map[string]types.Reaction
+	comments      map[string]map[string]types.Comment
+	pageViews     map[string]int
+	daily         map[string]types.DailyPageViews
+	sync          *types.PageViewSync
+	teams         map[string]types.Team
+	following     map[string]map[string]bool
+	reminders     map[string]types.ReminderPreferences
+	digests       map[string]types.DigestSubscription
+	notifyPrefs   map[string]types.NotificationPreferences
+	notifications map[string]types.Notification
+	webhooks      map[string]types.Webhook
+	deliveries    map[string]types.WebhookDelivery
+	slack         map[string]types.SlackIntegration
+}
+
+func newMemoryDatastore() *memoryDatastore {
+	return &memoryDatastore{
+		profiles:      map[string]types.UserProfile{},
+		entries:       map[string]map[string]types.JournalEntry{},
+		drafts:        map[string]map[string]types.JournalEntry{},
+		reactions:     map[string]map[string]types.Reaction{},
+		comments:      map[string]map[string]types.Comment{},
+		pageViews:     map[string]int{},
+		daily:         map[string]types.DailyPageViews{},
+		teams:         map[string]types.Team{},
+		following:     map[string]map[string]bool{},
+		reminders:     map[string]types.ReminderPreferences{},
+		digests:       map[string]types.DigestSubscription{},
+		notifyPrefs:   map[string]types.NotificationPreferences{},
+		notifications: map[string]types.Notification{},
+		webhooks:      map[string]types.Webhook{},
+		deliveries:    map[string]types.WebhookDelivery{},
+		slack:         map[string]types.SlackIntegration{},
+	}
+}
+
+func (ds *memoryDatastore) AllUsernames() ([]string, error) {
+	seen := map[string]bool{}
+	for u := range ds.profiles {
+		seen[u] = true
+	}
+	for u := range ds.entries {
+		seen[u] = true
+	}
+	for u := range ds.drafts {
+		seen[u] = true
+	}
+	for u := range ds.following {
+		seen[u] = true
+	}
+	for u := range ds.reminders {
+		seen[u] = true
+	}
+	for u := range ds.digests {
+		seen[u] = true
+	}
+	for u := range ds.notifyPrefs {
+		seen[u] = true
+	}
+	for _, n := range ds.notifications {
+		seen[n.Recipient] = true
+	}
+	for _, hook := range ds.webhooks {
+		seen[hook.Owner] = true
+	}
+	for owner := range ds.slack {
+		if strings.HasPrefix(owner, "user:") {
+			seen[strings.TrimPrefix(owner, "user:")] = true
+		}
+	}
+	for _, t := range ds.teams {
+		for _, m := range t.Members {
+			seen[m.Username] = true
+		}
+		for _, invitee := range t.Invitees {
+			seen[invitee] = true
+		}
+	}
+	users := []string{}
+	for u := range seen {
+		users = append(users, u)
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+func (ds *memoryDatastore) GetUserProfile(username string) (types.UserProfile, error) {
+	p, ok := ds.profiles[username]
+	if !ok {
+		return types.UserProfile{}, datastore.UserProfileNotFoundError{Username: username}
+	}
+	return p, nil
+}
+
+func (ds *memoryDatastore) SetUserProfile(username string, p types.UserProfile) error {
+	ds.profiles[username] = p
+	return nil
+}
+
+func (ds *memoryDatastore) GetEntries(username string) ([]types.JournalEntry, error) {
+	entries := []types.JournalEntry{}
+	for _, j := range ds.entries[username] {
+		entries = append(entries, j)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Date < entries[j].Date })
+	return entries, nil
+}
+
+func (ds *memoryDatastore) InsertEntry(username string, j types.JournalEntry) error {
+	if ds.entries[username] == nil {
+		ds.entries[username] = map[string]types.JournalEntry{}
+	}
+	ds.entries[username][j.Date] = j
+	return nil
+}
+
+func (ds *memoryDatastore) GetDrafts(username string) ([]types.JournalEntry, error) {
+	drafts := []types.JournalEntry{}
+	for _, j := range ds.drafts[username] {
+		drafts = append(drafts, j)
+	}
+	return drafts, nil
+}
+
+func (ds *memoryDatastore) InsertDraft(username string, j types.JournalEntry) error {
+	if ds.drafts[username] == nil {
+		ds.drafts[username] = map[string]types.JournalEntry{}
+	}
+	ds.drafts[username][j.Date] = j
+	return nil
+}
+
+func (ds *memoryDatastore) GetReactions(entryAuthor string, entryDate string) ([]types.Reaction, error) {
+	reactions := []types.Reaction{}
+	for _, r := range ds.reactions[entryAuthor+"/"+entryDate] {
+		reactions = append(reactions, r)
+	}
+	return reactions, nil
+}
+
+func (ds *memoryDatastore) AddReaction(entryAuthor string, entryDate string, r types.Reaction) error {
+	key := entryAuthor + "/" + entryDate
+	if ds.reactions[key] == nil {
+		ds.reactions[key] = map[string]types.Reaction{}
+	}
+	ds.reactions[key][r.Username] = r
+	return nil
+}
+
+func (ds *memoryDatastore) GetComments(entryAuthor string, entryDate string) ([]types.Comment, error) {
+	comments := []types.Comment{}
+	for _, c := range ds.comments[entryAuthor+"/"+entryDate] {
+		comments = append(comments, c)
+	}
+	return comments, nil
+}
+
+func (ds *memoryDatastore) SetComment(entryAuthor string, entryDate string, c types.Comment) error {
+	key := entryAuthor + "/" + entryDate
+	if ds.comments[key] == nil {
+		ds.comments[key] = map[string]types.Comment{}
+	}
+	ds.comments[key][c.ID] = c
+	return nil
+}
+
+func (ds *memoryDatastore) GetPageViews(path string) (int, error) {
+	views, ok := ds.pageViews[path]
+	if !ok {
+		return 0, datastore.PageViewsNotFoundError{Path: path}
+	}
+	return views, nil
+}
+
+func (ds *memoryDatastore) InsertPageViews(path string, views int) error {
+	ds.pageViews[path] = views
+	return nil
+}
+
+func (ds *memoryDatastore) GetDailyPageViews(path string, from string, to string) ([]types.DailyPageViews, error) {
+	daily := []types.DailyPageViews{}
+	for _, pv := range ds.daily {
+		if pv.Path == path && pv.Date >= from && pv.Date <= to {
+			daily = append(daily, pv)
+		}
+	}
+	return daily, nil
+}
+
+func (ds *memoryDatastore) InsertDailyPageViews(pv types.DailyPageViews) error {
+	ds.daily[pv.Path+"@"+pv.Date] = pv
+	return nil
+}
+
+func (ds *memoryDatastore) GetPageViewSync() (types.PageViewSync, error) {
+	if ds.sync == nil {
+		return types.PageViewSync{}, datastore.PageViewSyncNotFoundError{}
+	}
+	return *ds.sync, nil
+}
+
+func (ds *memoryDatastore) SetPageViewSync(sync types.PageViewSync) error {
+	ds.sync = &sync
+	return nil
+}
+
+func (ds *memoryDatastore) GetTeamsForUser(username string) ([]types.Team, error) {
+	teams := []types.Team{}
+	for _, t := range ds.teams {
+		for _, m := range t.Members {
+			if m.Username == username {
+				teams = append(teams, t)
+			}
+		}
+	}
+	return teams, nil
+}
+
+func (ds *memoryDatastore) SetTeam(team types.Team) error {
+	ds.teams[team.ID] = team
+	return nil
+}
+
+func (ds *memoryDatastore) GetFollowing(username string) ([]string, error) {
+	following := []string{}
+	for f := range ds.following[username] {
+		following = append(following, f)
+	}
+	return following, nil
+}
+
+func (ds *memoryDatastore) Follow(follower string, followee string) error {
+	if ds.following[follower] == nil {
+		ds.following[follower] = map[string]bool{}
+	}
+	ds.following[follower][followee] = true
+	return nil
+}
+
+func (ds *memoryDatastore) GetReminderPreferences(username string) (types.ReminderPreferences, error) {
+	p, ok := ds.reminders[username]
+	if !ok {
+		return types.ReminderPreferences{}, datastore.ReminderPreferencesNotFoundError{Username: username}
+	}
+	return p, nil
+}
+
+func (ds *memoryDatastore) SetReminderPreferences(username string, p types.ReminderPreferences) error {
+	ds.reminders[username] = p
+	return nil
+}
+
+func (ds *memoryDatastore) GetDigestSubscriptions() ([]types.DigestSubscription, error) {
+	subs := []types.DigestSubscription{}
+	for _, sub := range ds.digests {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (ds *memoryDatastore) SetDigestSubscription(username string, sub types.DigestSubscription) error {
+	ds.digests[username] = sub
+	return nil
+}
+
+func (ds *memoryDatastore) GetNotificationPreferences(username string) (types.NotificationPreferences, error) {
+	p, ok := ds.notifyPrefs[username]
+	if !ok {
+		return types.NotificationPreferences{}, datastore.NotificationPreferencesNotFoundError{Username: username}
+	}
+	return p, nil
+}
+
+func (ds *memoryDatastore) SetNotificationPreferences(username string, p types.NotificationPreferences) error {
+	ds.notifyPrefs[username] = p
+	return nil
+}
+
+func (ds *memoryDatastore) GetNotifications(recipient string) ([]types.Notification, error) {
+	notifications := []types.Notification{}
+	for _, n := range ds.notifications {
+		if n.Recipient == recipient {
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications, nil
+}
+
+func (ds *memoryDatastore) SetNotification(n types.Notification) error {
+	ds.notifications[n.ID] = n
+	return nil
+}
+
+func (ds *memoryDatastore) GetWebhooks(owner string) ([]types.Webhook, error) {
+	hooks := []types.Webhook{}
+	for _, hook := range ds.webhooks {
+		if hook.Owner == owner {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (ds *memoryDatastore) SetWebhook(hook types.Webhook) error {
+	ds.webhooks[hook.ID] = hook
+	return nil
+}
+
+func (ds *memoryDatastore) GetWebhookDeliveries(webhookID string, start, limit int) ([]types.WebhookDelivery, error) {
+	deliveries := []types.WebhookDelivery{}
+	for _, d := range ds.deliveries {
+		if d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].Timestamp > deliveries[j].Timestamp
+	})
+	if start > len(deliveries) {
+		start = len(deliveries)
+	}
+	deliveries = deliveries[start:]
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (ds *memoryDatastore) AddWebhookDelivery(d types.WebhookDelivery) error {
+	ds.deliveries[d.ID] = d
+	return nil
+}
+
+func (ds *memoryDatastore) GetSlackIntegration(owner string) (types.SlackIntegration, error) {
+	i, ok := ds.slack[owner]
+	if !ok {
+		return types.SlackIntegration{}, datastore.SlackIntegrationNotFoundError{Owner: owner}
+	}
+	return i, nil
+}
+
+func (ds *memoryDatastore) SetSlackIntegration(i types.SlackIntegration) error {
+	ds.slack[i.Owner] = i
+	return nil
+}
+
+func populatedDatastore() *memoryDatastore {
+	ds := newMemoryDatastore()
+	ds.SetUserProfile("alice", types.UserProfile{AboutMarkdown: "I build things", EmailAddress: "alice@example.com"})
+	ds.InsertEntry("alice", types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T12:00:00Z", Markdown: "Shipped the backup command", Visibility: types.VisibilityPublic})
+	ds.InsertEntry("alice", types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22T12:00:00Z", Markdown: "Planned the team offsite", Visibility: types.VisibilityTeam, TeamID: "team-1"})
+	ds.InsertEntry("bob", types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T13:00:00Z", Markdown: "Thanks @alice", Mentions: []string{"alice"}})
+	ds.InsertDraft("alice", types.JournalEntry{Date: "2019-12-06", LastModified: "2019-12-02T09:00:00Z", Markdown: "Still writing"})
+	ds.AddReaction("alice", "2019-11-29", types.Reaction{Username: "bob", Symbol: "🎉", Timestamp: "2019-11-29T14:00:00Z"})
+	ds.SetComment("alice", "2019-11-29", types.Comment{ID: "comment-1", Username: "bob", Markdown: "Nice work", Timestamp: "2019-11-29T14:05:00Z"})
+	ds.InsertPageViews("/alice/2019-11-29", 42)
+	ds.InsertDailyPageViews(types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 30})
+	ds.InsertDailyPageViews(types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 12})
+	ds.SetPageViewSync(types.PageViewSync{LastSyncedDate: "2019-11-30"})
+	ds.SetTeam(types.Team{ID: "team-1", Name: "Lighthouse", Members: []types.TeamMember{{Username: "alice", Role: types.TeamRoleAdmin}}, Invitees: []string{"carol"}})
+	ds.Follow("bob", "alice")
+	ds.SetReminderPreferences("alice", types.ReminderPreferences{Username: "alice", Enabled: true, SendTime: "17:00", TimeZone: "America/New_York", LastSentFor: "2019-11-29"})
+	ds.SetDigestSubscription("bob", types.DigestSubscription{Username: "bob", Frequency: types.DigestWeekly, Authors: []string{"alice"}, LastSentAt: "2019-11-25T00:00:00Z"})
+	ds.SetNotificationPreferences("alice", types.NotificationPreferences{Username: "alice", EmailEnabled: true})
+	ds.SetNotification(types.Notification{ID: "n-1", Recipient: "alice", Type: types.NotificationReaction, Actor: "bob", EntryAuthor: "alice", EntryDate: "2019-11-29", Symbol: "🎉", Timestamp: "2019-11-29T14:00:00Z", Emailed: true})
+	ds.SetNotification(types.Notification{ID: "n-2", Recipient: "alice", Type: types.NotificationMention, Actor: "bob", EntryAuthor: "bob", EntryDate: "2019-11-29", Timestamp: "2019-11-29T13:00:00Z", Read: true, Emailed: true})
+	// Dave has never published, but his data still belongs in backups.
+	ds.SetUserProfile("dave", types.UserProfile{AboutMarkdown: "Just reading for now"})
+	ds.Follow("dave", "alice")
+	ds.SetNotificationPreferences("dave", types.NotificationPreferences{Username: "dave", EmailEnabled: true})
+	ds.SetWebhook(types.Webhook{ID: "hook-1", Owner: "alice", URL: "https://example.com/hook", Events: []types.WebhookEvent{types.WebhookEntryPublished}, Secret: "s3cret", CreatedAt: "2019-11-01T00:00:00Z"})
+	ds.AddWebhookDelivery(types.WebhookDelivery{ID: "delivery-1", WebhookID: "hook-1", Event: types.WebhookEntryPublished, Timestamp: "2019-11-29T12:00:01Z", Attempts: 1, StatusCode: 200, Success: true})
+	ds.SetSlackIntegration(types.SlackIntegration{Owner: types.UserSlackOwner("alice"), WebhookURL: "https://hooks.slack.com/services/alice", CreatedBy: "alice"})
+	ds.SetSlackIntegration(types.SlackIntegration{Owner: types.TeamSlackOwner("team-1"), WebhookURL: "https://hooks.slack.com/services/team", CreatedBy: "alice"})
+	return ds
+}
+
+func TestExportAndRestoreRoundTrip(t *testing.T) {
+	source := populatedDatastore()
+	now := time.Date(2019, 12, 1, 8, 0, 0, 0, time.UTC)
+
+	var archive bytes.Buffer
+	exported, err := Export(source, &archive, now)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if exported.FormatVersion != FormatVersion || exported.CreatedAt != "2019-12-01T08:00:00Z" {
+		t.Errorf("unexpected manifest: %+v", exported)
+	}
+	if exported.Records[entriesFile] != 3 || exported.Records[dailyPageViewsFile] != 2 || exported.Records[slackFile] != 2 {
+		t.Errorf("unexpected record counts: %v", exported.Records)
+	}
+
+	target := newMemoryDatastore()
+	// Restoring twice must leave the same data as restoring once.
+	for i := 0; i < 2; i++ {
+		restored, err := Restore(target, bytes.NewReader(archive.Bytes()))
+		if err != nil {
+			t.Fatalf("Restore failed: %v", err)
+		}
+		if !reflect.DeepEqual(restored, exported) {
+			t.Errorf("restored manifest %+v, want %+v", restored, exported)
+		}
+	}
+
+	// Clear the embedded interfaces so that only the stored data is compared.
+	target.Datastore = nil
+	source.Datastore = nil
+	if !reflect.DeepEqual(target, source) {
+		t.Errorf("restored datastore differs from original:\ngot  %+v\nwant %+v", target, source)
+	}
+}
+
+func makeArchive(t *testing.T, files map[string]string, order []string) []byte {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+	for _, name := range order {
+		if err := writeFile(tw, name, []byte(files[name]), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestRestoreRejectsInvalidArchives(t *testing.T) {
+	var tests = []struct {
+		explanation string
+		files       map[string]string
+		order       []string
+		errContains string
+	}{
+		{
+			"newer format version",
+			map[string]string{manifestFile: `{"formatVersion": 2}`},
+			[]string{manifestFile},
+			"unsupported archive format version 2",
+		},
+		{
+			"missing manifest",
+			map[string]string{entriesFile: ""},
+			[]string{entriesFile},
+			"must start with manifest.json",
+		},
+		{
+			"unknown record file",
+			map[string]string{manifestFile: `{"formatVersion": 1}`, "secrets.jsonl": ""},
+			[]string{manifestFile, "secrets.jsonl"},
+			"unrecognized file",
+		},
+		{
+			"malformed record",
+			map[string]string{manifestFile: `{"formatVersion": 1}`, entriesFile: "{\"username\": \"alice\"}\nnot json\n"},
+			[]string{manifestFile, entriesFile},
+			"entries.jsonl line 2",
+		},
+	}
+	for _, tt := range tests {
+		_, err := Restore(newMemoryDatastore(), bytes.NewReader(makeArchive(t, tt.files, tt.order)))
+		if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+			t.Errorf("%s: got error %v, want error containing %q", tt.explanation, err, tt.errContains)
+		}
+	}
+}
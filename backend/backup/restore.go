@@ -0,0 +1,233 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// maxRecordSize is the size of the longest line that Restore accepts in a
+// record file.
+const maxRecordSize = 16 * 1024 * 1024
+
+// Restore writes every record in the archive from r to the datastore and
+// returns the archive's manifest. Each record overwrites any existing data
+// with the same key, so restoring the same archive again leaves the datastore
+// unchanged.
+func Restore(ds datastore.Datastore, r io.Reader) (Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	m, err := readManifest(tr)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Manifest{}, err
+		}
+		restore, ok := restorers[hdr.Name]
+		if !ok {
+			return Manifest{}, fmt.Errorf("unrecognized file in archive: %s", hdr.Name)
+		}
+		if err := restoreFile(ds, hdr.Name, tr, restore); err != nil {
+			return Manifest{}, err
+		}
+	}
+	return m, nil
+}
+
+func readManifest(tr *tar.Reader) (Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read archive: %s", err)
+	}
+	if hdr.Name != manifestFile {
+		return Manifest{}, fmt.Errorf("archive must start with %s, found %s", manifestFile, hdr.Name)
+	}
+	contents, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(contents, &m); err != nil {
+		return Manifest{}, fmt.Errorf("invalid manifest: %s", err)
+	}
+	if m.FormatVersion < 1 || m.FormatVersion > FormatVersion {
+		return Manifest{}, fmt.Errorf("unsupported archive format version %d (this version of What Got Done supports up to %d)", m.FormatVersion, FormatVersion)
+	}
+	return m, nil
+}
+
+func restoreFile(ds datastore.Datastore, name string, r io.Reader, restore func(datastore.Datastore, []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := restore(ds, scanner.Bytes()); err != nil {
+			return fmt.Errorf("failed to restore %s line %d: %s", name, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// restorers map each record file to a function that writes one of its records
+// to the datastore.
+var restorers = map[string]func(datastore.Datastore, []byte) error{
+	profilesFile: func(ds datastore.Datastore, b []byte) error {
+		var rec profileRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		return ds.SetUserProfile(rec.Username, rec.Profile)
+	},
+	entriesFile: func(ds datastore.Datastore, b []byte) error {
+		var rec entryRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		return ds.InsertEntry(rec.Username, rec.Entry)
+	},
+	draftsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec entryRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		return ds.InsertDraft(rec.Username, rec.Entry)
+	},
+	reactionsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec reactionRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		return ds.AddReaction(rec.EntryAuthor, rec.EntryDate, rec.Reaction)
+	},
+	commentsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec commentRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		return ds.SetComment(rec.EntryAuthor, rec.EntryDate, rec.Comment)
+	},
+	pageViewsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec pageViewsRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		return ds.InsertPageViews(rec.Path, rec.Views)
+	},
+	dailyPageViewsFile: func(ds datastore.Datastore, b []byte) error {
+		var pv types.DailyPageViews
+		if err := json.Unmarshal(b, &pv); err != nil {
+			return err
+		}
+		return ds.InsertDailyPageViews(pv)
+	},
+	pageViewSyncFile: func(ds datastore.Datastore, b []byte) error {
+		var sync types.PageViewSync
+		if err := json.Unmarshal(b, &sync); err != nil {
+			return err
+		}
+		return ds.SetPageViewSync(sync)
+	},
+	teamsFile: func(ds datastore.Datastore, b []byte) error {
+		var team types.Team
+		if err := json.Unmarshal(b, &team); err != nil {
+			return err
+		}
+		return ds.SetTeam(team)
+	},
+	followsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec followRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		return ds.Follow(rec.Follower, rec.Followee)
+	},
+	remindersFile: func(ds datastore.Datastore, b []byte) error {
+		var rec reminderRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		p := rec.Preferences
+		p.Username = rec.Username
+		p.LastSentFor = rec.LastSentFor
+		return ds.SetReminderPreferences(rec.Username, p)
+	},
+	digestsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec digestRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		sub := rec.Subscription
+		sub.Username = rec.Username
+		sub.LastSentAt = rec.LastSentAt
+		return ds.SetDigestSubscription(rec.Username, sub)
+	},
+	notifyPrefsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec notifyPrefsRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		p := rec.Preferences
+		p.Username = rec.Username
+		return ds.SetNotificationPreferences(rec.Username, p)
+	},
+	notificationsFile: func(ds datastore.Datastore, b []byte) error {
+		var rec notificationRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		n := rec.Notification
+		n.Recipient = rec.Recipient
+		n.Emailed = rec.Emailed
+		return ds.SetNotification(n)
+	},
+	webhooksFile: func(ds datastore.Datastore, b []byte) error {
+		var rec webhookRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		hook := rec.Webhook
+		hook.Owner = rec.Owner
+		hook.Secret = rec.Secret
+		return ds.SetWebhook(hook)
+	},
+	deliveriesFile: func(ds datastore.Datastore, b []byte) error {
+		var d types.WebhookDelivery
+		if err := json.Unmarshal(b, &d); err != nil {
+			return err
+		}
+		return ds.AddWebhookDelivery(d)
+	},
+	slackFile: func(ds datastore.Datastore, b []byte) error {
+		var rec slackRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return err
+		}
+		i := rec.Integration
+		i.Owner = rec.Owner
+		i.WebhookURL = rec.WebhookURL
+		return ds.SetSlackIntegration(i)
+	},
+}
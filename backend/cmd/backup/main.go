@@ -0,0 +1,40 @@
+// Command backup exports all What Got Done data from the datastore to a
+// portable archive that the restore command can load into any datastore.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/backup"
+	"github.com/mtlynch/whatgotdone/backend/datastore/firestore"
+)
+
+func main() {
+	now := time.Now()
+	output := flag.String("output", fmt.Sprintf("whatgotdone-backup-%s.tar.gz", now.UTC().Format("20060102-150405")), "path of the archive to write")
+	flag.Parse()
+
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %s", *output, err)
+	}
+
+	m, err := backup.Export(firestore.New(), f, now)
+	if err != nil {
+		f.Close()
+		os.Remove(*output)
+		log.Fatalf("Failed to export data: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("Failed to write %s: %s", *output, err)
+	}
+
+	for name, count := range m.Records {
+		log.Printf("Exported %d records to %s", count, name)
+	}
+	log.Printf("Wrote backup to %s", *output)
+}
@@ -0,0 +1,89 @@
+// Command import publishes a user's past updates from a zip archive or
+// directory of markdown files. It prints what it would publish and, unless
+// the -dry-run flag is set, publishes the entries if every file is valid.
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore/firestore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/importer"
+)
+
+func main() {
+	username := flag.String("user", "", "username to publish the entries as")
+	path := flag.String("path", "", "zip archive or directory of markdown files to import")
+	dryRun := flag.Bool("dry-run", false, "print what the import would publish without publishing")
+	overwrite := flag.Bool("overwrite", false, "replace existing entries with the same dates")
+	flag.Parse()
+	if !validate.Username(*username) {
+		log.Fatalf("The -user flag must be a valid username")
+	}
+	if *path == "" {
+		log.Fatalf("The -path flag is required")
+	}
+
+	files, err := readFiles(*path)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %s", *path, err)
+	}
+
+	ds := firestore.New()
+	plan, err := importer.Prepare(ds, *username, files, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to prepare import: %s", err)
+	}
+
+	for _, f := range plan.Skipped {
+		log.Printf("Skipping %s: not an entry", f)
+	}
+	for _, e := range plan.Entries {
+		if e.Conflict && *overwrite {
+			log.Printf("%s: replaces entry for %s (last modified %s)", e.File, e.Date, e.ExistingLastModified)
+		} else if e.Conflict {
+			log.Printf("%s: skipping, %s already has an entry for %s (use -overwrite to replace it)", e.File, *username, e.Date)
+		} else {
+			log.Printf("%s: new entry for %s", e.File, e.Date)
+		}
+	}
+	for _, f := range plan.Invalid {
+		log.Printf("%s: invalid: %s", f.File, f.Reason)
+	}
+	if len(plan.Invalid) > 0 {
+		log.Fatalf("Fix the %d invalid files and try again", len(plan.Invalid))
+	}
+	if *dryRun {
+		return
+	}
+
+	published, err := importer.Publish(ds, *username, plan, *overwrite)
+	if err != nil {
+		log.Fatalf("Failed to import entries after publishing %d: %s", published, err)
+	}
+	log.Printf("Published %d entries for %s", published, *username)
+}
+
+func readFiles(path string) ([]importer.File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return importer.ReadDir(path)
+	}
+	if !strings.HasSuffix(strings.ToLower(path), ".zip") {
+		return nil, errors.New("path must be a zip archive or a directory")
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return importer.ReadZip(b)
+}
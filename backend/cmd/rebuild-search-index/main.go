@@ -0,0 +1,35 @@
+// Command rebuild-search-index regenerates the search and tag indexes from all
+// published entries in the datastore. Entries published after the indexes
+// were introduced are indexed automatically, so this only needs to run
+// once for pre-existing data or after changes to how entries are tokenized.
+package main
+
+import (
+	"log"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore/firestore"
+)
+
+func main() {
+	ds := firestore.New()
+
+	users, err := ds.Users()
+	if err != nil {
+		log.Fatalf("Failed to retrieve users: %s", err)
+	}
+
+	indexed := 0
+	for _, username := range users {
+		entries, err := ds.GetEntries(username)
+		if err != nil {
+			log.Fatalf("Failed to retrieve entries for user %s: %s", username, err)
+		}
+		for _, j := range entries {
+			if err := ds.IndexEntry(username, j); err != nil {
+				log.Fatalf("Failed to index entry %s/%s: %s", username, j.Date, err)
+			}
+			indexed++
+		}
+	}
+	log.Printf("Indexed %d entries from %d users", indexed, len(users))
+}
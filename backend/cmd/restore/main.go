@@ -0,0 +1,37 @@
+// Command restore loads an archive that the backup command created into the
+// datastore. Restoring overwrites existing data with the same keys, so it's
+// safe to restore the same archive more than once, but it never deletes data
+// that is absent from the archive.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/mtlynch/whatgotdone/backend/backup"
+	"github.com/mtlynch/whatgotdone/backend/datastore/firestore"
+)
+
+func main() {
+	input := flag.String("input", "", "path of the archive to restore")
+	flag.Parse()
+	if *input == "" {
+		log.Fatalf("The -input flag is required")
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %s", *input, err)
+	}
+	defer f.Close()
+
+	m, err := backup.Restore(firestore.New(), f)
+	if err != nil {
+		log.Fatalf("Failed to restore %s: %s", *input, err)
+	}
+	for name, count := range m.Records {
+		log.Printf("Restored %d records from %s", count, name)
+	}
+	log.Printf("Restored backup created at %s", m.CreatedAt)
+}
@@ -0,0 +1,41 @@
+// Package codefence recognizes fenced code blocks in markdown, so that code
+// samples in entries don't count as tags, mentions, or headings.
+package codefence
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fencePattern matches a line that starts or ends a fenced code block: a run of
+// at least three backticks or tildes, indented by at most three spaces. This is
+// the same rule that the frontend's markdown renderer follows.
+var fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+
+// Opening returns the fence that starts a fenced code block on the given line,
+// or an empty string if the line doesn't start one.
+func Opening(line string) string {
+	match := fencePattern.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	fence, info := match[1], match[2]
+	// A backtick fence's info string can't contain backticks, or the line would
+	// be inline code instead.
+	if fence[0] == '`' && strings.Contains(info, "`") {
+		return ""
+	}
+	return fence
+}
+
+// Closes returns true if the given line ends a fenced code block that started
+// with fence. The closing fence must use the same character as the opening
+// fence, be at least as long, and have nothing after it but spaces.
+func Closes(line string, fence string) bool {
+	match := fencePattern.FindStringSubmatch(line)
+	if match == nil {
+		return false
+	}
+	closing, rest := match[1], match[2]
+	return closing[0] == fence[0] && len(closing) >= len(fence) && strings.TrimSpace(rest) == ""
+}
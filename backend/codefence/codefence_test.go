@@ -0,0 +1,128 @@
+package codefence
+
+import (
+	"testing"
+)
+
+func TestOpening(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		line          string
+		fenceExpected string
+	}{
+		{
+			"backticks start a code block",
+			"```",
+			"```",
+		},
+		{
+			"tildes start a code block",
+			"~~~",
+			"~~~",
+		},
+		{
+			"fence can be longer than three characters",
+			"`````",
+			"`````",
+		},
+		{
+			"fence can have an info string",
+			"```go",
+			"```",
+		},
+		{
+			"fence can be indented by up to three spaces",
+			"   ~~~",
+			"~~~",
+		},
+		{
+			"fence indented by four spaces is an indented code block instead",
+			"    ```",
+			"",
+		},
+		{
+			"two backticks are not a fence",
+			"``",
+			"",
+		},
+		{
+			"backtick fence can't have backticks in its info string",
+			"``` inline ` code",
+			"",
+		},
+		{
+			"tilde fence can have backticks in its info string",
+			"~~~ `code`",
+			"~~~",
+		},
+		{
+			"text before the fence is not a fence",
+			"Wrote ```code```",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		fence := Opening(tt.line)
+		if fence != tt.fenceExpected {
+			t.Errorf("%s: Opening(%q)=%q, want %q", tt.explanation, tt.line, fence, tt.fenceExpected)
+		}
+	}
+}
+
+func TestCloses(t *testing.T) {
+	var tests = []struct {
+		explanation    string
+		line           string
+		fence          string
+		closesExpected bool
+	}{
+		{
+			"same fence closes the block",
+			"```",
+			"```",
+			true,
+		},
+		{
+			"longer fence closes the block",
+			"~~~~~",
+			"~~~",
+			true,
+		},
+		{
+			"indented fence with trailing spaces closes the block",
+			"  ```  ",
+			"```",
+			true,
+		},
+		{
+			"shorter fence does not close the block",
+			"```",
+			"````",
+			false,
+		},
+		{
+			"tildes don't close a backtick block",
+			"~~~",
+			"```",
+			false,
+		},
+		{
+			"backticks don't close a tilde block",
+			"```",
+			"~~~",
+			false,
+		},
+		{
+			"fence with an info string does not close the block",
+			"```go",
+			"```",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		closes := Closes(tt.line, tt.fence)
+		if closes != tt.closesExpected {
+			t.Errorf("%s: Closes(%q, %q)=%v, want %v", tt.explanation, tt.line, tt.fence, closes, tt.closesExpected)
+		}
+	}
+}
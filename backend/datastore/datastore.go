@@ -6,6 +6,7 @@ package datastore
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/mtlynch/whatgotdone/backend/types"
 )
@@ -16,29 +17,206 @@ import (
 type Datastore interface {
 	// Users returns all the users who have published entries.
 	Users() ([]string, error)
+	// AllUsernames returns every user who has data in any collection, including
+	// users who have never published an entry, sorted alphabetically.
+	AllUsernames() ([]string, error)
 	// GetUserProfile returns profile information for the given user.
 	GetUserProfile(username string) (types.UserProfile, error)
 	// SetUserProfile updates the given user's profile.
 	SetUserProfile(username string, profile types.UserProfile) error
+	// DeleteUserProfile removes the given user's profile.
+	DeleteUserProfile(username string) error
 	// GetEntries returns all published entries for the given user.
 	GetEntries(username string) ([]types.JournalEntry, error)
 	// GetDraft returns an entry draft for the given user for the given date.
 	GetDraft(username string, date string) (types.JournalEntry, error)
+	// GetDrafts returns all entry drafts for the given user.
+	GetDrafts(username string) ([]types.JournalEntry, error)
 	// InsertEntry saves an entry to the datastore, overwriting any existing entry
-	// with the same name and username.
+	// with the same name and username. It also updates the entry's search and
+	// tag indexes.
 	InsertEntry(username string, j types.JournalEntry) error
 	// InsertDraft saves an entry draft to the datastore, overwriting any existing
 	// draft with the same name and username.
 	InsertDraft(username string, j types.JournalEntry) error
+	// DeleteEntries removes all of the given user's published entries, along
+	// with their search and tag indexes, and removes the user from Users.
+	DeleteEntries(username string) error
+	// DeleteDrafts removes all of the given user's entry drafts.
+	DeleteDrafts(username string) error
 	// GetReactions retrieves reader reactions associated with a published entry.
 	GetReactions(entryAuthor string, entryDate string) ([]types.Reaction, error)
+	// GetReactionsByUser retrieves every reaction that the given user has left
+	// on any published entry.
+	GetReactionsByUser(username string) ([]types.EntryReaction, error)
 	// AddReaction saves a reader reaction associated with a published entry,
 	// overwriting any existing reaction.
 	AddReaction(entryAuthor string, entryDate string, reaction types.Reaction) error
+	// DeleteReaction removes the given user's reaction to a published entry.
+	DeleteReaction(entryAuthor string, entryDate string, username string) error
+	// GetComments retrieves reader comments associated with a published entry.
+	GetComments(entryAuthor string, entryDate string) ([]types.Comment, error)
+	// GetCommentsByUser retrieves every comment that the given user has left on
+	// any published entry.
+	GetCommentsByUser(username string) ([]types.EntryComment, error)
+	// SetComment creates or updates a comment on a published entry.
+	SetComment(entryAuthor string, entryDate string, c types.Comment) error
+	// DeleteComment removes a comment from a published entry entirely, rather
+	// than leaving a placeholder for its replies.
+	DeleteComment(entryAuthor string, entryDate string, commentID string) error
 	// InsertPageViews stores the count of pageviews for a given What Got Done route.
 	InsertPageViews(path string, pageViews int) error
 	// GetPageViews retrieves the count of pageviews for a given What Got Done route.
 	GetPageViews(path string) (int, error)
+	// IncrementPageViews atomically adds n to the count of pageviews for a
+	// given What Got Done route, so that concurrent writers don't lose counts.
+	IncrementPageViews(path string, n int) error
+	// InsertDailyPageViews stores the count of pageviews for a given What Got
+	// Done route on a single day, replacing any existing count for that day.
+	InsertDailyPageViews(pv types.DailyPageViews) error
+	// IncrementDailyPageViews atomically adds n to the count of pageviews for a
+	// given What Got Done route on a single day in YYYY-MM-DD format.
+	IncrementDailyPageViews(path string, date string, n int) error
+	// GetDailyPageViews retrieves the daily pageview counts for a given What Got
+	// Done route between two dates in YYYY-MM-DD format, inclusive. Days with no
+	// pageviews are absent from the results.
+	GetDailyPageViews(path string, from string, to string) ([]types.DailyPageViews, error)
+	// DeletePageViews removes the total and daily pageview counts for a given
+	// What Got Done route.
+	DeletePageViews(path string) error
+	// GetPageViewSync returns the progress of copying page views from the
+	// analytics source.
+	GetPageViewSync() (types.PageViewSync, error)
+	// SetPageViewSync records the progress of copying page views from the
+	// analytics source.
+	SetPageViewSync(sync types.PageViewSync) error
+	// GetUserStats returns the most recently cached stats for the given user.
+	GetUserStats(username string) (types.UserStats, error)
+	// SetUserStats caches the given user's stats, replacing any existing stats.
+	SetUserStats(username string, stats types.UserStats) error
+	// DeleteUserStats removes the given user's cached stats.
+	DeleteUserStats(username string) error
+	// GetTrendingEntries returns the most recently cached trending ranking.
+	GetTrendingEntries() (types.TrendingEntries, error)
+	// SetTrendingEntries caches the trending ranking, replacing any existing
+	// ranking.
+	SetTrendingEntries(t types.TrendingEntries) error
+	// IndexEntry updates the search and tag indexes for a published entry.
+	IndexEntry(username string, j types.JournalEntry) error
+	// SearchEntries returns references to all published entries whose search
+	// index contains the given term.
+	SearchEntries(term string) ([]types.EntryRef, error)
+	// GetTaggedEntries returns references to all published entries that contain
+	// the given hashtag.
+	GetTaggedEntries(tag string) ([]types.EntryRef, error)
+	// GetTeam returns the team with the given ID.
+	GetTeam(teamID string) (types.Team, error)
+	// CreateTeam saves a new team to the datastore. It fails with
+	// TeamAlreadyExistsError if a team with the same ID already exists.
+	CreateTeam(team types.Team) error
+	// SetTeam saves a team to the datastore, overwriting any existing team with
+	// the same ID.
+	SetTeam(team types.Team) error
+	// UpdateTeam applies update to the team with the given ID and saves the
+	// result in a single transaction, so that concurrent updates can't
+	// overwrite each other. If update returns an error, UpdateTeam saves
+	// nothing and returns that error. It returns the updated team.
+	UpdateTeam(teamID string, update func(*types.Team) error) (types.Team, error)
+	// DeleteTeam removes the team with the given ID.
+	DeleteTeam(teamID string) error
+	// GetTeamsForUser returns all teams that the given user belongs to or has
+	// been invited to join.
+	GetTeamsForUser(username string) ([]types.Team, error)
+	// GetFollowing returns the usernames of all users that the given user
+	// follows.
+	GetFollowing(username string) ([]string, error)
+	// GetFollowers returns the usernames of all users who follow the given user.
+	GetFollowers(username string) ([]string, error)
+	// Follow records that follower follows the user followee.
+	Follow(follower string, followee string) error
+	// Unfollow removes any record of follower following the user followee.
+	Unfollow(follower string, followee string) error
+	// GetReminderPreferences returns the given user's email reminder settings.
+	GetReminderPreferences(username string) (types.ReminderPreferences, error)
+	// SetReminderPreferences updates the given user's email reminder settings.
+	SetReminderPreferences(username string, p types.ReminderPreferences) error
+	// DeleteReminderPreferences removes the given user's email reminder
+	// settings.
+	DeleteReminderPreferences(username string) error
+	// GetEnabledReminders returns the reminder settings of all users who have
+	// opted in to email reminders.
+	GetEnabledReminders() ([]types.ReminderPreferences, error)
+	// GetDigestSubscription returns the given user's email digest subscription.
+	GetDigestSubscription(username string) (types.DigestSubscription, error)
+	// SetDigestSubscription creates or updates the given user's email digest
+	// subscription.
+	SetDigestSubscription(username string, sub types.DigestSubscription) error
+	// DeleteDigestSubscription unsubscribes the given user from email digests.
+	DeleteDigestSubscription(username string) error
+	// GetDigestSubscriptions returns every user's email digest subscription.
+	GetDigestSubscriptions() ([]types.DigestSubscription, error)
+	// GetNotification returns the notification with the given ID.
+	GetNotification(id string) (types.Notification, error)
+	// SetNotification creates or replaces a notification with the same ID.
+	SetNotification(n types.Notification) error
+	// DeleteNotification removes the notification with the given ID.
+	DeleteNotification(id string) error
+	// GetUnreadNotifications returns the given user's unread notifications.
+	GetUnreadNotifications(recipient string) ([]types.Notification, error)
+	// GetNotifications returns all of the given user's notifications, both read
+	// and unread.
+	GetNotifications(recipient string) ([]types.Notification, error)
+	// GetNotificationsInvolving returns every notification that the given user
+	// receives, that the user's actions generated, or that refers to one of the
+	// user's entries.
+	GetNotificationsInvolving(username string) ([]types.Notification, error)
+	// GetNotificationPreferences returns the given user's notification settings.
+	GetNotificationPreferences(username string) (types.NotificationPreferences, error)
+	// SetNotificationPreferences updates the given user's notification settings.
+	SetNotificationPreferences(username string, p types.NotificationPreferences) error
+	// DeleteNotificationPreferences removes the given user's notification
+	// settings.
+	DeleteNotificationPreferences(username string) error
+	// GetNotificationEmailSubscribers returns the notification settings of all
+	// users who have opted in to notification emails.
+	GetNotificationEmailSubscribers() ([]types.NotificationPreferences, error)
+	// GetWebhooks returns the webhooks that the given user registered.
+	GetWebhooks(owner string) ([]types.Webhook, error)
+	// SetWebhook creates or updates a webhook.
+	SetWebhook(hook types.Webhook) error
+	// DeleteWebhook removes one of the given user's webhooks along with its
+	// delivery log.
+	DeleteWebhook(owner string, webhookID string) error
+	// AddWebhookDelivery records the outcome of a webhook delivery.
+	AddWebhookDelivery(d types.WebhookDelivery) error
+	// GetWebhookDeliveries returns up to limit records from the given webhook's
+	// delivery log, newest first, skipping the first start records.
+	GetWebhookDeliveries(webhookID string, start, limit int) ([]types.WebhookDelivery, error)
+	// PruneWebhookDeliveries deletes all but the keep most recent records from
+	// the given webhook's delivery log.
+	PruneWebhookDeliveries(webhookID string, keep int) error
+	// GetSlackIntegration returns the Slack integration for the given owner.
+	GetSlackIntegration(owner string) (types.SlackIntegration, error)
+	// SetSlackIntegration creates or replaces a Slack integration.
+	SetSlackIntegration(i types.SlackIntegration) error
+	// DeleteSlackIntegration removes the Slack integration for the given owner.
+	DeleteSlackIntegration(owner string) error
+	// AcquireJobLease atomically grants holder the right to run the named job
+	// until the given time. It returns false without changing the lease if any
+	// holder's lease on the job is still active at now.
+	AcquireJobLease(job string, holder string, now time.Time, until time.Time) (bool, error)
+	// GetJobStatuses returns the status of every scheduled job that has run.
+	GetJobStatuses() ([]types.JobStatus, error)
+	// SetJobStatus creates or replaces the status of a scheduled job.
+	SetJobStatus(status types.JobStatus) error
+	// GetAccountDeletions returns all of the given user's requests to delete
+	// their account.
+	GetAccountDeletions(username string) ([]types.AccountDeletion, error)
+	// GetConfirmedAccountDeletions returns every confirmed request to delete an
+	// account whose data has not yet been purged.
+	GetConfirmedAccountDeletions() ([]types.AccountDeletion, error)
+	// SetAccountDeletion creates or replaces a request to delete an account.
+	SetAccountDeletion(d types.AccountDeletion) error
 }
 
 // DraftNotFoundError occurs when no draft exists for a user with a given date.
@@ -61,6 +239,101 @@ func (f UserProfileNotFoundError) Error() string {
 	return fmt.Sprintf("No user profile found for username %s", f.Username)
 }
 
+// TeamNotFoundError occurs when no team exists with the given ID.
+type TeamNotFoundError struct {
+	TeamID string
+}
+
+func (f TeamNotFoundError) Error() string {
+	return fmt.Sprintf("Could not find team with ID %s", f.TeamID)
+}
+
+// TeamAlreadyExistsError occurs when creating a team with an ID that another
+// team already has.
+type TeamAlreadyExistsError struct {
+	TeamID string
+}
+
+func (f TeamAlreadyExistsError) Error() string {
+	return fmt.Sprintf("A team with ID %s already exists", f.TeamID)
+}
+
+// ReminderPreferencesNotFoundError occurs when a user has never saved email
+// reminder settings.
+type ReminderPreferencesNotFoundError struct {
+	Username string
+}
+
+func (f ReminderPreferencesNotFoundError) Error() string {
+	return fmt.Sprintf("No reminder preferences found for username %s", f.Username)
+}
+
+// DigestSubscriptionNotFoundError occurs when a user is not subscribed to email
+// digests.
+type DigestSubscriptionNotFoundError struct {
+	Username string
+}
+
+func (f DigestSubscriptionNotFoundError) Error() string {
+	return fmt.Sprintf("No digest subscription found for username %s", f.Username)
+}
+
+// NotificationNotFoundError occurs when no notification exists with the given
+// ID.
+type NotificationNotFoundError struct {
+	ID string
+}
+
+func (f NotificationNotFoundError) Error() string {
+	return fmt.Sprintf("Could not find notification with ID %s", f.ID)
+}
+
+// NotificationPreferencesNotFoundError occurs when a user has never saved
+// notification settings.
+type NotificationPreferencesNotFoundError struct {
+	Username string
+}
+
+func (f NotificationPreferencesNotFoundError) Error() string {
+	return fmt.Sprintf("No notification preferences found for username %s", f.Username)
+}
+
+// SlackIntegrationNotFoundError occurs when a user or team has not configured
+// a Slack integration.
+type SlackIntegrationNotFoundError struct {
+	Owner string
+}
+
+func (f SlackIntegrationNotFoundError) Error() string {
+	return fmt.Sprintf("No Slack integration found for %s", f.Owner)
+}
+
+// PageViewSyncNotFoundError occurs when the server has never copied page views
+// from an analytics source.
+type PageViewSyncNotFoundError struct{}
+
+func (f PageViewSyncNotFoundError) Error() string {
+	return "No page view sync progress found"
+}
+
+// UserStatsNotFoundError occurs when no stats have been cached for the given
+// user.
+type UserStatsNotFoundError struct {
+	Username string
+}
+
+func (f UserStatsNotFoundError) Error() string {
+	return fmt.Sprintf("No cached stats found for username %s", f.Username)
+}
+
+// TrendingEntriesNotFoundError occurs when the server has never cached a
+// trending ranking.
+type TrendingEntriesNotFoundError struct{}
+
+func (f TrendingEntriesNotFoundError) Error() string {
+	return "No cached trending entries found"
+}
+
 // PageViewsNotFoundError occurs when no page view data is present in the
 // datastore for the given URL path.
 type PageViewsNotFoundError struct {
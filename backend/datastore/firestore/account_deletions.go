@@ -0,0 +1,46 @@
+package firestore
+
+import (
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetAccountDeletions returns all of the given user's requests to delete their
+// account.
+func (c client) GetAccountDeletions(username string) ([]types.AccountDeletion, error) {
+	return c.queryAccountDeletions(c.firestoreClient.Collection(deletionsRootKey).Where("username", "==", username))
+}
+
+// GetConfirmedAccountDeletions returns every confirmed request to delete an
+// account whose data has not yet been purged.
+func (c client) GetConfirmedAccountDeletions() ([]types.AccountDeletion, error) {
+	return c.queryAccountDeletions(c.firestoreClient.Collection(deletionsRootKey).Where("status", "==", string(types.AccountDeletionConfirmed)))
+}
+
+// SetAccountDeletion creates or replaces a request to delete an account.
+func (c client) SetAccountDeletion(d types.AccountDeletion) error {
+	_, err := c.firestoreClient.Collection(deletionsRootKey).Doc(d.ID).Set(c.ctx, d)
+	return err
+}
+
+func (c client) queryAccountDeletions(q firestore.Query) ([]types.AccountDeletion, error) {
+	deletions := []types.AccountDeletion{}
+	iter := q.Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var d types.AccountDeletion
+		if err := doc.DataTo(&d); err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, d)
+	}
+	return deletions, nil
+}
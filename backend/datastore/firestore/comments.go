@@ -0,0 +1,89 @@
+package firestore
+
+import (
+	"strings"
+
+	"google.golang.org/api/iterator"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetComments retrieves reader comments associated with a published entry.
+func (c client) GetComments(entryAuthor string, entryDate string) ([]types.Comment, error) {
+	comments := []types.Comment{}
+	iter := c.firestoreClient.Collection(commentsRootKey).Doc(getEntryReactionsKey(entryAuthor, entryDate)).Collection(perEntryCommentsKey).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var comment types.Comment
+		doc.DataTo(&comment)
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// GetCommentsByUser retrieves every comment that the given user has left on
+// any published entry. The Firestore client library that What Got Done uses
+// predates collection group queries, so this queries each entry that has
+// comments for ones from the user.
+func (c client) GetCommentsByUser(username string) ([]types.EntryComment, error) {
+	comments := []types.EntryComment{}
+	entryIter := c.firestoreClient.Collection(commentsRootKey).DocumentRefs(c.ctx)
+	for {
+		entryDoc, err := entryIter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.SplitN(entryDoc.ID, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		iter := entryDoc.Collection(perEntryCommentsKey).Where("username", "==", username).Documents(c.ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			var comment types.Comment
+			if err := doc.DataTo(&comment); err != nil {
+				return nil, err
+			}
+			comments = append(comments, types.EntryComment{
+				EntryAuthor: parts[0],
+				EntryDate:   parts[1],
+				Comment:     comment,
+			})
+		}
+	}
+	return comments, nil
+}
+
+// SetComment creates or updates a comment on a published entry.
+func (c client) SetComment(entryAuthor string, entryDate string, comment types.Comment) error {
+	// Create a parent document so that its children appear in Firestore console.
+	c.firestoreClient.Collection(commentsRootKey).Doc(getEntryReactionsKey(entryAuthor, entryDate)).Set(c.ctx, entryReactionsDocument{
+		entryAuthor: entryAuthor,
+		entryDate:   entryDate,
+	})
+
+	_, err := c.firestoreClient.Collection(commentsRootKey).Doc(getEntryReactionsKey(entryAuthor, entryDate)).Collection(perEntryCommentsKey).Doc(comment.ID).Set(c.ctx, comment)
+	return err
+}
+
+// DeleteComment removes a comment from a published entry entirely, rather than
+// leaving a placeholder for its replies.
+func (c client) DeleteComment(entryAuthor string, entryDate string, commentID string) error {
+	_, err := c.firestoreClient.Collection(commentsRootKey).Doc(getEntryReactionsKey(entryAuthor, entryDate)).Collection(perEntryCommentsKey).Doc(commentID).Delete(c.ctx)
+	return err
+}
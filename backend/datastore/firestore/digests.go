@@ -0,0 +1,59 @@
+package firestore
+
+import (
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetDigestSubscription returns the given user's email digest subscription.
+func (c client) GetDigestSubscription(username string) (types.DigestSubscription, error) {
+	doc, err := c.firestoreClient.Collection(digestsRootKey).Doc(username).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.DigestSubscription{}, datastore.DigestSubscriptionNotFoundError{Username: username}
+		}
+		return types.DigestSubscription{}, err
+	}
+	var sub types.DigestSubscription
+	if err := doc.DataTo(&sub); err != nil {
+		return types.DigestSubscription{}, err
+	}
+	return sub, nil
+}
+
+// SetDigestSubscription creates or updates the given user's email digest
+// subscription.
+func (c client) SetDigestSubscription(username string, sub types.DigestSubscription) error {
+	sub.Username = username
+	_, err := c.firestoreClient.Collection(digestsRootKey).Doc(username).Set(c.ctx, sub)
+	return err
+}
+
+// DeleteDigestSubscription unsubscribes the given user from email digests.
+func (c client) DeleteDigestSubscription(username string) error {
+	_, err := c.firestoreClient.Collection(digestsRootKey).Doc(username).Delete(c.ctx)
+	return err
+}
+
+// GetDigestSubscriptions returns every user's email digest subscription.
+func (c client) GetDigestSubscriptions() ([]types.DigestSubscription, error) {
+	subs := []types.DigestSubscription{}
+	iter := c.firestoreClient.Collection(digestsRootKey).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var sub types.DigestSubscription
+		doc.DataTo(&sub)
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
@@ -30,6 +30,37 @@ func (c client) GetDraft(username string, date string) (types.JournalEntry, erro
 	}
 }
 
+// GetDrafts returns all entry drafts for the given user.
+func (c client) GetDrafts(username string) ([]types.JournalEntry, error) {
+	drafts := []types.JournalEntry{}
+	iter := c.firestoreClient.Collection(draftsRootKey).Doc(username).Collection(perUserDraftsKey).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var j types.JournalEntry
+		if err := doc.DataTo(&j); err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, j)
+	}
+	return drafts, nil
+}
+
+// DeleteDrafts removes all of the given user's entry drafts.
+func (c client) DeleteDrafts(username string) error {
+	userDoc := c.firestoreClient.Collection(draftsRootKey).Doc(username)
+	if err := c.deleteCollection(userDoc.Collection(perUserDraftsKey)); err != nil {
+		return err
+	}
+	_, err := userDoc.Delete(c.ctx)
+	return err
+}
+
 // InsertDraft saves an entry draft to the datastore, overwriting any existing
 // entry with the same name and username.
 func (c client) InsertDraft(username string, j types.JournalEntry) error {
@@ -31,7 +31,8 @@ func (c client) GetEntries(username string) ([]types.JournalEntry, error) {
 }
 
 // InsertEntry saves an entry to the datastore, overwriting any existing entry
-// with the same name and username.
+// with the same name and username. It also updates the entry's search and tag
+// indexes.
 func (c client) InsertEntry(username string, j types.JournalEntry) error {
 	// Create a User document so that its children appear in Firestore console.
 	c.firestoreClient.Collection(entriesRootKey).Doc(username).Set(c.ctx, userDocument{
@@ -39,5 +40,32 @@ func (c client) InsertEntry(username string, j types.JournalEntry) error {
 		LastModified: j.LastModified,
 	})
 	_, err := c.firestoreClient.Collection(entriesRootKey).Doc(username).Collection(perUserEntriesKey).Doc(j.Date).Set(c.ctx, j)
+	if err != nil {
+		return err
+	}
+	return c.IndexEntry(username, j)
+}
+
+// DeleteEntries removes all of the given user's published entries, along with
+// their search and tag indexes, and removes the user from Users.
+func (c client) DeleteEntries(username string) error {
+	userDoc := c.firestoreClient.Collection(entriesRootKey).Doc(username)
+	iter := userDoc.Collection(perUserEntriesKey).DocumentRefs(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := c.firestoreClient.Collection(searchIndexRootKey).Doc(getSearchIndexKey(username, doc.ID)).Delete(c.ctx); err != nil {
+			return err
+		}
+		if _, err := doc.Delete(c.ctx); err != nil {
+			return err
+		}
+	}
+	_, err := userDoc.Delete(c.ctx)
 	return err
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
 
 	"github.com/mtlynch/whatgotdone/backend/types"
 )
@@ -32,6 +33,26 @@ type (
 		Views int    `firestore:"views"`
 	}
 
+	searchIndexDocument struct {
+		Author string   `firestore:"author,omitempty"`
+		Date   string   `firestore:"date,omitempty"`
+		Terms  []string `firestore:"terms"`
+		Tags   []string `firestore:"tags"`
+	}
+
+	teamDocument struct {
+		Team types.Team `firestore:"team"`
+		// MemberUsernames and InviteeUsernames duplicate information in Team so
+		// that we can query for a user's teams.
+		MemberUsernames  []string `firestore:"memberUsernames"`
+		InviteeUsernames []string `firestore:"inviteeUsernames"`
+	}
+
+	followsDocument struct {
+		Username  string   `firestore:"username,omitempty"`
+		Following []string `firestore:"following"`
+	}
+
 	entryReactionsDocument struct {
 		entryAuthor string `firestore:"entryAuthor,omitempty"`
 		entryDate   string `firestore:"entryDate,omitempty"`
@@ -40,17 +61,56 @@ type (
 
 const (
 	entriesRootKey      = "journalEntries"
+	followsRootKey      = "follows"
 	perUserEntriesKey   = "entries"
 	draftsRootKey       = "journalDrafts"
 	perUserDraftsKey    = "drafts"
 	pageViewsRootKey    = "pageViews"
+	perDayPageViewsKey  = "dailyPageViews"
+	pageViewSyncRootKey = "pageViewSync"
+	pageViewSyncDocKey  = "analytics"
 	reactionsRootKey    = "reactions"
+	commentsRootKey     = "comments"
+	perEntryCommentsKey = "entryComments"
+	remindersRootKey    = "reminderPreferences"
+	digestsRootKey      = "digestSubscriptions"
+	notifyRootKey       = "notifications"
+	notifyPrefsRootKey  = "notificationPreferences"
+	webhooksRootKey     = "webhooks"
+	deliveriesRootKey   = "webhookDeliveries"
+	slackRootKey        = "slackIntegrations"
+	jobLeasesRootKey    = "jobLeases"
+	jobStatusesRootKey  = "jobStatuses"
+	deletionsRootKey    = "accountDeletions"
 	perUserReactionsKey = "perUserReactions"
+	searchIndexRootKey  = "searchIndex"
 	secretsRootKey      = "secrets"
+	teamsRootKey        = "teams"
+	trendingRootKey     = "trending"
+	trendingDocKey      = "ranking"
 	secretUserKitDocKey = "userKitKey"
 	userProfilesRootKey = "userProfiles"
+	userStatsRootKey    = "userStats"
 )
 
+// deleteCollection deletes every document in a collection. It doesn't delete
+// the documents' subcollections.
+func (c client) deleteCollection(col *firestore.CollectionRef) error {
+	iter := col.DocumentRefs(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := doc.Delete(c.ctx); err != nil {
+			return err
+		}
+	}
+}
+
 func getGoogleCloudProjectID() string {
 	projectID := os.Getenv("GOOGLE_CLOUD_PROJECT")
 	if projectID == "" {
@@ -0,0 +1,62 @@
+package firestore
+
+import (
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetFollowing returns the usernames of all users that the given user follows.
+func (c client) GetFollowing(username string) ([]string, error) {
+	doc, err := c.firestoreClient.Collection(followsRootKey).Doc(username).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	var fd followsDocument
+	if err := doc.DataTo(&fd); err != nil {
+		return nil, err
+	}
+	if fd.Following == nil {
+		return []string{}, nil
+	}
+	return fd.Following, nil
+}
+
+// GetFollowers returns the usernames of all users who follow the given user.
+func (c client) GetFollowers(username string) ([]string, error) {
+	followers := []string{}
+	iter := c.firestoreClient.Collection(followsRootKey).Where("following", "array-contains", username).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		followers = append(followers, doc.Ref.ID)
+	}
+	return followers, nil
+}
+
+// Follow records that follower follows the user followee.
+func (c client) Follow(follower string, followee string) error {
+	_, err := c.firestoreClient.Collection(followsRootKey).Doc(follower).Set(c.ctx, map[string]interface{}{
+		"username":  follower,
+		"following": firestore.ArrayUnion(followee),
+	}, firestore.MergeAll)
+	return err
+}
+
+// Unfollow removes any record of follower following the user followee.
+func (c client) Unfollow(follower string, followee string) error {
+	_, err := c.firestoreClient.Collection(followsRootKey).Doc(follower).Set(c.ctx, map[string]interface{}{
+		"username":  follower,
+		"following": firestore.ArrayRemove(followee),
+	}, firestore.MergeAll)
+	return err
+}
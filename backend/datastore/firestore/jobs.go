@@ -0,0 +1,73 @@
+package firestore
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// AcquireJobLease atomically grants holder the right to run the named job
+// until the given time, unless an existing lease is still active.
+func (c client) AcquireJobLease(job string, holder string, now time.Time, until time.Time) (bool, error) {
+	ref := c.firestoreClient.Collection(jobLeasesRootKey).Doc(job)
+	acquired := false
+	err := c.firestoreClient.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		acquired = false
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			var lease types.JobLease
+			if err := doc.DataTo(&lease); err != nil {
+				return err
+			}
+			if lease.Until.After(now) {
+				return nil
+			}
+		}
+		acquired = true
+		return tx.Set(ref, types.JobLease{
+			Job:    job,
+			Holder: holder,
+			Until:  until,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+// GetJobStatuses returns the status of every scheduled job that has run.
+func (c client) GetJobStatuses() ([]types.JobStatus, error) {
+	statuses := []types.JobStatus{}
+	iter := c.firestoreClient.Collection(jobStatusesRootKey).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var s types.JobStatus
+		if err := doc.DataTo(&s); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// SetJobStatus creates or replaces the status of a scheduled job.
+func (c client) SetJobStatus(s types.JobStatus) error {
+	_, err := c.firestoreClient.Collection(jobStatusesRootKey).Doc(s.Name).Set(c.ctx, s)
+	return err
+}
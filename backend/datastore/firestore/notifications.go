@@ -0,0 +1,158 @@
+package firestore
+
+import (
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetNotification returns the notification with the given ID.
+func (c client) GetNotification(id string) (types.Notification, error) {
+	doc, err := c.firestoreClient.Collection(notifyRootKey).Doc(id).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.Notification{}, datastore.NotificationNotFoundError{ID: id}
+		}
+		return types.Notification{}, err
+	}
+	var n types.Notification
+	if err := doc.DataTo(&n); err != nil {
+		return types.Notification{}, err
+	}
+	return n, nil
+}
+
+// SetNotification creates or replaces a notification with the same ID.
+func (c client) SetNotification(n types.Notification) error {
+	_, err := c.firestoreClient.Collection(notifyRootKey).Doc(n.ID).Set(c.ctx, n)
+	return err
+}
+
+// DeleteNotification removes the notification with the given ID.
+func (c client) DeleteNotification(id string) error {
+	_, err := c.firestoreClient.Collection(notifyRootKey).Doc(id).Delete(c.ctx)
+	return err
+}
+
+// GetUnreadNotifications returns the given user's unread notifications.
+func (c client) GetUnreadNotifications(recipient string) ([]types.Notification, error) {
+	notifications := []types.Notification{}
+	iter := c.firestoreClient.Collection(notifyRootKey).
+		Where("recipient", "==", recipient).
+		Where("read", "==", false).
+		Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var n types.Notification
+		doc.DataTo(&n)
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+// GetNotifications returns all of the given user's notifications, both read
+// and unread.
+func (c client) GetNotifications(recipient string) ([]types.Notification, error) {
+	notifications := []types.Notification{}
+	iter := c.firestoreClient.Collection(notifyRootKey).Where("recipient", "==", recipient).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var n types.Notification
+		doc.DataTo(&n)
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+// GetNotificationsInvolving returns every notification that the given user
+// receives, that the user's actions generated, or that refers to one of the
+// user's entries.
+func (c client) GetNotificationsInvolving(username string) ([]types.Notification, error) {
+	notifications := []types.Notification{}
+	seen := map[string]bool{}
+	for _, field := range []string{"recipient", "actor", "entryAuthor"} {
+		iter := c.firestoreClient.Collection(notifyRootKey).Where(field, "==", username).Documents(c.ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if seen[doc.Ref.ID] {
+				continue
+			}
+			seen[doc.Ref.ID] = true
+			var n types.Notification
+			doc.DataTo(&n)
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications, nil
+}
+
+// GetNotificationPreferences returns the given user's notification settings.
+func (c client) GetNotificationPreferences(username string) (types.NotificationPreferences, error) {
+	doc, err := c.firestoreClient.Collection(notifyPrefsRootKey).Doc(username).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.NotificationPreferences{}, datastore.NotificationPreferencesNotFoundError{Username: username}
+		}
+		return types.NotificationPreferences{}, err
+	}
+	var p types.NotificationPreferences
+	if err := doc.DataTo(&p); err != nil {
+		return types.NotificationPreferences{}, err
+	}
+	return p, nil
+}
+
+// SetNotificationPreferences updates the given user's notification settings.
+func (c client) SetNotificationPreferences(username string, p types.NotificationPreferences) error {
+	p.Username = username
+	_, err := c.firestoreClient.Collection(notifyPrefsRootKey).Doc(username).Set(c.ctx, p)
+	return err
+}
+
+// DeleteNotificationPreferences removes the given user's notification
+// settings.
+func (c client) DeleteNotificationPreferences(username string) error {
+	_, err := c.firestoreClient.Collection(notifyPrefsRootKey).Doc(username).Delete(c.ctx)
+	return err
+}
+
+// GetNotificationEmailSubscribers returns the notification settings of all
+// users who have opted in to notification emails.
+func (c client) GetNotificationEmailSubscribers() ([]types.NotificationPreferences, error) {
+	prefs := []types.NotificationPreferences{}
+	iter := c.firestoreClient.Collection(notifyPrefsRootKey).Where("emailEnabled", "==", true).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var p types.NotificationPreferences
+		doc.DataTo(&p)
+		prefs = append(prefs, p)
+	}
+	return prefs, nil
+}
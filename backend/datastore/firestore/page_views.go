@@ -1,11 +1,16 @@
 package firestore
 
 import (
+	"context"
 	"net/url"
 
-	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
 )
 
 func (c client) GetPageViews(path string) (int, error) {
@@ -31,6 +36,109 @@ func (c client) InsertPageViews(path string, pageViews int) error {
 	return err
 }
 
+// IncrementPageViews adds n to the count of pageviews for a route in a
+// Firestore transaction, so that concurrent flushes from different server
+// instances don't overwrite each other's counts.
+func (c client) IncrementPageViews(path string, n int) error {
+	ref := c.firestoreClient.Collection(pageViewsRootKey).Doc(pathToKey(path))
+	return c.firestoreClient.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		pvd := pageViewsDocument{Path: path}
+		doc, err := tx.Get(ref)
+		if err == nil {
+			if err := doc.DataTo(&pvd); err != nil {
+				return err
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		pvd.Views += n
+		return tx.Set(ref, pvd)
+	})
+}
+
+// IncrementDailyPageViews adds n to the count of pageviews for a route on a
+// single day in a Firestore transaction.
+func (c client) IncrementDailyPageViews(path string, date string, n int) error {
+	ref := c.firestoreClient.Collection(pageViewsRootKey).Doc(pathToKey(path)).Collection(perDayPageViewsKey).Doc(date)
+	return c.firestoreClient.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		pv := types.DailyPageViews{Path: path, Date: date}
+		doc, err := tx.Get(ref)
+		if err == nil {
+			if err := doc.DataTo(&pv); err != nil {
+				return err
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		pv.Views += n
+		return tx.Set(ref, pv)
+	})
+}
+
+// InsertDailyPageViews stores the count of pageviews for a route on a single
+// day, replacing any existing count for that day.
+func (c client) InsertDailyPageViews(pv types.DailyPageViews) error {
+	_, err := c.firestoreClient.Collection(pageViewsRootKey).Doc(pathToKey(pv.Path)).Collection(perDayPageViewsKey).Doc(pv.Date).Set(c.ctx, pv)
+	return err
+}
+
+// GetDailyPageViews retrieves the daily pageview counts for a route between two
+// dates, inclusive, ordered by date.
+func (c client) GetDailyPageViews(path string, from string, to string) ([]types.DailyPageViews, error) {
+	daily := []types.DailyPageViews{}
+	iter := c.firestoreClient.Collection(pageViewsRootKey).Doc(pathToKey(path)).Collection(perDayPageViewsKey).
+		Where("date", ">=", from).Where("date", "<=", to).OrderBy("date", firestore.Asc).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var pv types.DailyPageViews
+		if err := doc.DataTo(&pv); err != nil {
+			return nil, err
+		}
+		daily = append(daily, pv)
+	}
+	return daily, nil
+}
+
+// DeletePageViews removes the total and daily pageview counts for a route.
+func (c client) DeletePageViews(path string) error {
+	doc := c.firestoreClient.Collection(pageViewsRootKey).Doc(pathToKey(path))
+	if err := c.deleteCollection(doc.Collection(perDayPageViewsKey)); err != nil {
+		return err
+	}
+	_, err := doc.Delete(c.ctx)
+	return err
+}
+
+// GetPageViewSync returns the progress of copying page views from the
+// analytics source.
+func (c client) GetPageViewSync() (types.PageViewSync, error) {
+	doc, err := c.firestoreClient.Collection(pageViewSyncRootKey).Doc(pageViewSyncDocKey).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.PageViewSync{}, datastore.PageViewSyncNotFoundError{}
+		}
+		return types.PageViewSync{}, err
+	}
+	var sync types.PageViewSync
+	if err := doc.DataTo(&sync); err != nil {
+		return types.PageViewSync{}, err
+	}
+	return sync, nil
+}
+
+// SetPageViewSync records the progress of copying page views from the
+// analytics source.
+func (c client) SetPageViewSync(sync types.PageViewSync) error {
+	_, err := c.firestoreClient.Collection(pageViewSyncRootKey).Doc(pageViewSyncDocKey).Set(c.ctx, sync)
+	return err
+}
+
 func pathToKey(path string) string {
 	return url.PathEscape(path)
 }
@@ -1,7 +1,11 @@
 package firestore
 
 import (
+	"strings"
+
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/mtlynch/whatgotdone/backend/types"
 )
@@ -25,6 +29,44 @@ func (c client) GetReactions(entryAuthor string, entryDate string) ([]types.Reac
 	return reactions, nil
 }
 
+// GetReactionsByUser retrieves every reaction that the given user has left on
+// any published entry. The Firestore client library that What Got Done uses
+// predates collection group queries, so this checks each entry that has
+// reactions for one from the user.
+func (c client) GetReactionsByUser(username string) ([]types.EntryReaction, error) {
+	reactions := []types.EntryReaction{}
+	iter := c.firestoreClient.Collection(reactionsRootKey).DocumentRefs(c.ctx)
+	for {
+		entryDoc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.SplitN(entryDoc.ID, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		doc, err := entryDoc.Collection(perUserReactionsKey).Doc(username).Get(c.ctx)
+		if status.Code(err) == codes.NotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		var r types.Reaction
+		if err := doc.DataTo(&r); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, types.EntryReaction{
+			EntryAuthor: parts[0],
+			EntryDate:   parts[1],
+			Reaction:    r,
+		})
+	}
+	return reactions, nil
+}
+
 // AddReaction saves a reader reaction associated with a published entry,
 // overwriting any existing reaction.
 func (c client) AddReaction(entryAuthor string, entryDate string, reaction types.Reaction) error {
@@ -39,6 +81,12 @@ func (c client) AddReaction(entryAuthor string, entryDate string, reaction types
 	return err
 }
 
+// DeleteReaction removes the given user's reaction to a published entry.
+func (c client) DeleteReaction(entryAuthor string, entryDate string, username string) error {
+	_, err := c.firestoreClient.Collection(reactionsRootKey).Doc(getEntryReactionsKey(entryAuthor, entryDate)).Collection(perUserReactionsKey).Doc(username).Delete(c.ctx)
+	return err
+}
+
 func getEntryReactionsKey(entryAuthor string, entryDate string) string {
 	return entryAuthor + ":" + entryDate
 }
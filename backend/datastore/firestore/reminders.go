@@ -0,0 +1,59 @@
+package firestore
+
+import (
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetReminderPreferences returns the given user's email reminder settings.
+func (c client) GetReminderPreferences(username string) (types.ReminderPreferences, error) {
+	doc, err := c.firestoreClient.Collection(remindersRootKey).Doc(username).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.ReminderPreferences{}, datastore.ReminderPreferencesNotFoundError{Username: username}
+		}
+		return types.ReminderPreferences{}, err
+	}
+	var p types.ReminderPreferences
+	if err := doc.DataTo(&p); err != nil {
+		return types.ReminderPreferences{}, err
+	}
+	return p, nil
+}
+
+// SetReminderPreferences updates the given user's email reminder settings.
+func (c client) SetReminderPreferences(username string, p types.ReminderPreferences) error {
+	p.Username = username
+	_, err := c.firestoreClient.Collection(remindersRootKey).Doc(username).Set(c.ctx, p)
+	return err
+}
+
+// DeleteReminderPreferences removes the given user's email reminder settings.
+func (c client) DeleteReminderPreferences(username string) error {
+	_, err := c.firestoreClient.Collection(remindersRootKey).Doc(username).Delete(c.ctx)
+	return err
+}
+
+// GetEnabledReminders returns the reminder settings of all users who have
+// opted in to email reminders.
+func (c client) GetEnabledReminders() ([]types.ReminderPreferences, error) {
+	prefs := []types.ReminderPreferences{}
+	iter := c.firestoreClient.Collection(remindersRootKey).Where("enabled", "==", true).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var p types.ReminderPreferences
+		doc.DataTo(&p)
+		prefs = append(prefs, p)
+	}
+	return prefs, nil
+}
@@ -0,0 +1,57 @@
+package firestore
+
+import (
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/mtlynch/whatgotdone/backend/search"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// IndexEntry updates the search and tag indexes for a published entry.
+func (c client) IndexEntry(username string, j types.JournalEntry) error {
+	_, err := c.firestoreClient.Collection(searchIndexRootKey).Doc(getSearchIndexKey(username, j.Date)).Set(c.ctx, searchIndexDocument{
+		Author: username,
+		Date:   j.Date,
+		Terms:  search.Tokenize(j.Markdown),
+		Tags:   search.ReadTags(j.Markdown),
+	})
+	return err
+}
+
+// SearchEntries returns references to all published entries whose search
+// index contains the given term.
+func (c client) SearchEntries(term string) ([]types.EntryRef, error) {
+	return c.queryEntryIndex(c.firestoreClient.Collection(searchIndexRootKey).Where("terms", "array-contains", term))
+}
+
+// GetTaggedEntries returns references to all published entries that contain
+// the given hashtag.
+func (c client) GetTaggedEntries(tag string) ([]types.EntryRef, error) {
+	return c.queryEntryIndex(c.firestoreClient.Collection(searchIndexRootKey).Where("tags", "array-contains", tag))
+}
+
+func (c client) queryEntryIndex(q firestore.Query) ([]types.EntryRef, error) {
+	refs := []types.EntryRef{}
+	iter := q.Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var d searchIndexDocument
+		doc.DataTo(&d)
+		refs = append(refs, types.EntryRef{
+			Author: d.Author,
+			Date:   d.Date,
+		})
+	}
+	return refs, nil
+}
+
+func getSearchIndexKey(username string, date string) string {
+	return username + ":" + date
+}
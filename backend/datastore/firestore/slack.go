@@ -0,0 +1,37 @@
+package firestore
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetSlackIntegration returns the Slack integration for the given owner.
+func (c client) GetSlackIntegration(owner string) (types.SlackIntegration, error) {
+	doc, err := c.firestoreClient.Collection(slackRootKey).Doc(owner).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.SlackIntegration{}, datastore.SlackIntegrationNotFoundError{Owner: owner}
+		}
+		return types.SlackIntegration{}, err
+	}
+	var i types.SlackIntegration
+	if err := doc.DataTo(&i); err != nil {
+		return types.SlackIntegration{}, err
+	}
+	return i, nil
+}
+
+// SetSlackIntegration creates or replaces a Slack integration.
+func (c client) SetSlackIntegration(i types.SlackIntegration) error {
+	_, err := c.firestoreClient.Collection(slackRootKey).Doc(i.Owner).Set(c.ctx, i)
+	return err
+}
+
+// DeleteSlackIntegration removes the Slack integration for the given owner.
+func (c client) DeleteSlackIntegration(owner string) error {
+	_, err := c.firestoreClient.Collection(slackRootKey).Doc(owner).Delete(c.ctx)
+	return err
+}
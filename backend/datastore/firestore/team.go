@@ -0,0 +1,133 @@
+package firestore
+
+import (
+	"context"
+	"sort"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetTeam returns the team with the given ID.
+func (c client) GetTeam(teamID string) (types.Team, error) {
+	doc, err := c.firestoreClient.Collection(teamsRootKey).Doc(teamID).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.Team{}, datastore.TeamNotFoundError{TeamID: teamID}
+		}
+		return types.Team{}, err
+	}
+	var td teamDocument
+	if err := doc.DataTo(&td); err != nil {
+		return types.Team{}, err
+	}
+	return td.Team, nil
+}
+
+// CreateTeam saves a new team to the datastore. Firestore rejects the write if
+// the team's document already exists, so concurrent creates of the same team
+// can't overwrite each other.
+func (c client) CreateTeam(team types.Team) error {
+	_, err := c.firestoreClient.Collection(teamsRootKey).Doc(team.ID).Create(c.ctx, newTeamDocument(team))
+	if status.Code(err) == codes.AlreadyExists {
+		return datastore.TeamAlreadyExistsError{TeamID: team.ID}
+	}
+	return err
+}
+
+// SetTeam saves a team to the datastore, overwriting any existing team with
+// the same ID.
+func (c client) SetTeam(team types.Team) error {
+	_, err := c.firestoreClient.Collection(teamsRootKey).Doc(team.ID).Set(c.ctx, newTeamDocument(team))
+	return err
+}
+
+// UpdateTeam applies update to the team with the given ID and saves the result
+// in a Firestore transaction. Firestore retries the transaction if another
+// write changes the team first, so update may run more than once.
+func (c client) UpdateTeam(teamID string, update func(*types.Team) error) (types.Team, error) {
+	ref := c.firestoreClient.Collection(teamsRootKey).Doc(teamID)
+	var team types.Team
+	err := c.firestoreClient.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return datastore.TeamNotFoundError{TeamID: teamID}
+			}
+			return err
+		}
+		var td teamDocument
+		if err := doc.DataTo(&td); err != nil {
+			return err
+		}
+		team = td.Team
+		if err := update(&team); err != nil {
+			return err
+		}
+		return tx.Set(ref, newTeamDocument(team))
+	})
+	if err != nil {
+		return types.Team{}, err
+	}
+	return team, nil
+}
+
+// DeleteTeam removes the team with the given ID.
+func (c client) DeleteTeam(teamID string) error {
+	_, err := c.firestoreClient.Collection(teamsRootKey).Doc(teamID).Delete(c.ctx)
+	return err
+}
+
+func newTeamDocument(team types.Team) teamDocument {
+	memberUsernames := []string{}
+	for _, m := range team.Members {
+		memberUsernames = append(memberUsernames, m.Username)
+	}
+	inviteeUsernames := team.Invitees
+	if inviteeUsernames == nil {
+		inviteeUsernames = []string{}
+	}
+	return teamDocument{
+		Team:             team,
+		MemberUsernames:  memberUsernames,
+		InviteeUsernames: inviteeUsernames,
+	}
+}
+
+// GetTeamsForUser returns all teams that the given user belongs to or has been
+// invited to join.
+func (c client) GetTeamsForUser(username string) ([]types.Team, error) {
+	teamsByID := map[string]types.Team{}
+	queries := []firestore.Query{
+		c.firestoreClient.Collection(teamsRootKey).Where("memberUsernames", "array-contains", username),
+		c.firestoreClient.Collection(teamsRootKey).Where("inviteeUsernames", "array-contains", username),
+	}
+	for _, q := range queries {
+		iter := q.Documents(c.ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			var td teamDocument
+			doc.DataTo(&td)
+			teamsByID[td.Team.ID] = td.Team
+		}
+	}
+	teams := []types.Team{}
+	for _, t := range teamsByID {
+		teams = append(teams, t)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].ID < teams[j].ID
+	})
+	return teams, nil
+}
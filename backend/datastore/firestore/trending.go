@@ -0,0 +1,32 @@
+package firestore
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetTrendingEntries returns the most recently cached trending ranking.
+func (c client) GetTrendingEntries() (types.TrendingEntries, error) {
+	doc, err := c.firestoreClient.Collection(trendingRootKey).Doc(trendingDocKey).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.TrendingEntries{}, datastore.TrendingEntriesNotFoundError{}
+		}
+		return types.TrendingEntries{}, err
+	}
+	var t types.TrendingEntries
+	if err := doc.DataTo(&t); err != nil {
+		return types.TrendingEntries{}, err
+	}
+	return t, nil
+}
+
+// SetTrendingEntries caches the trending ranking, replacing any existing
+// ranking.
+func (c client) SetTrendingEntries(t types.TrendingEntries) error {
+	_, err := c.firestoreClient.Collection(trendingRootKey).Doc(trendingDocKey).Set(c.ctx, t)
+	return err
+}
@@ -1,6 +1,9 @@
 package firestore
 
 import (
+	"sort"
+	"strings"
+
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -27,6 +30,96 @@ func (c client) Users() (users []string, err error) {
 	return users, nil
 }
 
+// AllUsernames returns every user who has data in any collection, including
+// users who have never published an entry, sorted alphabetically.
+func (c client) AllUsernames() ([]string, error) {
+	usernames := map[string]bool{}
+
+	// These collections store each user's data in a document keyed by username.
+	for _, collection := range []string{
+		entriesRootKey,
+		draftsRootKey,
+		userProfilesRootKey,
+		followsRootKey,
+		remindersRootKey,
+		digestsRootKey,
+		notifyPrefsRootKey,
+	} {
+		iter := c.firestoreClient.Collection(collection).DocumentRefs(c.ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			usernames[doc.ID] = true
+		}
+	}
+
+	// Slack integrations are keyed by owner, such as "user:alice".
+	userOwnerPrefix := types.UserSlackOwner("")
+	iter := c.firestoreClient.Collection(slackRootKey).DocumentRefs(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(doc.ID, userOwnerPrefix) {
+			usernames[strings.TrimPrefix(doc.ID, userOwnerPrefix)] = true
+		}
+	}
+
+	// These collections store usernames in the fields of their documents.
+	for _, f := range []struct {
+		collection string
+		field      string
+	}{
+		{notifyRootKey, "recipient"},
+		{webhooksRootKey, "owner"},
+		{teamsRootKey, "memberUsernames"},
+		{teamsRootKey, "inviteeUsernames"},
+	} {
+		iter := c.firestoreClient.Collection(f.collection).Select(f.field).Documents(c.ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			value, err := doc.DataAt(f.field)
+			if err != nil {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				usernames[v] = true
+			case []interface{}:
+				for _, u := range v {
+					if username, ok := u.(string); ok {
+						usernames[username] = true
+					}
+				}
+			}
+		}
+	}
+
+	sorted := []string{}
+	for username := range usernames {
+		if username != "" {
+			sorted = append(sorted, username)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
 // UserProfile returns profile information about the given user.
 func (c client) GetUserProfile(username string) (types.UserProfile, error) {
 	doc := c.firestoreClient.Collection(userProfilesRootKey).Doc(username)
@@ -50,3 +143,9 @@ func (c client) SetUserProfile(username string, p types.UserProfile) error {
 	_, err := c.firestoreClient.Collection(userProfilesRootKey).Doc(username).Set(c.ctx, p)
 	return err
 }
+
+// DeleteUserProfile removes the given user's profile.
+func (c client) DeleteUserProfile(username string) error {
+	_, err := c.firestoreClient.Collection(userProfilesRootKey).Doc(username).Delete(c.ctx)
+	return err
+}
@@ -0,0 +1,38 @@
+package firestore
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetUserStats returns the most recently cached stats for the given user.
+func (c client) GetUserStats(username string) (types.UserStats, error) {
+	doc, err := c.firestoreClient.Collection(userStatsRootKey).Doc(username).Get(c.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return types.UserStats{}, datastore.UserStatsNotFoundError{Username: username}
+		}
+		return types.UserStats{}, err
+	}
+	var stats types.UserStats
+	if err := doc.DataTo(&stats); err != nil {
+		return types.UserStats{}, err
+	}
+	return stats, nil
+}
+
+// SetUserStats caches the given user's stats, replacing any existing stats.
+func (c client) SetUserStats(username string, stats types.UserStats) error {
+	stats.Username = username
+	_, err := c.firestoreClient.Collection(userStatsRootKey).Doc(username).Set(c.ctx, stats)
+	return err
+}
+
+// DeleteUserStats removes the given user's cached stats.
+func (c client) DeleteUserStats(username string) error {
+	_, err := c.firestoreClient.Collection(userStatsRootKey).Doc(username).Delete(c.ctx)
+	return err
+}
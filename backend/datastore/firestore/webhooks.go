@@ -0,0 +1,114 @@
+package firestore
+
+import (
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// GetWebhooks returns the webhooks that the given user registered.
+func (c client) GetWebhooks(owner string) ([]types.Webhook, error) {
+	hooks := []types.Webhook{}
+	iter := c.firestoreClient.Collection(webhooksRootKey).Where("owner", "==", owner).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var hook types.Webhook
+		doc.DataTo(&hook)
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// SetWebhook creates or updates a webhook.
+func (c client) SetWebhook(hook types.Webhook) error {
+	_, err := c.firestoreClient.Collection(webhooksRootKey).Doc(hook.ID).Set(c.ctx, hook)
+	return err
+}
+
+// DeleteWebhook removes one of the given user's webhooks along with its
+// delivery log. It does nothing if the webhook belongs to a different user.
+func (c client) DeleteWebhook(owner string, webhookID string) error {
+	hooks, err := c.GetWebhooks(owner)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if hook.ID != webhookID {
+			continue
+		}
+		// Delete the deliveries first so that a failure leaves the webhook in
+		// place to retry from.
+		iter := c.firestoreClient.Collection(deliveriesRootKey).Where("webhookId", "==", webhookID).Documents(c.ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if _, err := doc.Ref.Delete(c.ctx); err != nil {
+				return err
+			}
+		}
+		_, err := c.firestoreClient.Collection(webhooksRootKey).Doc(webhookID).Delete(c.ctx)
+		return err
+	}
+	return nil
+}
+
+// AddWebhookDelivery records the outcome of a webhook delivery.
+func (c client) AddWebhookDelivery(d types.WebhookDelivery) error {
+	_, err := c.firestoreClient.Collection(deliveriesRootKey).Doc(d.ID).Set(c.ctx, d)
+	return err
+}
+
+// GetWebhookDeliveries returns up to limit records from the given webhook's
+// delivery log, newest first, skipping the first start records.
+func (c client) GetWebhookDeliveries(webhookID string, start, limit int) ([]types.WebhookDelivery, error) {
+	deliveries := []types.WebhookDelivery{}
+	iter := c.deliveriesNewestFirst(webhookID).Offset(start).Limit(limit).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var d types.WebhookDelivery
+		doc.DataTo(&d)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+// PruneWebhookDeliveries deletes all but the keep most recent records from
+// the given webhook's delivery log.
+func (c client) PruneWebhookDeliveries(webhookID string, keep int) error {
+	iter := c.deliveriesNewestFirst(webhookID).Offset(keep).Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := doc.Ref.Delete(c.ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c client) deliveriesNewestFirst(webhookID string) firestore.Query {
+	return c.firestoreClient.Collection(deliveriesRootKey).Where("webhookId", "==", webhookID).OrderBy("timestamp", firestore.Desc)
+}
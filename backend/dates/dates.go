@@ -0,0 +1,46 @@
+// Package dates computes the weekly dates that What Got Done uses to organize
+// entries.
+package dates
+
+import "time"
+
+// whatGotDoneEpochYear is the year of the earliest valid entry date.
+const whatGotDoneEpochYear = 2019
+
+// IsEntryDate returns true if the given date is valid for a journal entry. To
+// be valid, the date must be:
+//
+//   - In YYYY-MM-DD format
+//   - A Friday
+//   - After 2019-01-01
+//   - No later than the nearest following Friday
+func IsEntryDate(date string) bool {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false
+	}
+	if t.Year() < whatGotDoneEpochYear {
+		return false
+	}
+	if t.Weekday() != time.Friday {
+		return false
+	}
+	if t.After(fridayOnOrAfter(time.Now())) {
+		return false
+	}
+	return true
+}
+
+// WeekEnding returns the entry date (in YYYY-MM-DD format) for the week that
+// contains t. Entries are dated for the Friday that ends their week, so this
+// is the nearest Friday on or after t.
+func WeekEnding(t time.Time) string {
+	return fridayOnOrAfter(t).Format("2006-01-02")
+}
+
+func fridayOnOrAfter(t time.Time) time.Time {
+	for t.Weekday() != time.Friday {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
@@ -0,0 +1,78 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsEntryDate(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		date          string
+		validExpected bool
+	}{
+		{
+			"standard date in 2019 is valid",
+			"2019-10-18",
+			true,
+		},
+		{
+			"non-Friday date is invalid",
+			"2019-10-19",
+			false,
+		},
+		{
+			"future date is invalid",
+			"2039-03-13",
+			false,
+		},
+		{
+			"malformed date is invalid",
+			"2019-10-1",
+			false,
+		},
+		{
+			"empty string is invalid",
+			"",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		validActual := IsEntryDate(tt.date)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.date, validActual, tt.validExpected)
+		}
+	}
+}
+
+func TestWeekEnding(t *testing.T) {
+	var tests = []struct {
+		explanation  string
+		t            time.Time
+		dateExpected string
+	}{
+		{
+			"Friday is the end of its own week",
+			time.Date(2019, 10, 18, 17, 30, 0, 0, time.UTC),
+			"2019-10-18",
+		},
+		{
+			"Monday belongs to the following Friday",
+			time.Date(2019, 10, 14, 9, 0, 0, 0, time.UTC),
+			"2019-10-18",
+		},
+		{
+			"Saturday belongs to the following week",
+			time.Date(2019, 10, 19, 9, 0, 0, 0, time.UTC),
+			"2019-10-25",
+		},
+	}
+
+	for _, tt := range tests {
+		dateActual := WeekEnding(tt.t)
+		if dateActual != tt.dateExpected {
+			t.Errorf("%s: input [%v], got %v, want %v", tt.explanation, tt.t, dateActual, tt.dateExpected)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package digest emails users a summary of recent entries from the authors
+// they subscribe to.
+package digest
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/site"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// EntryFilter returns the subset of an author's entries that the given viewer
+// is allowed to read.
+type EntryFilter func(viewer string, author string, entries []types.JournalEntry) ([]types.JournalEntry, error)
+
+// Sender sends email digests to subscribed users.
+type Sender struct {
+	datastore datastore.Datastore
+	mailer    mail.Mailer
+	signer    Signer
+	filter    EntryFilter
+}
+
+type digestEntry struct {
+	author string
+	entry  types.JournalEntry
+}
+
+// New creates a Sender that emails digests through the given mailer. The
+// digest only includes entries that pass filter for the subscriber.
+func New(ds datastore.Datastore, m mail.Mailer, signer Signer, filter EntryFilter) Sender {
+	return Sender{
+		datastore: ds,
+		mailer:    m,
+		signer:    signer,
+		filter:    filter,
+	}
+}
+
+// SendDue emails a digest to every subscriber whose digest period has elapsed
+// since their last digest.
+func (s Sender) SendDue(now time.Time) error {
+	subs, err := s.datastore.GetDigestSubscriptions()
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := s.sendIfDue(sub, now); err != nil {
+			log.Printf("Failed to send digest to %s: %s", sub.Username, err)
+		}
+	}
+	return nil
+}
+
+// Period returns the length of time that a digest of the given frequency
+// covers.
+func Period(f types.DigestFrequency) time.Duration {
+	if f == types.DigestDaily {
+		return 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+func (s Sender) sendIfDue(sub types.DigestSubscription, now time.Time) error {
+	since := now.Add(-Period(sub.Frequency))
+	if sub.LastSentAt != "" {
+		lastSent, err := time.Parse(time.RFC3339, sub.LastSentAt)
+		if err != nil {
+			return err
+		}
+		if now.Sub(lastSent) < Period(sub.Frequency) {
+			return nil
+		}
+		since = lastSent
+	}
+
+	profile, err := s.datastore.GetUserProfile(sub.Username)
+	if _, ok := err.(datastore.UserProfileNotFoundError); ok {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if profile.EmailAddress == "" {
+		return nil
+	}
+
+	entries, err := s.collectEntries(sub, since, now)
+	if err != nil {
+		return err
+	}
+	// Skip empty digests, but still advance the time window so that the next
+	// digest doesn't repeat this period.
+	if len(entries) > 0 {
+		subject := fmt.Sprintf("Your %s What Got Done digest", sub.Frequency)
+		// The List-Unsubscribe headers let email clients offer one-click
+		// unsubscribes (RFC 8058), which POST to the unsubscribe URL.
+		if err := s.mailer.Send(profile.EmailAddress, subject, s.render(sub.Username, entries),
+			mail.Header{Name: "List-Unsubscribe", Value: "<" + s.signer.UnsubscribeURL(sub.Username) + ">"},
+			mail.Header{Name: "List-Unsubscribe-Post", Value: "List-Unsubscribe=One-Click"}); err != nil {
+			return err
+		}
+		log.Printf("Sent %s digest with %d entries to %s", sub.Frequency, len(entries), sub.Username)
+	}
+
+	sub.LastSentAt = now.Format(time.RFC3339)
+	return s.datastore.SetDigestSubscription(sub.Username, sub)
+}
+
+func (s Sender) collectEntries(sub types.DigestSubscription, since time.Time, until time.Time) ([]digestEntry, error) {
+	authors := sub.Authors
+	if len(authors) == 0 {
+		var err error
+		authors, err = s.datastore.GetFollowing(sub.Username)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := []digestEntry{}
+	for _, author := range authors {
+		entries, err := s.datastore.GetEntries(author)
+		if err != nil {
+			return nil, err
+		}
+		entries, err = s.filter(sub.Username, author, entries)
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range entries {
+			modified, err := time.Parse(time.RFC3339, j.LastModified)
+			if err != nil {
+				continue
+			}
+			if modified.After(since) && !modified.After(until) {
+				results = append(results, digestEntry{author: author, entry: j})
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].author != results[j].author {
+			return results[i].author < results[j].author
+		}
+		return results[i].entry.Date > results[j].entry.Date
+	})
+	return results, nil
+}
+
+func (s Sender) render(username string, entries []digestEntry) string {
+	var b strings.Builder
+	b.WriteString("Here's what the people you follow got done:\n\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s's update for the week ending %s\n", e.author, e.entry.Date)
+		fmt.Fprintf(&b, "%s\n\n", site.EntryURL(e.author, e.entry.Date))
+		b.WriteString(strings.TrimSpace(e.entry.Markdown))
+		b.WriteString("\n\n---\n\n")
+	}
+	b.WriteString("To manage which authors appear in your digest, visit your profile settings. ")
+	fmt.Fprintf(&b, "To unsubscribe from these emails, click here:\n\n%s\n", s.signer.UnsubscribeURL(username))
+	return b.String()
+}
@@ -0,0 +1,199 @@
+package digest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that the digest sender calls.
+	datastore.Datastore
+	subscriptions  map[string]types.DigestSubscription
+	journalEntries map[string][]types.JournalEntry
+	following      map[string][]string
+	userProfiles   map[string]types.UserProfile
+}
+
+func (ds mockDatastore) GetDigestSubscriptions() ([]types.DigestSubscription, error) {
+	subs := []types.DigestSubscription{}
+	for _, sub := range ds.subscriptions {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (ds mockDatastore) SetDigestSubscription(username string, sub types.DigestSubscription) error {
+	ds.subscriptions[username] = sub
+	return nil
+}
+
+func (ds mockDatastore) GetEntries(username string) ([]types.JournalEntry, error) {
+	return ds.journalEntries[username], nil
+}
+
+func (ds mockDatastore) GetFollowing(username string) ([]string, error) {
+	return ds.following[username], nil
+}
+
+func (ds mockDatastore) GetUserProfile(username string) (types.UserProfile, error) {
+	p, ok := ds.userProfiles[username]
+	if !ok {
+		return types.UserProfile{}, datastore.UserProfileNotFoundError{Username: username}
+	}
+	return p, nil
+}
+
+type sentMessage struct {
+	to      string
+	body    string
+	headers []mail.Header
+}
+
+type mockMailer struct {
+	sent []sentMessage
+}
+
+func (m *mockMailer) Send(to string, subject string, body string, headers ...mail.Header) error {
+	m.sent = append(m.sent, sentMessage{to: to, body: body, headers: headers})
+	return nil
+}
+
+// hidePrivateEntries mimics the server's visibility rules closely enough to
+// verify that the sender applies its filter.
+func hidePrivateEntries(viewer string, author string, entries []types.JournalEntry) ([]types.JournalEntry, error) {
+	visible := []types.JournalEntry{}
+	for _, j := range entries {
+		if j.Visibility != types.VisibilityPrivate {
+			visible = append(visible, j)
+		}
+	}
+	return visible, nil
+}
+
+func TestSendDue(t *testing.T) {
+	now := time.Date(2019, time.November, 30, 12, 0, 0, 0, time.UTC)
+	entries := map[string][]types.JournalEntry{
+		"alice": []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T20:00:00Z", Markdown: "Shipped billing"},
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-25T20:00:00Z", Markdown: "Designed billing"},
+		},
+		"carol": []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T21:00:00Z", Markdown: "Hired a designer"},
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T22:00:00Z", Markdown: "Secret plans", Visibility: types.VisibilityPrivate},
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-28T18:00:00Z", Markdown: "Fixed a typo"},
+		},
+	}
+	var tests = []struct {
+		explanation        string
+		sub                types.DigestSubscription
+		bodyContains       []string
+		bodyExcludes       []string
+		sentExpected       bool
+		lastSentAtExpected string
+	}{
+		{
+			"daily digest includes the last day's entries from selected authors",
+			types.DigestSubscription{Username: "bob", Frequency: types.DigestDaily, Authors: []string{"alice"}},
+			[]string{"Shipped billing", "https://whatgotdone.com/alice/2019-11-29", "/api/digest/unsubscribe?token="},
+			[]string{"Designed billing", "Hired a designer", "Fixed a typo"},
+			true,
+			"2019-11-30T12:00:00Z",
+		},
+		{
+			"weekly digest covers the last week",
+			types.DigestSubscription{Username: "bob", Frequency: types.DigestWeekly, Authors: []string{"alice"}},
+			[]string{"Shipped billing", "Designed billing"},
+			[]string{},
+			true,
+			"2019-11-30T12:00:00Z",
+		},
+		{
+			"digest without selected authors uses followed users and hides private entries",
+			types.DigestSubscription{Username: "bob", Frequency: types.DigestDaily},
+			[]string{"Shipped billing", "Hired a designer"},
+			[]string{"Secret plans"},
+			true,
+			"2019-11-30T12:00:00Z",
+		},
+		{
+			"digest starts where the last one left off",
+			types.DigestSubscription{Username: "bob", Frequency: types.DigestDaily, Authors: []string{"alice", "carol"}, LastSentAt: "2019-11-28T12:00:00Z"},
+			[]string{"Shipped billing", "Hired a designer", "Fixed a typo"},
+			[]string{"Designed billing"},
+			true,
+			"2019-11-30T12:00:00Z",
+		},
+		{
+			"does not send before the period elapses",
+			types.DigestSubscription{Username: "bob", Frequency: types.DigestDaily, Authors: []string{"alice"}, LastSentAt: "2019-11-30T00:00:00Z"},
+			nil,
+			nil,
+			false,
+			"2019-11-30T00:00:00Z",
+		},
+		{
+			"skips empty digests but advances the window",
+			types.DigestSubscription{Username: "bob", Frequency: types.DigestDaily, Authors: []string{"dave"}},
+			nil,
+			nil,
+			false,
+			"2019-11-30T12:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		ds := mockDatastore{
+			subscriptions: map[string]types.DigestSubscription{
+				"bob": tt.sub,
+			},
+			journalEntries: entries,
+			following: map[string][]string{
+				"bob": []string{"alice", "carol"},
+			},
+			userProfiles: map[string]types.UserProfile{
+				"bob": types.UserProfile{EmailAddress: "bob@example.com"},
+			},
+		}
+		m := mockMailer{}
+		s := New(ds, &m, NewSigner("dummy-secret"), hidePrivateEntries)
+
+		if err := s.SendDue(now); err != nil {
+			t.Fatalf("%s: SendDue failed: %v", tt.explanation, err)
+		}
+		if (len(m.sent) > 0) != tt.sentExpected {
+			t.Fatalf("%s: unexpected number of messages: got %d", tt.explanation, len(m.sent))
+		}
+		if lastSentAt := ds.subscriptions["bob"].LastSentAt; lastSentAt != tt.lastSentAtExpected {
+			t.Errorf("%s: unexpected lastSentAt: got %v want %v", tt.explanation, lastSentAt, tt.lastSentAtExpected)
+		}
+		if !tt.sentExpected {
+			continue
+		}
+		if !reflect.DeepEqual(m.sent[0].to, "bob@example.com") {
+			t.Errorf("%s: unexpected recipient: %v", tt.explanation, m.sent[0].to)
+		}
+		headersExpected := []mail.Header{
+			{Name: "List-Unsubscribe", Value: "<" + NewSigner("dummy-secret").UnsubscribeURL("bob") + ">"},
+			{Name: "List-Unsubscribe-Post", Value: "List-Unsubscribe=One-Click"},
+		}
+		if !reflect.DeepEqual(m.sent[0].headers, headersExpected) {
+			t.Errorf("%s: unexpected headers: got %v want %v", tt.explanation, m.sent[0].headers, headersExpected)
+		}
+		for _, s := range tt.bodyContains {
+			if !strings.Contains(m.sent[0].body, s) {
+				t.Errorf("%s: expected digest to contain %q, got: %s", tt.explanation, s, m.sent[0].body)
+			}
+		}
+		for _, s := range tt.bodyExcludes {
+			if strings.Contains(m.sent[0].body, s) {
+				t.Errorf("%s: expected digest not to contain %q, got: %s", tt.explanation, s, m.sent[0].body)
+			}
+		}
+	}
+}
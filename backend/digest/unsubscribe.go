@@ -0,0 +1,51 @@
+package digest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+
+	"github.com/mtlynch/whatgotdone/backend/site"
+)
+
+// Signer creates and verifies tokens that authorize one-click unsubscribe
+// links without requiring the user to log in.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a Signer that signs tokens with the given server secret. A
+// Signer with an empty secret rejects every token.
+func NewSigner(secret string) Signer {
+	return Signer{secret: []byte(secret)}
+}
+
+// Sign returns the unsubscribe token for the given user.
+func (s Signer) Sign(username string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	// Prefix the message with its purpose so that tokens signed with the same
+	// secret for other purposes aren't valid unsubscribe tokens.
+	mac.Write([]byte("digest-unsubscribe:" + username))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if token is a valid unsubscribe token for the given
+// user.
+func (s Signer) Verify(username string, token string) bool {
+	if len(s.secret) == 0 {
+		return false
+	}
+	return hmac.Equal([]byte(s.Sign(username)), []byte(token))
+}
+
+// UnsubscribeURL returns the link that unsubscribes the given user from email
+// digests. Opening the link shows a confirmation page, and sending a POST
+// request to it unsubscribes the user.
+func (s Signer) UnsubscribeURL(username string) string {
+	v := url.Values{}
+	v.Set("username", username)
+	v.Set("token", s.Sign(username))
+	return fmt.Sprintf("%s/api/digest/unsubscribe?%s", site.URL, v.Encode())
+}
@@ -0,0 +1,53 @@
+package digest
+
+import (
+	"testing"
+)
+
+func TestSignerVerify(t *testing.T) {
+	signer := NewSigner("dummy-secret")
+	var tests = []struct {
+		explanation   string
+		username      string
+		token         string
+		validExpected bool
+	}{
+		{
+			"token for the same user is valid",
+			"bob",
+			signer.Sign("bob"),
+			true,
+		},
+		{
+			"token for a different user is invalid",
+			"bob",
+			signer.Sign("alice"),
+			false,
+		},
+		{
+			"token signed with a different secret is invalid",
+			"bob",
+			NewSigner("other-secret").Sign("bob"),
+			false,
+		},
+		{
+			"empty token is invalid",
+			"bob",
+			"",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		validActual := signer.Verify(tt.username, tt.token)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: got %v, want %v", tt.explanation, validActual, tt.validExpected)
+		}
+	}
+}
+
+func TestSignerWithoutSecretRejectsTokens(t *testing.T) {
+	signer := NewSigner("")
+	if signer.Verify("bob", signer.Sign("bob")) {
+		t.Errorf("expected a signer without a secret to reject every token")
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/site"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// accountDeletionConfirmationTTL is how long a user has to confirm a request
+// to delete their account.
+const accountDeletionConfirmationTTL = time.Hour
+
+// userMeDelete deletes the logged-in user's account in two steps. A request
+// without a confirm parameter starts a deletion and emails a confirmation token
+// to the address in the user's profile. Repeating the request with confirm set
+// to that token confirms the deletion, and a background job then purges the
+// user's data. Confirming the same deletion again reports its progress.
+func (s defaultServer) userMeDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must be logged in to delete your account", http.StatusForbidden)
+			return
+		}
+
+		deletions, err := s.datastore.GetAccountDeletions(username)
+		if err != nil {
+			log.Printf("Failed to retrieve account deletions for %s: %s", username, err)
+			http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now().UTC()
+		confirm := r.URL.Query().Get("confirm")
+		if confirm == "" {
+			s.startAccountDeletion(w, username, deletions, now)
+			return
+		}
+
+		d, ok := findAccountDeletionByToken(deletions, confirm)
+		if !ok {
+			http.Error(w, "Invalid confirmation token", http.StatusBadRequest)
+			return
+		}
+		if d.Status == types.AccountDeletionPending {
+			expires, err := time.Parse(time.RFC3339, d.ConfirmationExpiresAt)
+			if err != nil || now.After(expires) {
+				http.Error(w, "Confirmation token has expired, please request account deletion again", http.StatusBadRequest)
+				return
+			}
+			d.Status = types.AccountDeletionConfirmed
+			d.ConfirmedAt = now.Format(time.RFC3339)
+			if err := s.datastore.SetAccountDeletion(d); err != nil {
+				log.Printf("Failed to confirm account deletion for %s: %s", username, err)
+				http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+				return
+			}
+			log.Printf("User %s confirmed deletion of their account", username)
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		if err := json.NewEncoder(w).Encode(d); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// startAccountDeletion creates a deletion request awaiting confirmation, or
+// renews the user's pending request with a new token, and emails the token to
+// the user. Sending the token out of band means that a stolen session alone
+// can't delete an account. If the user already confirmed a deletion that
+// hasn't finished, it reports that deletion instead.
+func (s defaultServer) startAccountDeletion(w http.ResponseWriter, username string, deletions []types.AccountDeletion, now time.Time) {
+	d := types.AccountDeletion{}
+	for _, existing := range deletions {
+		if existing.Status == types.AccountDeletionConfirmed {
+			w.WriteHeader(http.StatusAccepted)
+			if err := json.NewEncoder(w).Encode(existing); err != nil {
+				panic(err)
+			}
+			return
+		}
+		if existing.Status == types.AccountDeletionPending {
+			d = existing
+		}
+	}
+
+	if s.mailer == nil {
+		http.Error(w, "Account deletion is unavailable because the server can't send email", http.StatusServiceUnavailable)
+		return
+	}
+	profile, err := s.datastore.GetUserProfile(username)
+	if _, ok := err.(datastore.UserProfileNotFoundError); ok || (err == nil && profile.EmailAddress == "") {
+		http.Error(w, "Add an email address to your profile to delete your account", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Printf("Failed to retrieve profile for %s: %s", username, err)
+		http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		log.Printf("Failed to generate account deletion token: %s", err)
+		http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+	if d.ID == "" {
+		d.ID, err = randomHex(16)
+		if err != nil {
+			log.Printf("Failed to generate account deletion ID: %s", err)
+			http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+			return
+		}
+		d.Username = username
+	}
+	d.Status = types.AccountDeletionPending
+	d.ConfirmationToken = token
+	d.RequestedAt = now.Format(time.RFC3339)
+	d.ConfirmationExpiresAt = now.Add(accountDeletionConfirmationTTL).Format(time.RFC3339)
+	if err := s.datastore.SetAccountDeletion(d); err != nil {
+		log.Printf("Failed to save account deletion request for %s: %s", username, err)
+		http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.mailer.Send(profile.EmailAddress, "Confirm your What Got Done account deletion", accountDeletionEmailBody(token)); err != nil {
+		log.Printf("Failed to email account deletion token to %s: %s", username, err)
+		http.Error(w, "Failed to send confirmation email", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(d); err != nil {
+		panic(err)
+	}
+}
+
+func accountDeletionEmailBody(token string) string {
+	return fmt.Sprintf(`Hi there,
+
+Someone asked to delete your What Got Done account and all of its data. If that was you, confirm the deletion within the next hour with this confirmation token:
+
+%s
+
+To confirm, send a DELETE request to %s/api/user/me?confirm=<token> while logged in.
+
+If you didn't ask to delete your account, ignore this email and your account will stay as it is.
+`, token, site.URL)
+}
+
+// blockWritesFromDeletedAccounts rejects requests that change data on behalf of
+// users who confirmed that they want to delete their accounts, so that nothing
+// new appears during or after the purge. Users can still check on the
+// deletion's progress.
+func (s defaultServer) blockWritesFromDeletedAccounts(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isWriteRequest(r) || (r.URL.Path == "/api/user/me" && r.Method == http.MethodDelete) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		deletions, err := s.datastore.GetAccountDeletions(username)
+		if err != nil {
+			log.Printf("Failed to retrieve account deletions for %s: %s", username, err)
+			http.Error(w, "Failed to check account status", http.StatusInternalServerError)
+			return
+		}
+		for _, d := range deletions {
+			if d.Status == types.AccountDeletionConfirmed || d.Status == types.AccountDeletionCompleted {
+				http.Error(w, "This account has been deleted", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func isWriteRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func findAccountDeletionByToken(deletions []types.AccountDeletion, token string) (types.AccountDeletion, bool) {
+	for _, d := range deletions {
+		if d.ConfirmationToken != "" && subtle.ConstantTimeCompare([]byte(d.ConfirmationToken), []byte(token)) == 1 {
+			return d, true
+		}
+	}
+	return types.AccountDeletion{}, false
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetAccountDeletions(username string) ([]types.AccountDeletion, error) {
+	deletions := []types.AccountDeletion{}
+	for _, d := range ds.deletions {
+		if d.Username == username {
+			deletions = append(deletions, d)
+		}
+	}
+	return deletions, nil
+}
+
+func (ds mockDatastore) GetConfirmedAccountDeletions() ([]types.AccountDeletion, error) {
+	deletions := []types.AccountDeletion{}
+	for _, d := range ds.deletions {
+		if d.Status == types.AccountDeletionConfirmed {
+			deletions = append(deletions, d)
+		}
+	}
+	return deletions, nil
+}
+
+func (ds *mockDatastore) SetAccountDeletion(d types.AccountDeletion) error {
+	if ds.deletions == nil {
+		ds.deletions = map[string]types.AccountDeletion{}
+	}
+	ds.deletions[d.ID] = d
+	return nil
+}
+
+type mockMailer struct {
+	recipients []string
+	bodies     []string
+}
+
+func (m *mockMailer) Send(to string, subject string, body string, headers ...mail.Header) error {
+	m.recipients = append(m.recipients, to)
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+var emailedTokenPattern = regexp.MustCompile(`(?m)^([0-9a-f]{64})$`)
+
+// lastEmailedToken returns the confirmation token in the most recent email.
+func (m *mockMailer) lastEmailedToken(t *testing.T) string {
+	if len(m.bodies) == 0 {
+		t.Fatalf("no confirmation email was sent")
+	}
+	match := emailedTokenPattern.FindStringSubmatch(m.bodies[len(m.bodies)-1])
+	if match == nil {
+		t.Fatalf("email has no confirmation token: %s", m.bodies[len(m.bodies)-1])
+	}
+	return match[1]
+}
+
+func newAccountDeletionTestServer(ds *mockDatastore, m mail.Mailer) defaultServer {
+	s := newTeamsTestServer(ds)
+	s.mailer = m
+	s.router = mux.NewRouter()
+	s.routes()
+	return s
+}
+
+type accountDeletionResponseForTest struct {
+	ID                string `json:"id"`
+	Status            string `json:"status"`
+	ConfirmationToken string `json:"confirmationToken"`
+}
+
+func decodeAccountDeletion(t *testing.T, body []byte) accountDeletionResponseForTest {
+	var resp accountDeletionResponseForTest
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %s", string(body))
+	}
+	return resp
+}
+
+func TestUserMeDelete(t *testing.T) {
+	ds := mockDatastore{
+		userProfile: types.UserProfile{EmailAddress: "alice@example.com"},
+	}
+	m := mockMailer{}
+	s := newAccountDeletionTestServer(&ds, &m)
+
+	w := sendTeamsRequest(s, http.MethodDelete, "/api/user/me", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("request: status=%d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	requested := decodeAccountDeletion(t, w.Body.Bytes())
+	if requested.Status != string(types.AccountDeletionPending) || requested.ConfirmationToken != "" {
+		t.Fatalf("unexpected deletion request: %+v", requested)
+	}
+	if len(ds.deletions) != 1 || ds.deletions[requested.ID].Username != "alice" {
+		t.Fatalf("expected a pending deletion for alice, got %+v", ds.deletions)
+	}
+	if len(m.recipients) != 1 || m.recipients[0] != "alice@example.com" {
+		t.Fatalf("expected a confirmation email to alice, got %v", m.recipients)
+	}
+	requested.ConfirmationToken = m.lastEmailedToken(t)
+
+	w = sendTeamsRequest(s, http.MethodDelete, "/api/user/me?confirm=wrong-token", "mock_token_A", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong token: status=%d, want %d", w.Code, http.StatusBadRequest)
+	}
+	w = sendTeamsRequest(s, http.MethodDelete, "/api/user/me?confirm="+requested.ConfirmationToken, "mock_token_B", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("another user's token: status=%d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	for i := 0; i < 2; i++ {
+		w = sendTeamsRequest(s, http.MethodDelete, "/api/user/me?confirm="+requested.ConfirmationToken, "mock_token_A", "")
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("confirm %d: status=%d, want %d: %s", i, w.Code, http.StatusAccepted, w.Body.String())
+		}
+		confirmed := decodeAccountDeletion(t, w.Body.Bytes())
+		if confirmed.ID != requested.ID || confirmed.Status != string(types.AccountDeletionConfirmed) || confirmed.ConfirmationToken != "" {
+			t.Errorf("confirm %d: unexpected deletion: %+v", i, confirmed)
+		}
+	}
+
+	// Requesting deletion again while a confirmed deletion is in progress reports
+	// that deletion rather than starting another.
+	w = sendTeamsRequest(s, http.MethodDelete, "/api/user/me", "mock_token_A", "")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("repeat request: status=%d, want %d", w.Code, http.StatusAccepted)
+	}
+	if repeated := decodeAccountDeletion(t, w.Body.Bytes()); repeated.ID != requested.ID || len(ds.deletions) != 1 {
+		t.Errorf("expected the existing deletion, got %+v", repeated)
+	}
+}
+
+func TestUserMeDeleteRejectsExpiredConfirmation(t *testing.T) {
+	ds := mockDatastore{
+		deletions: map[string]types.AccountDeletion{
+			"abc123": {
+				ID:                    "abc123",
+				Username:              "alice",
+				Status:                types.AccountDeletionPending,
+				ConfirmationToken:     "old-token",
+				RequestedAt:           "2019-11-29T10:00:00Z",
+				ConfirmationExpiresAt: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+			},
+		},
+		userProfile: types.UserProfile{EmailAddress: "alice@example.com"},
+	}
+	m := mockMailer{}
+	s := newAccountDeletionTestServer(&ds, &m)
+
+	w := sendTeamsRequest(s, http.MethodDelete, "/api/user/me?confirm=old-token", "mock_token_A", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expired token: status=%d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if ds.deletions["abc123"].Status != types.AccountDeletionPending {
+		t.Errorf("expired token must not confirm deletion, got %s", ds.deletions["abc123"].Status)
+	}
+
+	// Requesting deletion again renews the pending request.
+	w = sendTeamsRequest(s, http.MethodDelete, "/api/user/me", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("renew: status=%d, want %d", w.Code, http.StatusOK)
+	}
+	renewed := decodeAccountDeletion(t, w.Body.Bytes())
+	if renewed.ID != "abc123" || len(ds.deletions) != 1 {
+		t.Errorf("expected the pending deletion to be renewed, got %+v", renewed)
+	}
+	if token := m.lastEmailedToken(t); token == "old-token" || ds.deletions["abc123"].ConfirmationToken != token {
+		t.Errorf("expected a new token for the pending deletion, got %s", token)
+	}
+
+	w = sendTeamsRequest(s, http.MethodDelete, "/api/user/me", "", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("logged out: status=%d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestUserMeDeleteRequiresEmail(t *testing.T) {
+	var tests = []struct {
+		explanation        string
+		emailAddress       string
+		mailer             mail.Mailer
+		httpStatusExpected int
+	}{
+		{"server can't send email", "alice@example.com", nil, http.StatusServiceUnavailable},
+		{"user has no email address", "", &mockMailer{}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		ds := mockDatastore{
+			userProfile: types.UserProfile{EmailAddress: tt.emailAddress},
+		}
+		s := newAccountDeletionTestServer(&ds, tt.mailer)
+
+		w := sendTeamsRequest(s, http.MethodDelete, "/api/user/me", "mock_token_A", "")
+		if w.Code != tt.httpStatusExpected {
+			t.Errorf("%s: status=%d, want %d", tt.explanation, w.Code, tt.httpStatusExpected)
+		}
+		if len(ds.deletions) != 0 {
+			t.Errorf("%s: deletion must not start without a way to confirm it", tt.explanation)
+		}
+	}
+}
+
+func TestDeletedAccountsCantWrite(t *testing.T) {
+	ds := mockDatastore{
+		deletions: map[string]types.AccountDeletion{
+			"abc123": {
+				ID:          "abc123",
+				Username:    "alice",
+				Status:      types.AccountDeletionConfirmed,
+				RequestedAt: "2019-11-29T10:00:00Z",
+				ConfirmedAt: "2019-11-29T10:05:00Z",
+			},
+		},
+	}
+	s := newAccountDeletionTestServer(&ds, &mockMailer{})
+
+	w := sendTeamsRequest(s, http.MethodPost, "/api/follow/bob", "mock_token_A", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("write from deleted account: status=%d, want %d", w.Code, http.StatusForbidden)
+	}
+	if len(ds.following["alice"]) != 0 {
+		t.Errorf("deleted account must not follow anyone, got %v", ds.following["alice"])
+	}
+	w = sendTeamsRequest(s, http.MethodPost, "/api/follow/carol", "mock_token_B", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("write from other account: status=%d, want %d", w.Code, http.StatusOK)
+	}
+	w = sendTeamsRequest(s, http.MethodDelete, "/api/user/me", "mock_token_A", "")
+	if w.Code != http.StatusAccepted {
+		t.Errorf("deletion progress: status=%d, want %d", w.Code, http.StatusAccepted)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+)
+
+// adminUsernamesFromEnv reads the site's administrators from the
+// ADMIN_USERNAMES environment variable, a comma-separated list of usernames.
+// Invalid usernames are ignored.
+func adminUsernamesFromEnv() []string {
+	admins := []string{}
+	raw := os.Getenv("ADMIN_USERNAMES")
+	if raw == "" {
+		return admins
+	}
+	for _, username := range strings.Split(raw, ",") {
+		username = strings.TrimSpace(username)
+		if !validate.Username(username) {
+			log.Printf("Ignoring invalid username [%s] in ADMIN_USERNAMES", username)
+			continue
+		}
+		admins = append(admins, username)
+	}
+	return admins
+}
+
+// isSiteAdmin returns true if the given user administers the site.
+func (s defaultServer) isSiteAdmin(username string) bool {
+	return isStringInSlice(username, s.adminUsernames)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+	"github.com/mtlynch/whatgotdone/backend/analytics/accesslog"
+	"github.com/mtlynch/whatgotdone/backend/analytics/googleanalytics"
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// Names of the analytics sources that the ANALYTICS_SOURCE environment
+// variable can select.
+const (
+	googleAnalyticsSource = "google-analytics"
+	accessLogSource       = "access-log"
+	serverCountSource     = "server"
+)
+
+// pageViewHistoryStart is the earliest day that page view syncs copy from the
+// analytics source.
+const pageViewHistoryStart = "2019-01-01"
+
+// pageViewSyncOverlapDays is the number of days before the last synced date
+// that each sync fetches again, because analytics sources can take a few days
+// to finish counting a day's views.
+const pageViewSyncOverlapDays = 3
+
+type pageViewResponse struct {
+	Path  string `json:"path"`
+	Views int    `json:"views"`
+}
+
+func (s *defaultServer) pageViewsOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) pageViewsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			log.Print("Request is missing path query parameter")
+			http.Error(w, "Request is missing path query parameter", http.StatusBadRequest)
+			return
+		}
+
+		users, err := s.datastore.Users()
+		if err != nil {
+			log.Printf("Failed to retrieve users from datastore: %v", err)
+			http.Error(w, "Failed to retrieve pageviews", http.StatusInternalServerError)
+			return
+		}
+		if !isPathForJournalEntry(path, users) {
+			log.Printf("path is not a journal entry: %s", path)
+			http.Error(w, "path parameter must specify a journal entry", http.StatusForbidden)
+			return
+		}
+
+		pathParts := strings.Split(path, "/")
+		hidden, err := isEntryHidden(s.datastore, s.viewerFromRequest(r), pathParts[1], pathParts[2])
+		if err != nil {
+			log.Printf("Failed to check visibility of %s: %v", path, err)
+			http.Error(w, "Failed to retrieve pageviews", http.StatusInternalServerError)
+			return
+		}
+		if hidden {
+			http.Error(w, "Path has no pageview data", http.StatusNotFound)
+			return
+		}
+
+		q := r.URL.Query()
+		if q.Get("from") != "" || q.Get("to") != "" || q.Get("granularity") != "" {
+			s.pageViewSeriesGet(w, r, path, pathParts[2])
+			return
+		}
+
+		views, err := s.datastore.GetPageViews(path)
+		if _, ok := err.(datastore.PageViewsNotFoundError); ok {
+			log.Printf("No pageviews found for %s", path)
+			http.Error(w, "Path has no pageview data", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("failed to retrieve pageviews from datastore for path %s: %v", path, err)
+			http.Error(w, fmt.Sprintf("Failed to retrieve pageviews for path %s", path), http.StatusInternalServerError)
+			return
+		}
+
+		response := pageViewResponse{
+			Path:  path,
+			Views: views,
+		}
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// syncPageViews copies new daily page views of every entry from the analytics
+// source to the datastore and updates the totals of the entries it touched.
+// The first sync copies the full history. Later syncs fetch only the days since
+// the last sync, plus a few days of overlap for views that the source reported
+// late.
+//
+// Each sync replaces the daily counts it fetches and recomputes totals from the
+// stored daily counts, so repeating a sync, even one that failed partway, never
+// counts a view twice.
+func (s defaultServer) syncPageViews(now time.Time) error {
+	startDate := pageViewHistoryStart
+	sync, err := s.datastore.GetPageViewSync()
+	if _, ok := err.(datastore.PageViewSyncNotFoundError); ok {
+		log.Printf("No previous page view sync found, copying all page views since %s", startDate)
+	} else if err != nil {
+		return err
+	} else {
+		lastSynced, err := time.Parse("2006-01-02", sync.LastSyncedDate)
+		if err != nil {
+			return fmt.Errorf("invalid last synced date %s: %v", sync.LastSyncedDate, err)
+		}
+		if overlapStart := lastSynced.AddDate(0, 0, -pageViewSyncOverlapDays).Format("2006-01-02"); overlapStart > startDate {
+			startDate = overlapStart
+		}
+	}
+	endDate := now.UTC().Format("2006-01-02")
+
+	pvcs, err := s.analyticsSource.DailyPageViewsByPath(startDate, endDate)
+	if err != nil {
+		return err
+	}
+	pvcs, err = s.filterNonEntries(coalescePageViews(pvcs))
+	if err != nil {
+		return err
+	}
+
+	// Some sources report days in their own time zone, which can be ahead of
+	// UTC, so include every fetched day when recomputing totals.
+	lastDate := endDate
+	touched := map[string]bool{}
+	for _, pvc := range pvcs {
+		if err := s.datastore.InsertDailyPageViews(types.DailyPageViews{
+			Path:  pvc.Path,
+			Date:  pvc.Date,
+			Views: pvc.Views,
+		}); err != nil {
+			return fmt.Errorf("failed to store daily pageviews %v: %v", pvc, err)
+		}
+		touched[pvc.Path] = true
+		if pvc.Date > lastDate {
+			lastDate = pvc.Date
+		}
+	}
+	for path := range touched {
+		daily, err := s.datastore.GetDailyPageViews(path, pageViewHistoryStart, lastDate)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve daily pageviews for %s: %v", path, err)
+		}
+		total := 0
+		for _, pv := range daily {
+			total += pv.Views
+		}
+		if err := s.datastore.InsertPageViews(path, total); err != nil {
+			return fmt.Errorf("failed to store pageviews for %s: %v", path, err)
+		}
+	}
+
+	log.Printf("Synced page views for %d paths from %s to %s", len(touched), startDate, endDate)
+	return s.datastore.SetPageViewSync(types.PageViewSync{LastSyncedDate: endDate})
+}
+
+// newAnalyticsSource creates the source of page view data that the
+// ANALYTICS_SOURCE environment variable selects. It returns a nil source if
+// the server should count page views itself.
+func newAnalyticsSource() (analytics.Source, error) {
+	switch name := os.Getenv("ANALYTICS_SOURCE"); name {
+	case "", googleAnalyticsSource:
+		return googleanalytics.New()
+	case accessLogSource:
+		path := os.Getenv("ACCESS_LOG_PATH")
+		if path == "" {
+			return nil, errors.New("ACCESS_LOG_PATH must be set to read page views from the access log")
+		}
+		return accesslog.New(path), nil
+	case serverCountSource:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unrecognized ANALYTICS_SOURCE: %s", name)
+	}
+}
+
+// coalescePageViews combines the daily pageviews of paths that differ only in
+// their query strings or encoding.
+func coalescePageViews(pvcs []analytics.DailyPageViewCount) []analytics.DailyPageViewCount {
+	type pathDay struct {
+		path string
+		date string
+	}
+	totals := map[pathDay]int{}
+	coalesced := []analytics.DailyPageViewCount{}
+	for _, pvc := range pvcs {
+		u, err := url.Parse(pvc.Path)
+		if err != nil {
+			panic(err)
+		}
+		totals[pathDay{u.EscapedPath(), pvc.Date}] += pvc.Views
+	}
+	for k, c := range totals {
+		coalesced = append(coalesced, analytics.DailyPageViewCount{Path: k.path, Date: k.date, Views: c})
+	}
+	return coalesced
+}
+
+func (s defaultServer) filterNonEntries(pvcs []analytics.DailyPageViewCount) ([]analytics.DailyPageViewCount, error) {
+	filtered := []analytics.DailyPageViewCount{}
+	users, err := s.datastore.Users()
+	if err != nil {
+		return filtered, err
+	}
+	for _, pvc := range pvcs {
+		if isPathForJournalEntry(pvc.Path, users) {
+			filtered = append(filtered, pvc)
+		}
+	}
+	return filtered, nil
+}
+
+func isPathForJournalEntry(path string, users []string) bool {
+	pathParts := strings.Split(path, "/")
+	if len(pathParts) != 3 {
+		return false
+	}
+	// Path should start with a forward slash, so the first part is empty.
+	if pathParts[0] != "" {
+		return false
+	}
+
+	user := pathParts[1]
+	if !isStringInSlice(user, users) {
+		return false
+	}
+	entryDate := pathParts[2]
+	if !validate.EntryDate(entryDate) {
+		return false
+	}
+	return true
+}
+
+func isStringInSlice(s string, ss []string) bool {
+	for _, x := range ss {
+		if s == x {
+			return true
+		}
+	}
+	return false
+}
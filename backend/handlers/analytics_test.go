@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) InsertPageViews(path string, pageViews int) error {
+	return nil
+}
+
+func (ds mockDatastore) IncrementPageViews(path string, n int) error {
+	return nil
+}
+
+func (ds mockDatastore) GetPageViews(path string) (int, error) {
+	for _, pvc := range ds.pageViewCounts {
+		if pvc.Path == path {
+			return pvc.Views, nil
+		}
+	}
+	return 0, errors.New("no pageview results found")
+}
+
+func (ds mockDatastore) DeletePageViews(path string) error {
+	return nil
+}
+
+func (ds mockDatastore) GetPageViewSync() (types.PageViewSync, error) {
+	if ds.pageViewSync == nil {
+		return types.PageViewSync{}, datastore.PageViewSyncNotFoundError{}
+	}
+	return *ds.pageViewSync, nil
+}
+
+func (ds *mockDatastore) SetPageViewSync(sync types.PageViewSync) error {
+	ds.pageViewSync = &sync
+	return nil
+}
+
+func TestPageViewsGet(t *testing.T) {
+	var pageViewsGetTests = []struct {
+		path               string
+		httpStatusExpected int
+		viewsExpected      int
+	}{
+		{
+			"/jimmy123/2020-01-17",
+			http.StatusOK,
+			5,
+		},
+		{
+			"",
+			http.StatusBadRequest,
+			0,
+		},
+		{
+			"/non.existent.user/2020-01-17",
+			http.StatusForbidden,
+			0,
+		},
+		{
+			"/non.existent.user/2020-01-16",
+			http.StatusForbidden,
+			0,
+		},
+		{
+			"/non.existent.user",
+			http.StatusForbidden,
+			0,
+		},
+		{
+			"/",
+			http.StatusForbidden,
+			0,
+		},
+		{
+			"/privacy-policy",
+			http.StatusForbidden,
+			0,
+		},
+	}
+
+	ds := mockDatastore{
+		users: []string{"jimmy123"},
+		pageViewCounts: []analytics.PageViewCount{
+			analytics.PageViewCount{Path: "/jimmy123/2020-01-17", Views: 5},
+		},
+	}
+	router := mux.NewRouter()
+	s := defaultServer{
+		datastore:      &ds,
+		router:         router,
+		csrfMiddleware: dummyCsrfMiddleware(),
+	}
+	s.routes()
+
+	for _, tt := range pageViewsGetTests {
+		req, err := http.NewRequest("GET", "/api/pageViews?path="+url.QueryEscape(tt.path), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("for input [%s], handler returned wrong status code: got %v want %v",
+				tt.path, status, tt.httpStatusExpected)
+		}
+
+		if tt.httpStatusExpected == http.StatusOK {
+			var response pageViewResponse
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			if err != nil {
+				t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+			}
+			if response.Views != tt.viewsExpected {
+				t.Fatalf("for input [%s], unexpected view count: got %v want %v",
+					tt.path, response.Views, tt.viewsExpected)
+			}
+		}
+	}
+}
+
+func TestSyncPageViewsIsIncrementalAndIdempotent(t *testing.T) {
+	mock := &mockDatastore{
+		users: []string{"alice"},
+	}
+	ds := pageViewRecordingDatastore{
+		mockDatastore: mock,
+		saved:         map[string]int{},
+	}
+	source := &mockAnalyticsSource{
+		daily: []analytics.DailyPageViewCount{
+			{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 4},
+			{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 2},
+			{Path: "/privacy-policy", Date: "2019-11-30", Views: 9},
+		},
+	}
+	s := defaultServer{
+		datastore:       ds,
+		analyticsSource: source,
+	}
+	day := func(d int) time.Time {
+		return time.Date(2019, 12, d, 15, 0, 0, 0, time.UTC)
+	}
+
+	steps := []struct {
+		explanation    string
+		now            time.Time
+		update         func()
+		rangeExpected  [2]string
+		totalExpected  int
+		syncedExpected string
+	}{
+		{
+			"first sync copies the full history",
+			day(1),
+			func() {},
+			[2]string{"2019-01-01", "2019-12-01"},
+			6,
+			"2019-12-01",
+		},
+		{
+			"repeating a sync doesn't double count",
+			day(1),
+			func() {},
+			[2]string{"2019-11-28", "2019-12-01"},
+			6,
+			"2019-12-01",
+		},
+		{
+			"later sync picks up new days and late views",
+			day(4),
+			func() {
+				source.daily[1].Views = 3
+				source.daily = append(source.daily, analytics.DailyPageViewCount{Path: "/alice/2019-11-29", Date: "2019-12-03", Views: 5})
+			},
+			[2]string{"2019-11-28", "2019-12-04"},
+			12,
+			"2019-12-04",
+		},
+		{
+			"sync ignores days before the overlap",
+			day(10),
+			func() {
+				source.daily[0].Views = 100
+			},
+			[2]string{"2019-12-01", "2019-12-10"},
+			12,
+			"2019-12-10",
+		},
+		{
+			"failed sync doesn't advance the last synced date",
+			day(12),
+			func() {
+				source.err = errors.New("dummy analytics error")
+			},
+			[2]string{"2019-12-07", "2019-12-12"},
+			12,
+			"2019-12-10",
+		},
+	}
+	for _, step := range steps {
+		step.update()
+		err := s.syncPageViews(step.now)
+		if (err != nil) != (source.err != nil) {
+			t.Fatalf("%s: unexpected error: %v", step.explanation, err)
+		}
+		if r := source.ranges[len(source.ranges)-1]; r != step.rangeExpected {
+			t.Errorf("%s: fetched %v, want %v", step.explanation, r, step.rangeExpected)
+		}
+		if total := ds.saved["/alice/2019-11-29"]; total != step.totalExpected {
+			t.Errorf("%s: total=%d, want %d", step.explanation, total, step.totalExpected)
+		}
+		if _, ok := ds.saved["/privacy-policy"]; ok {
+			t.Errorf("%s: stored page views for a path that isn't an entry", step.explanation)
+		}
+		if mock.pageViewSync == nil || mock.pageViewSync.LastSyncedDate != step.syncedExpected {
+			t.Errorf("%s: sync=%+v, want last synced date %s", step.explanation, mock.pageViewSync, step.syncedExpected)
+		}
+	}
+}
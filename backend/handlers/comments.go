@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (s defaultServer) commentsOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) commentsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entryAuthor, entryDate, ok := s.commentEntryForRequest(w, r, s.viewerFromRequest(r))
+		if !ok {
+			return
+		}
+
+		comments, err := s.datastore.GetComments(entryAuthor, entryDate)
+		if err != nil {
+			log.Printf("Failed to retrieve comments for %s/%s: %s", entryAuthor, entryDate, err)
+			http.Error(w, "Failed to retrieve comments", http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(comments, func(i, j int) bool {
+			return comments[i].Timestamp < comments[j].Timestamp
+		})
+
+		if err := json.NewEncoder(w).Encode(comments); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) commentsPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to comment", http.StatusForbidden)
+			return
+		}
+
+		entryAuthor, entryDate, ok := s.commentEntryForRequest(w, r, username)
+		if !ok {
+			return
+		}
+
+		type commentRequest struct {
+			Markdown string `json:"markdown"`
+			ParentID string `json:"parentId"`
+		}
+		var cr commentRequest
+		if err := json.NewDecoder(r.Body).Decode(&cr); err != nil {
+			log.Printf("Invalid comment request: %v", err)
+			http.Error(w, "Invalid comment request", http.StatusBadRequest)
+			return
+		}
+		markdown := strings.TrimSpace(cr.Markdown)
+		if !validate.Comment(markdown) {
+			http.Error(w, "Invalid comment", http.StatusBadRequest)
+			return
+		}
+
+		if cr.ParentID != "" {
+			comments, err := s.datastore.GetComments(entryAuthor, entryDate)
+			if err != nil {
+				log.Printf("Failed to retrieve comments for %s/%s: %s", entryAuthor, entryDate, err)
+				http.Error(w, "Failed to add comment", http.StatusInternalServerError)
+				return
+			}
+			parent, ok := findComment(comments, cr.ParentID)
+			if !ok || parent.Deleted {
+				http.Error(w, "Parent comment not found", http.StatusBadRequest)
+				return
+			}
+		}
+
+		id, err := newCommentID()
+		if err != nil {
+			log.Printf("Failed to generate comment ID: %s", err)
+			http.Error(w, "Failed to add comment", http.StatusInternalServerError)
+			return
+		}
+		c := types.Comment{
+			ID:        id,
+			Username:  username,
+			ParentID:  cr.ParentID,
+			Markdown:  markdown,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		if err := s.datastore.SetComment(entryAuthor, entryDate, c); err != nil {
+			log.Printf("Failed to add comment to %s/%s: %s", entryAuthor, entryDate, err)
+			http.Error(w, "Failed to add comment", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// commentPost edits an existing comment. Only the comment's author can edit
+// it.
+func (s defaultServer) commentPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to edit comments", http.StatusForbidden)
+			return
+		}
+
+		entryAuthor, entryDate, ok := s.commentEntryForRequest(w, r, username)
+		if !ok {
+			return
+		}
+		c, ok := s.commentForRequest(w, r, entryAuthor, entryDate)
+		if !ok {
+			return
+		}
+		if c.Username != username {
+			http.Error(w, "You can only edit your own comments", http.StatusForbidden)
+			return
+		}
+
+		type commentRequest struct {
+			Markdown string `json:"markdown"`
+		}
+		var cr commentRequest
+		if err := json.NewDecoder(r.Body).Decode(&cr); err != nil {
+			log.Printf("Invalid comment request: %v", err)
+			http.Error(w, "Invalid comment request", http.StatusBadRequest)
+			return
+		}
+		markdown := strings.TrimSpace(cr.Markdown)
+		if !validate.Comment(markdown) {
+			http.Error(w, "Invalid comment", http.StatusBadRequest)
+			return
+		}
+
+		c.Markdown = markdown
+		c.LastModified = time.Now().Format(time.RFC3339)
+		if err := s.datastore.SetComment(entryAuthor, entryDate, c); err != nil {
+			log.Printf("Failed to update comment %s: %s", c.ID, err)
+			http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// commentDelete removes a comment's content. The comment's author and the
+// entry's author can both delete a comment.
+func (s defaultServer) commentDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to delete comments", http.StatusForbidden)
+			return
+		}
+
+		entryAuthor, entryDate, ok := s.commentEntryForRequest(w, r, username)
+		if !ok {
+			return
+		}
+		c, ok := s.commentForRequest(w, r, entryAuthor, entryDate)
+		if !ok {
+			return
+		}
+		if c.Username != username && entryAuthor != username {
+			http.Error(w, "You can only delete your own comments or comments on your entries", http.StatusForbidden)
+			return
+		}
+
+		if !c.Deleted {
+			// Keep a placeholder rather than removing the comment so that replies
+			// to it remain part of the thread.
+			c = types.Comment{
+				ID:           c.ID,
+				ParentID:     c.ParentID,
+				Timestamp:    c.Timestamp,
+				LastModified: time.Now().Format(time.RFC3339),
+				Deleted:      true,
+			}
+			if err := s.datastore.SetComment(entryAuthor, entryDate, c); err != nil {
+				log.Printf("Failed to delete comment %s: %s", c.ID, err)
+				http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		type commentDeleteResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(commentDeleteResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// commentEntryForRequest retrieves the entry author and date from the request
+// path and verifies that the entry exists and that the viewer can read it. If
+// not, it writes an error response and returns false.
+func (s defaultServer) commentEntryForRequest(w http.ResponseWriter, r *http.Request, viewer string) (string, string, bool) {
+	entryAuthor, err := usernameFromRequestPath(r)
+	if err != nil {
+		log.Printf("Failed to retrieve username from request path: %s", err)
+		http.Error(w, "Invalid username", http.StatusBadRequest)
+		return "", "", false
+	}
+	entryDate, err := dateFromRequestPath(r)
+	if err != nil {
+		log.Printf("Invalid date: %s", entryDate)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", "", false
+	}
+
+	entries, err := getVisibleEntries(s.datastore, viewer, entryAuthor)
+	if err != nil {
+		log.Printf("Failed to retrieve entries for %s: %s", entryAuthor, err)
+		http.Error(w, "Failed to retrieve entry", http.StatusInternalServerError)
+		return "", "", false
+	}
+	if _, ok := findEntryByDate(entries, entryDate); !ok {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return "", "", false
+	}
+	return entryAuthor, entryDate, true
+}
+
+// commentForRequest retrieves the comment specified in the request path. If
+// the comment doesn't exist, it writes an error response and returns false.
+func (s defaultServer) commentForRequest(w http.ResponseWriter, r *http.Request, entryAuthor string, entryDate string) (types.Comment, bool) {
+	commentID, err := commentIDFromRequestPath(r)
+	if err != nil {
+		log.Printf("Failed to retrieve comment ID from request path: %s", err)
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return types.Comment{}, false
+	}
+	comments, err := s.datastore.GetComments(entryAuthor, entryDate)
+	if err != nil {
+		log.Printf("Failed to retrieve comments for %s/%s: %s", entryAuthor, entryDate, err)
+		http.Error(w, "Failed to retrieve comment", http.StatusInternalServerError)
+		return types.Comment{}, false
+	}
+	c, ok := findComment(comments, commentID)
+	if !ok {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return types.Comment{}, false
+	}
+	return c, true
+}
+
+func findComment(comments []types.Comment, id string) (types.Comment, bool) {
+	for _, c := range comments {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return types.Comment{}, false
+}
+
+func newCommentID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
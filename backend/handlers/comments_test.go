@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetComments(entryAuthor string, entryDate string) ([]types.Comment, error) {
+	comments := make([]types.Comment, len(ds.comments))
+	copy(comments, ds.comments)
+	return comments, nil
+}
+
+func (ds mockDatastore) GetCommentsByUser(username string) ([]types.EntryComment, error) {
+	comments := []types.EntryComment{}
+	for _, c := range ds.givenComments {
+		if c.Username == username {
+			comments = append(comments, c)
+		}
+	}
+	return comments, nil
+}
+
+func (ds *mockDatastore) SetComment(entryAuthor string, entryDate string, c types.Comment) error {
+	for i := range ds.comments {
+		if ds.comments[i].ID == c.ID {
+			ds.comments[i] = c
+			return nil
+		}
+	}
+	ds.comments = append(ds.comments, c)
+	return nil
+}
+
+func (ds *mockDatastore) DeleteComment(entryAuthor string, entryDate string, commentID string) error {
+	comments := []types.Comment{}
+	for _, c := range ds.comments {
+		if c.ID != commentID {
+			comments = append(comments, c)
+		}
+	}
+	ds.comments = comments
+	return nil
+}
+
+func postComment(t *testing.T, s defaultServer, authToken string, body string) types.Comment {
+	w := sendTeamsRequest(s, "POST", "/api/comments/entry/alice/2019-11-29", authToken, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to post comment: got status %v", w.Code)
+	}
+	var c types.Comment
+	if err := json.Unmarshal(w.Body.Bytes(), &c); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	return c
+}
+
+func TestCommentsPost(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", Markdown: "Shipped billing"},
+		},
+		comments: []types.Comment{
+			types.Comment{ID: "0000000000000001", Username: "bob", Markdown: "Nice", Timestamp: "2019-11-29T20:00:00Z"},
+			types.Comment{ID: "0000000000000002", Timestamp: "2019-11-29T20:01:00Z", Deleted: true},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	var tests = []struct {
+		explanation        string
+		path               string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"anonymous users can't comment", "/api/comments/entry/alice/2019-11-29", "", `{"markdown": "Hi"}`, http.StatusForbidden},
+		{"rejects empty comment", "/api/comments/entry/alice/2019-11-29", "mock_token_B", `{"markdown": "   "}`, http.StatusBadRequest},
+		{"rejects comment with heading", "/api/comments/entry/alice/2019-11-29", "mock_token_B", `{"markdown": "# Question"}`, http.StatusBadRequest},
+		{"rejects reply to missing comment", "/api/comments/entry/alice/2019-11-29", "mock_token_B", `{"markdown": "Hi", "parentId": "00000000000000ff"}`, http.StatusBadRequest},
+		{"rejects reply to deleted comment", "/api/comments/entry/alice/2019-11-29", "mock_token_B", `{"markdown": "Hi", "parentId": "0000000000000002"}`, http.StatusBadRequest},
+		{"rejects comment on missing entry", "/api/comments/entry/alice/2019-11-22", "mock_token_B", `{"markdown": "Hi"}`, http.StatusNotFound},
+		{"accepts top-level comment", "/api/comments/entry/alice/2019-11-29", "mock_token_B", `{"markdown": "How did you pick the price?"}`, http.StatusOK},
+		{"accepts reply", "/api/comments/entry/alice/2019-11-29", "mock_token_A", `{"markdown": "Thanks!", "parentId": "0000000000000001"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := sendTeamsRequest(s, "POST", tt.path, tt.authToken, tt.body)
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+	}
+	if len(ds.comments) != 4 {
+		t.Fatalf("expected two new comments, got %+v", ds.comments)
+	}
+	reply := ds.comments[3]
+	if reply.Username != "alice" || reply.ParentID != "0000000000000001" || reply.Markdown != "Thanks!" {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+}
+
+func TestCommentsEditAndDelete(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", Markdown: "Shipped billing"},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	bobComment := postComment(t, s, "mock_token_B", `{"markdown": "First"}`)
+	carolComment := postComment(t, s, "mock_token_C", `{"markdown": "Second", "parentId": "`+bobComment.ID+`"}`)
+	bobPath := "/api/comments/entry/alice/2019-11-29/" + bobComment.ID
+	carolPath := "/api/comments/entry/alice/2019-11-29/" + carolComment.ID
+
+	steps := []struct {
+		explanation        string
+		method             string
+		path               string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"only the comment author can edit", "POST", bobPath, "mock_token_C", `{"markdown": "Hijacked"}`, http.StatusForbidden},
+		{"entry author can't edit other users' comments", "POST", bobPath, "mock_token_A", `{"markdown": "Hijacked"}`, http.StatusForbidden},
+		{"comment author edits comment", "POST", bobPath, "mock_token_B", `{"markdown": "First, edited"}`, http.StatusOK},
+		{"edit must be valid", "POST", bobPath, "mock_token_B", `{"markdown": "![img](x.png)"}`, http.StatusBadRequest},
+		{"other readers can't delete", "DELETE", bobPath, "mock_token_C", "", http.StatusForbidden},
+		{"entry author deletes a reader's comment", "DELETE", bobPath, "mock_token_A", "", http.StatusOK},
+		{"deleting again is idempotent", "DELETE", bobPath, "mock_token_A", "", http.StatusOK},
+		{"comment author deletes own comment", "DELETE", carolPath, "mock_token_C", "", http.StatusOK},
+		{"missing comment returns not found", "DELETE", "/api/comments/entry/alice/2019-11-29/00000000000000ff", "mock_token_A", "", http.StatusNotFound},
+		{"invalid comment ID is rejected", "DELETE", "/api/comments/entry/alice/2019-11-29/nope", "mock_token_A", "", http.StatusBadRequest},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, step.method, step.path, step.authToken, step.body)
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				step.explanation, status, step.httpStatusExpected)
+		}
+		if step.explanation == "comment author edits comment" && ds.comments[0].Markdown != "First, edited" {
+			t.Fatalf("comment was not edited: %+v", ds.comments[0])
+		}
+	}
+
+	w := sendTeamsRequest(s, "GET", "/api/comments/entry/alice/2019-11-29", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to list comments: got status %v", w.Code)
+	}
+	var comments []types.Comment
+	if err := json.Unmarshal(w.Body.Bytes(), &comments); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if len(comments) != 2 {
+		t.Fatalf("deleted comments should remain as placeholders: %+v", comments)
+	}
+	for _, c := range comments {
+		if !c.Deleted || c.Markdown != "" || c.Username != "" {
+			t.Fatalf("deleted comment still has content: %+v", c)
+		}
+	}
+	if comments[1].ParentID != bobComment.ID {
+		t.Fatalf("deleting a comment should preserve the thread: %+v", comments)
+	}
+}
+
+func TestCommentsGetRespectsVisibility(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", Markdown: "Secret", Visibility: types.VisibilityPrivate},
+		},
+		comments: []types.Comment{
+			types.Comment{ID: "0000000000000001", Username: "alice", Markdown: "Note to self"},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	if w := sendTeamsRequest(s, "GET", "/api/comments/entry/alice/2019-11-29", "mock_token_B", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("other users should not see comments on private entries: got %v", w.Code)
+	}
+	if w := sendTeamsRequest(s, "POST", "/api/comments/entry/alice/2019-11-29", "mock_token_B", `{"markdown": "Hi"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("other users should not comment on private entries: got %v", w.Code)
+	}
+	if w := sendTeamsRequest(s, "GET", "/api/comments/entry/alice/2019-11-29", "mock_token_A", ""); w.Code != http.StatusOK {
+		t.Fatalf("author should see comments on private entries: got %v", w.Code)
+	}
+}
@@ -1,9 +1,19 @@
 package handlers
 
 import (
+	"net/http"
+
 	"github.com/gorilla/csrf"
 )
 
+// csrfExemptPaths are routes that authorize requests with signed tokens in
+// their URLs rather than with the user's session, so they must accept POST
+// requests from outside What Got Done, such as one-click unsubscribes from
+// email clients.
+var csrfExemptPaths = []string{
+	"/api/digest/unsubscribe",
+}
+
 func newCsrfMiddleware() httpMiddlewareHandler {
 	csrfSeed := getCsrfSeed()
 	return csrf.Protect(
@@ -14,3 +24,14 @@ func newCsrfMiddleware() httpMiddlewareHandler {
 		csrf.Path("/"),
 		csrf.Secure(false))
 }
+
+// skipCsrfForSignedLinks turns off CSRF checks for csrfExemptPaths. It must
+// run before the CSRF middleware.
+func (s defaultServer) skipCsrfForSignedLinks(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isStringInSlice(r.URL.Path, csrfExemptPaths) {
+			r = csrf.UnsafeSkipCheck(r)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// maxDigestAuthors is the maximum number of authors a user can select for
+// their email digest.
+const maxDigestAuthors = 100
+
+func (s defaultServer) digestOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) digestGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view your digest subscription", http.StatusForbidden)
+			return
+		}
+
+		sub, err := s.datastore.GetDigestSubscription(username)
+		if _, ok := err.(datastore.DigestSubscriptionNotFoundError); ok {
+			http.Error(w, "Not subscribed to digests", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("Failed to retrieve digest subscription for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve digest subscription", http.StatusInternalServerError)
+			return
+		}
+		if sub.Authors == nil {
+			sub.Authors = []string{}
+		}
+
+		if err := json.NewEncoder(w).Encode(sub); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) digestPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to subscribe to digests", http.StatusForbidden)
+			return
+		}
+
+		type digestRequest struct {
+			Frequency types.DigestFrequency `json:"frequency"`
+			Authors   []string              `json:"authors"`
+		}
+		var dr digestRequest
+		if err := json.NewDecoder(r.Body).Decode(&dr); err != nil {
+			log.Printf("Invalid digest request: %v", err)
+			http.Error(w, "Invalid digest request", http.StatusBadRequest)
+			return
+		}
+		if dr.Frequency != types.DigestDaily && dr.Frequency != types.DigestWeekly {
+			http.Error(w, "Invalid frequency: must be daily or weekly", http.StatusBadRequest)
+			return
+		}
+		if len(dr.Authors) > maxDigestAuthors {
+			http.Error(w, fmt.Sprintf("Too many authors: limit is %d", maxDigestAuthors), http.StatusBadRequest)
+			return
+		}
+		authors := []string{}
+		for _, author := range dr.Authors {
+			if !validate.Username(author) {
+				http.Error(w, "Invalid author", http.StatusBadRequest)
+				return
+			}
+			if !isStringInSlice(author, authors) {
+				authors = append(authors, author)
+			}
+		}
+
+		sub, err := s.datastore.GetDigestSubscription(username)
+		if _, ok := err.(datastore.DigestSubscriptionNotFoundError); ok {
+			// Start the first digest's time window now so that new subscribers
+			// don't receive an immediate digest of old entries.
+			sub = types.DigestSubscription{
+				LastSentAt: time.Now().Format(time.RFC3339),
+			}
+		} else if err != nil {
+			log.Printf("Failed to retrieve digest subscription for %s: %s", username, err)
+			http.Error(w, "Failed to update digest subscription", http.StatusInternalServerError)
+			return
+		}
+		sub.Frequency = dr.Frequency
+		sub.Authors = authors
+
+		if err := s.datastore.SetDigestSubscription(username, sub); err != nil {
+			log.Printf("Failed to save digest subscription for %s: %s", username, err)
+			http.Error(w, "Failed to update digest subscription", http.StatusInternalServerError)
+			return
+		}
+
+		type digestResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(digestResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) digestDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to unsubscribe from digests", http.StatusForbidden)
+			return
+		}
+
+		if err := s.datastore.DeleteDigestSubscription(username); err != nil {
+			log.Printf("Failed to delete digest subscription for %s: %s", username, err)
+			http.Error(w, "Failed to unsubscribe from digests", http.StatusInternalServerError)
+			return
+		}
+
+		type digestResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(digestResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+var digestUnsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Unsubscribe from What Got Done digests</title></head>
+<body>
+<p>Stop sending What Got Done digest emails to {{.Username}}?</p>
+<form method="post" action="{{.Action}}">
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+// digestUnsubscribeGet handles the unsubscribe links in digest emails by
+// asking the user to confirm. It doesn't unsubscribe the user itself, because
+// email scanners and link prefetchers open links without the user's consent.
+func (s defaultServer) digestUnsubscribeGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := s.verifyDigestUnsubscribe(w, r)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := digestUnsubscribeTemplate.Execute(w, struct {
+			Username string
+			Action   string
+		}{
+			Username: username,
+			Action:   r.URL.RequestURI(),
+		}); err != nil {
+			log.Printf("Failed to render unsubscribe page: %s", err)
+		}
+	}
+}
+
+// digestUnsubscribePost unsubscribes a user from email digests, either from
+// the confirmation page or from an email client's one-click unsubscribe (RFC
+// 8058). The signed token authorizes the request, so the user doesn't need to
+// be logged in.
+func (s defaultServer) digestUnsubscribePost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := s.verifyDigestUnsubscribe(w, r)
+		if !ok {
+			return
+		}
+
+		if err := s.datastore.DeleteDigestSubscription(username); err != nil {
+			log.Printf("Failed to delete digest subscription for %s: %s", username, err)
+			http.Error(w, "Failed to unsubscribe from digests", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "You have been unsubscribed from What Got Done digest emails.")
+	}
+}
+
+// verifyDigestUnsubscribe checks the signed token in an unsubscribe link. If
+// the link is invalid, it writes an error response and returns false.
+func (s defaultServer) verifyDigestUnsubscribe(w http.ResponseWriter, r *http.Request) (string, bool) {
+	username := r.URL.Query().Get("username")
+	if !validate.Username(username) {
+		http.Error(w, "Invalid username", http.StatusBadRequest)
+		return "", false
+	}
+	if !s.digestSigner.Verify(username, r.URL.Query().Get("token")) {
+		http.Error(w, "Invalid unsubscribe link", http.StatusForbidden)
+		return "", false
+	}
+	return username, true
+}
@@ -0,0 +1,8 @@
+// +build dev
+
+package handlers
+
+func getDigestUnsubscribeSecret(sendsEmail bool) string {
+	// In dev mode, use a hardcoded secret for digest unsubscribe links.
+	return "dummy-dev-digest-unsubscribe-secret"
+}
@@ -0,0 +1,19 @@
+// +build !dev
+
+package handlers
+
+import (
+	"log"
+	"os"
+)
+
+// getDigestUnsubscribeSecret returns the key that signs unsubscribe links in
+// digest emails. It's separate from the CSRF seed so that rotating either key
+// doesn't affect the other. Only servers that send email need the key.
+func getDigestUnsubscribeSecret(sendsEmail bool) string {
+	secret := os.Getenv("DIGEST_UNSUBSCRIBE_SECRET")
+	if secret == "" && sendsEmail {
+		log.Fatalf("DIGEST_UNSUBSCRIBE_SECRET environment variable must be set to send email digests")
+	}
+	return secret
+}
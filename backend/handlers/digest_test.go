@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/digest"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetDigestSubscription(username string) (types.DigestSubscription, error) {
+	if sub, ok := ds.digests[username]; ok {
+		return sub, nil
+	}
+	return types.DigestSubscription{}, datastore.DigestSubscriptionNotFoundError{Username: username}
+}
+
+func (ds *mockDatastore) SetDigestSubscription(username string, sub types.DigestSubscription) error {
+	if ds.digests == nil {
+		ds.digests = map[string]types.DigestSubscription{}
+	}
+	sub.Username = username
+	ds.digests[username] = sub
+	return nil
+}
+
+func (ds *mockDatastore) DeleteDigestSubscription(username string) error {
+	delete(ds.digests, username)
+	return nil
+}
+
+func (ds mockDatastore) GetDigestSubscriptions() ([]types.DigestSubscription, error) {
+	subs := []types.DigestSubscription{}
+	for _, sub := range ds.digests {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func TestDigestSubscriptionLifecycle(t *testing.T) {
+	ds := mockDatastore{}
+	s := newTeamsTestServer(&ds)
+
+	steps := []struct {
+		explanation        string
+		method             string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"anonymous users can't subscribe", "POST", "", `{"frequency": "daily", "authors": ["bob"]}`, http.StatusForbidden},
+		{"alice has no subscription yet", "GET", "mock_token_A", "", http.StatusNotFound},
+		{"rejects invalid frequency", "POST", "mock_token_A", `{"frequency": "hourly", "authors": ["bob"]}`, http.StatusBadRequest},
+		{"rejects invalid author", "POST", "mock_token_A", `{"frequency": "daily", "authors": ["bad user"]}`, http.StatusBadRequest},
+		{"alice subscribes to bob", "POST", "mock_token_A", `{"frequency": "daily", "authors": ["bob", "bob"]}`, http.StatusOK},
+		{"alice sees her subscription", "GET", "mock_token_A", "", http.StatusOK},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, step.method, "/api/user/me/digest", step.authToken, step.body)
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				step.explanation, status, step.httpStatusExpected)
+		}
+	}
+
+	w := sendTeamsRequest(s, "GET", "/api/user/me/digest", "mock_token_A", "")
+	var response types.DigestSubscription
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	expected := types.DigestSubscription{Frequency: types.DigestDaily, Authors: []string{"bob"}}
+	if !reflect.DeepEqual(response, expected) {
+		t.Fatalf("unexpected subscription: got %+v want %+v", response, expected)
+	}
+	if ds.digests["alice"].LastSentAt == "" {
+		t.Fatalf("new subscription should start its digest window immediately")
+	}
+
+	w = sendTeamsRequest(s, "DELETE", "/api/user/me/digest", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to unsubscribe: %v", w.Code)
+	}
+	if _, ok := ds.digests["alice"]; ok {
+		t.Fatalf("subscription still exists after unsubscribing")
+	}
+}
+
+func TestDigestUnsubscribe(t *testing.T) {
+	signer := digest.NewSigner("dummy-secret")
+	var tests = []struct {
+		explanation        string
+		username           string
+		token              string
+		httpStatusExpected int
+		subscribedExpected bool
+	}{
+		{
+			"valid token unsubscribes user",
+			"bob",
+			signer.Sign("bob"),
+			http.StatusOK,
+			false,
+		},
+		{
+			"token for another user is rejected",
+			"bob",
+			signer.Sign("alice"),
+			http.StatusForbidden,
+			true,
+		},
+		{
+			"missing token is rejected",
+			"bob",
+			"",
+			http.StatusForbidden,
+			true,
+		},
+		{
+			"invalid username is rejected",
+			"bad user",
+			signer.Sign("bad user"),
+			http.StatusBadRequest,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		ds := mockDatastore{
+			digests: map[string]types.DigestSubscription{
+				"bob": types.DigestSubscription{Username: "bob", Frequency: types.DigestWeekly},
+			},
+		}
+		s := defaultServer{
+			datastore:      &ds,
+			digestSigner:   signer,
+			router:         mux.NewRouter(),
+			csrfMiddleware: dummyCsrfMiddleware(),
+		}
+		s.routes()
+
+		v := url.Values{}
+		v.Set("username", tt.username)
+		v.Set("token", tt.token)
+		req, err := http.NewRequest("GET", "/api/digest/unsubscribe?"+v.Encode(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: GET returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+		if _, subscribed := ds.digests["bob"]; !subscribed {
+			t.Fatalf("%s: GET must not unsubscribe the user", tt.explanation)
+		}
+
+		// Email clients send one-click unsubscribes as form posts (RFC 8058).
+		req, err = http.NewRequest("POST", "/api/digest/unsubscribe?"+v.Encode(), strings.NewReader("List-Unsubscribe=One-Click"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w = httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: POST returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+		if _, subscribed := ds.digests["bob"]; subscribed != tt.subscribedExpected {
+			t.Fatalf("%s: unexpected subscription state: got %v want %v", tt.explanation, subscribed, tt.subscribedExpected)
+		}
+	}
+}
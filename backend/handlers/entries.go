@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/site"
 	"github.com/mtlynch/whatgotdone/backend/types"
 )
 
@@ -19,7 +22,7 @@ func (s *defaultServer) entriesGet() http.HandlerFunc {
 			return
 		}
 
-		entries, err := s.datastore.GetEntries(username)
+		entries, err := getVisibleEntries(s.datastore, s.viewerFromRequest(r), username)
 		if err != nil {
 			log.Printf("Failed to retrieve entries: %s", err)
 			http.Error(w, fmt.Sprintf("Failed to retrieve entries for %s", username), http.StatusInternalServerError)
@@ -57,7 +60,9 @@ func (s *defaultServer) entryPost() http.HandlerFunc {
 		}
 
 		type entryRequest struct {
-			EntryContent string `json:"entryContent"`
+			EntryContent string           `json:"entryContent"`
+			Visibility   types.Visibility `json:"visibility"`
+			TeamID       string           `json:"teamId"`
 		}
 
 		var t entryRequest
@@ -66,13 +71,50 @@ func (s *defaultServer) entryPost() http.HandlerFunc {
 		if err != nil {
 			log.Printf("Failed to decode request: %s", err)
 			http.Error(w, "Failed to decode request", http.StatusBadRequest)
+			return
+		}
+
+		if t.Visibility == types.VisibilityTeam {
+			if !validate.TeamID(t.TeamID) {
+				http.Error(w, "Invalid team ID", http.StatusBadRequest)
+				return
+			}
+			team, err := s.datastore.GetTeam(t.TeamID)
+			if _, ok := err.(datastore.TeamNotFoundError); ok {
+				http.Error(w, "Team does not exist", http.StatusBadRequest)
+				return
+			} else if err != nil {
+				log.Printf("Failed to retrieve team %s: %s", t.TeamID, err)
+				http.Error(w, "Failed to insert entry", http.StatusInternalServerError)
+				return
+			}
+			if _, ok := team.Member(username); !ok {
+				http.Error(w, "You must be a member of a team to publish to it", http.StatusForbidden)
+				return
+			}
+		} else if t.TeamID != "" {
+			http.Error(w, "Only entries with team visibility can specify a team", http.StatusBadRequest)
+			return
+		} else if !isValidDefaultVisibility(t.Visibility) {
+			http.Error(w, "Invalid visibility", http.StatusBadRequest)
+			return
 		}
 
 		j := types.JournalEntry{
 			Date:         date,
 			LastModified: time.Now().Format(time.RFC3339),
 			Markdown:     t.EntryContent,
+			Visibility:   t.Visibility,
+			TeamID:       t.TeamID,
+		}
+
+		existing, err := s.datastore.GetEntries(username)
+		if err != nil {
+			log.Printf("Failed to retrieve entries for %s: %s", username, err)
+			http.Error(w, "Failed to insert entry", http.StatusInternalServerError)
+			return
 		}
+		previous, isUpdate := findEntryByDate(existing, date)
 
 		err = s.datastore.InsertDraft(username, j)
 		if err != nil {
@@ -80,6 +122,12 @@ func (s *defaultServer) entryPost() http.HandlerFunc {
 			http.Error(w, "Failed to insert entry", http.StatusInternalServerError)
 			return
 		}
+		j.Mentions, err = s.readMentionedUsers(username, j.Markdown)
+		if err != nil {
+			log.Printf("Failed to read mentions in %s/%s: %s", username, date, err)
+			http.Error(w, "Failed to insert entry", http.StatusInternalServerError)
+			return
+		}
 		err = s.datastore.InsertEntry(username, j)
 		if err != nil {
 			log.Printf("Failed to insert journal entry: %s", err)
@@ -87,6 +135,30 @@ func (s *defaultServer) entryPost() http.HandlerFunc {
 			return
 		}
 
+		// The entry is already saved, so a notification failure shouldn't fail
+		// the request.
+		if err := s.recordMentionNotifications(username, j, previous.Mentions); err != nil {
+			log.Printf("Failed to record mention notifications for %s/%s: %s", username, date, err)
+		}
+
+		// Only announce new entries in Slack so that edits don't spam channels.
+		if !isUpdate {
+			if err := s.postEntryToSlack(username, j); err != nil {
+				log.Printf("Failed to announce entry %s/%s in Slack: %s", username, date, err)
+			}
+		}
+		event := types.WebhookEntryPublished
+		if isUpdate {
+			event = types.WebhookEntryUpdated
+		}
+		s.dispatchWebhook(username, event, entryEventData{
+			Author:     username,
+			Date:       date,
+			URL:        site.EntryURL(username, date),
+			Markdown:   j.Markdown,
+			Visibility: j.Visibility,
+		})
+
 		type entryResponse struct {
 			Ok   bool   `json:"ok"`
 			Path string `json:"path"`
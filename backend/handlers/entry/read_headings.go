@@ -0,0 +1,31 @@
+package entry
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/codefence"
+)
+
+// ReadProjectHeadings returns the display text of each project heading in an
+// entry, in the order they appear. Headings inside fenced code blocks don't
+// count.
+func ReadProjectHeadings(markdown string) []string {
+	headings := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(markdown))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if fence := codefence.Opening(line); fence != "" {
+			readUntilCodeBlockEnd(scanner, fence)
+			continue
+		}
+		if !strings.HasPrefix(line, headerPrefix) {
+			continue
+		}
+		heading := strings.TrimSpace(stripMarkdownLink(line[len(headerPrefix):]))
+		if heading != "" {
+			headings = append(headings, heading)
+		}
+	}
+	return headings
+}
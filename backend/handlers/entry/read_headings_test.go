@@ -0,0 +1,46 @@
+package entry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadProjectHeadings(t *testing.T) {
+	var tests = []struct {
+		explanation      string
+		markdown         string
+		headingsExpected []string
+	}{
+		{
+			"returns headings in order",
+			"# Billing\n\n* Shipped the new page\n\n# Hiring\n\n* Posted a job",
+			[]string{"Billing", "Hiring"},
+		},
+		{
+			"strips markdown links",
+			"# [What Got Done](https://whatgotdone.com)\n\n* Fixed bugs",
+			[]string{"What Got Done"},
+		},
+		{
+			"ignores headings in code blocks",
+			"# Scripts\n\n```\n# not a heading\n```",
+			[]string{"Scripts"},
+		},
+		{
+			"ignores lower-level headings",
+			"# Billing\n\n## Details\n\n* Shipped",
+			[]string{"Billing"},
+		},
+		{
+			"entry without headings has no projects",
+			"* Took a nap",
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		headingsActual := ReadProjectHeadings(tt.markdown)
+		if !reflect.DeepEqual(headingsActual, tt.headingsExpected) {
+			t.Errorf("%s: got %v, want %v", tt.explanation, headingsActual, tt.headingsExpected)
+		}
+	}
+}
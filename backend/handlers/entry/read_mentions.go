@@ -0,0 +1,68 @@
+package entry
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/codefence"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+)
+
+var (
+	mentionPattern    = regexp.MustCompile(`(?:^|[^\w@/.])@(\w+)`)
+	inlineCodePattern = regexp.MustCompile("`[^`]*`")
+)
+
+// ReadMentions returns the unique usernames mentioned (e.g., @alice) in an
+// entry's markdown, in the order they first appear. Mentions inside fenced
+// code blocks or inline code don't count. Callers are responsible for checking
+// that each mention refers to a real user.
+func ReadMentions(markdown string) []string {
+	seen := map[string]bool{}
+	mentions := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(markdown))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if fence := codefence.Opening(line); fence != "" {
+			readUntilCodeBlockEnd(scanner, fence)
+			continue
+		}
+		line = inlineCodePattern.ReplaceAllString(line, "")
+		for _, match := range mentionPattern.FindAllStringSubmatch(line, -1) {
+			username := match[1]
+			if seen[username] {
+				continue
+			}
+			seen[username] = true
+			mentions = append(mentions, username)
+		}
+	}
+	return mentions
+}
+
+// MentionedUsers returns the users that an author mentions in an entry's
+// markdown, ignoring mentions of the author and of usernames that aren't in
+// users.
+func MentionedUsers(author string, markdown string, users []string) []string {
+	mentions := []string{}
+	for _, username := range ReadMentions(markdown) {
+		if username == author || !validate.Username(username) {
+			continue
+		}
+		if !isUser(username, users) {
+			continue
+		}
+		mentions = append(mentions, username)
+	}
+	return mentions
+}
+
+func isUser(username string, users []string) bool {
+	for _, u := range users {
+		if u == username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package entry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadMentions(t *testing.T) {
+	var tests = []struct {
+		explanation      string
+		markdown         string
+		mentionsExpected []string
+	}{
+		{
+			"finds mentions in body text",
+			"* Paired with @bob on the outage\n* @carol reviewed the design",
+			[]string{"bob", "carol"},
+		},
+		{
+			"removes duplicates and preserves order",
+			"Thanks @carol and @bob. @carol also fixed the build.",
+			[]string{"carol", "bob"},
+		},
+		{
+			"ignores trailing punctuation",
+			"Shipped it with help from @bob_smith!",
+			[]string{"bob_smith"},
+		},
+		{
+			"finds mentions in headings",
+			"# Launch with @bob\n\n* Done",
+			[]string{"bob"},
+		},
+		{
+			"ignores email addresses",
+			"Email me at alice@example.com",
+			[]string{},
+		},
+		{
+			"ignores URLs that contain @",
+			"See https://example.com/@bob for details",
+			[]string{},
+		},
+		{
+			"ignores mentions in fenced code blocks",
+			"```\n@decorator\n```\n\nThanks @bob",
+			[]string{"bob"},
+		},
+		{
+			"ignores mentions in tilde-fenced code blocks",
+			"~~~python\n@decorator\n```\n@property\n~~~\n\nThanks @bob",
+			[]string{"bob"},
+		},
+		{
+			"ignores mentions in indented code fences",
+			"  ```\n@decorator\n  ```\n\nThanks @bob",
+			[]string{"bob"},
+		},
+		{
+			"ignores mentions in inline code",
+			"Added `@Override` annotations with @bob",
+			[]string{"bob"},
+		},
+		{
+			"empty entry has no mentions",
+			"",
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		mentions := ReadMentions(tt.markdown)
+		if !reflect.DeepEqual(mentions, tt.mentionsExpected) {
+			t.Errorf("%s: got %v, want %v", tt.explanation, mentions, tt.mentionsExpected)
+		}
+	}
+}
+
+func TestMentionedUsers(t *testing.T) {
+	users := []string{"alice", "bob", "carol"}
+	mentions := MentionedUsers("alice", "Thanks @bob, @alice, @dave, and @carol", users)
+	expected := []string{"bob", "carol"}
+	if !reflect.DeepEqual(mentions, expected) {
+		t.Errorf("MentionedUsers=%v, want %v", mentions, expected)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/codefence"
 )
 
 // ProjectNotFoundError occurs when an entry does not contain the given project.
@@ -64,9 +66,9 @@ func readUntilNextHeading(scanner *bufio.Scanner) string {
 	lines := []string{}
 	for scanner.Scan() {
 		line := scanner.Text()
-		if lineHasCodeBlockDelimiter(line) {
+		if fence := codefence.Opening(line); fence != "" {
 			lines = append(lines, line)
-			lines = append(lines, readUntilCodeBlockEnd(scanner))
+			lines = append(lines, readUntilCodeBlockEnd(scanner, fence))
 			continue
 		}
 		if readHeading(line) != "" {
@@ -77,17 +79,12 @@ func readUntilNextHeading(scanner *bufio.Scanner) string {
 	return strings.Join(lines, "\n")
 }
 
-func lineHasCodeBlockDelimiter(line string) bool {
-	const codeBlockDelimiter = "```"
-	return strings.HasPrefix(line, codeBlockDelimiter)
-}
-
-func readUntilCodeBlockEnd(scanner *bufio.Scanner) string {
+func readUntilCodeBlockEnd(scanner *bufio.Scanner, fence string) string {
 	lines := []string{}
 	for scanner.Scan() {
 		line := scanner.Text()
 		lines = append(lines, line)
-		if lineHasCodeBlockDelimiter(line) {
+		if codefence.Closes(line, fence) {
 			break
 		}
 	}
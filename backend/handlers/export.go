@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+const (
+	exportFormatJSON     = "json"
+	exportFormatMarkdown = "markdown"
+)
+
+// userExport is everything that What Got Done stores about a user's own
+// writing, its readership, and the user's activity on the site.
+type userExport struct {
+	Username          string                 `json:"username"`
+	ExportedAt        string                 `json:"exportedAt"`
+	Profile           types.UserProfile      `json:"profile"`
+	Entries           []types.JournalEntry   `json:"entries"`
+	Drafts            []types.JournalEntry   `json:"drafts"`
+	ReactionsGiven    []types.EntryReaction  `json:"reactionsGiven"`
+	ReactionsReceived []types.EntryReaction  `json:"reactionsReceived"`
+	CommentsWritten   []types.EntryComment   `json:"commentsWritten"`
+	CommentsReceived  []types.EntryComment   `json:"commentsReceived"`
+	Following         []string               `json:"following"`
+	Followers         []string               `json:"followers"`
+	Teams             []teamMembershipExport `json:"teams"`
+	Notifications     []types.Notification   `json:"notifications"`
+	PageViews         []types.EntryPageViews `json:"pageViews"`
+	DailyPageViews    []types.DailyPageViews `json:"dailyPageViews"`
+}
+
+// teamMembershipExport describes a user's place in a team. Role is "invited"
+// for teams that the user has been invited to but hasn't joined.
+type teamMembershipExport struct {
+	TeamID   string `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Role     string `json:"role"`
+}
+
+const teamRoleInvited = "invited"
+
+// userMeExportGet sends the logged-in user a download of all their data, either
+// as a single JSON document or as a zip of markdown files.
+func (s defaultServer) userMeExportGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must be logged in to export your data", http.StatusForbidden)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = exportFormatJSON
+		}
+		if format != exportFormatJSON && format != exportFormatMarkdown {
+			http.Error(w, "Invalid format: must be json or markdown", http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now()
+		export, err := s.collectUserExport(username, now)
+		if err != nil {
+			log.Printf("Failed to collect export data for %s: %s", username, err)
+			http.Error(w, "Failed to export data", http.StatusInternalServerError)
+			return
+		}
+
+		filename := fmt.Sprintf("whatgotdone-%s-%s", username, now.UTC().Format("20060102"))
+		if format == exportFormatMarkdown {
+			w.Header().Set("Content-Type", "application/zip")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+			if err := writeMarkdownExport(w, export); err != nil {
+				log.Printf("Failed to write markdown export for %s: %s", username, err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		if err := json.NewEncoder(w).Encode(export); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) collectUserExport(username string, now time.Time) (userExport, error) {
+	export := userExport{
+		Username:          username,
+		ExportedAt:        now.UTC().Format(time.RFC3339),
+		ReactionsReceived: []types.EntryReaction{},
+		CommentsReceived:  []types.EntryComment{},
+		PageViews:         []types.EntryPageViews{},
+		DailyPageViews:    []types.DailyPageViews{},
+	}
+
+	profile, err := s.datastore.GetUserProfile(username)
+	if _, ok := err.(datastore.UserProfileNotFoundError); ok {
+		profile = types.UserProfile{}
+	} else if err != nil {
+		return userExport{}, err
+	}
+	export.Profile = profile
+
+	export.Entries, err = s.datastore.GetEntries(username)
+	if err != nil {
+		return userExport{}, err
+	}
+	sortEntriesByDate(export.Entries)
+
+	export.Drafts, err = s.datastore.GetDrafts(username)
+	if err != nil {
+		return userExport{}, err
+	}
+	sortEntriesByDate(export.Drafts)
+
+	given, err := s.datastore.GetReactionsByUser(username)
+	if err != nil {
+		return userExport{}, err
+	}
+	export.ReactionsGiven = []types.EntryReaction{}
+	for _, r := range given {
+		if r.Symbol != "" {
+			export.ReactionsGiven = append(export.ReactionsGiven, r)
+		}
+	}
+
+	written, err := s.datastore.GetCommentsByUser(username)
+	if err != nil {
+		return userExport{}, err
+	}
+	export.CommentsWritten = withoutDeletedComments(written)
+
+	export.Following, err = s.datastore.GetFollowing(username)
+	if err != nil {
+		return userExport{}, err
+	}
+	sort.Strings(export.Following)
+	export.Followers, err = s.datastore.GetFollowers(username)
+	if err != nil {
+		return userExport{}, err
+	}
+	sort.Strings(export.Followers)
+
+	export.Teams, err = s.collectTeamMemberships(username)
+	if err != nil {
+		return userExport{}, err
+	}
+
+	export.Notifications, err = s.datastore.GetNotifications(username)
+	if err != nil {
+		return userExport{}, err
+	}
+	sort.Slice(export.Notifications, func(i, j int) bool {
+		return export.Notifications[i].Timestamp < export.Notifications[j].Timestamp
+	})
+
+	for _, j := range export.Entries {
+		reactions, err := s.datastore.GetReactions(username, j.Date)
+		if err != nil {
+			return userExport{}, err
+		}
+		for _, r := range reactions {
+			// Skip reactions that readers have since cleared.
+			if r.Symbol == "" {
+				continue
+			}
+			export.ReactionsReceived = append(export.ReactionsReceived, types.EntryReaction{
+				EntryAuthor: username,
+				EntryDate:   j.Date,
+				Reaction:    r,
+			})
+		}
+
+		comments, err := s.datastore.GetComments(username, j.Date)
+		if err != nil {
+			return userExport{}, err
+		}
+		for _, c := range comments {
+			export.CommentsReceived = append(export.CommentsReceived, types.EntryComment{
+				EntryAuthor: username,
+				EntryDate:   j.Date,
+				Comment:     c,
+			})
+		}
+
+		path := "/" + username + "/" + j.Date
+		views, err := s.datastore.GetPageViews(path)
+		if _, ok := err.(datastore.PageViewsNotFoundError); ok {
+			views = 0
+		} else if err != nil {
+			return userExport{}, err
+		}
+		export.PageViews = append(export.PageViews, types.EntryPageViews{
+			Date:  j.Date,
+			Views: views,
+		})
+
+		daily, err := s.datastore.GetDailyPageViews(path, j.Date, now.UTC().Format("2006-01-02"))
+		if err != nil {
+			return userExport{}, err
+		}
+		export.DailyPageViews = append(export.DailyPageViews, daily...)
+	}
+
+	export.CommentsReceived = withoutDeletedComments(export.CommentsReceived)
+
+	return export, nil
+}
+
+func (s defaultServer) collectTeamMemberships(username string) ([]teamMembershipExport, error) {
+	teams, err := s.datastore.GetTeamsForUser(username)
+	if err != nil {
+		return nil, err
+	}
+	memberships := []teamMembershipExport{}
+	for _, t := range teams {
+		role := teamRoleInvited
+		if m, ok := t.Member(username); ok {
+			role = string(m.Role)
+		}
+		memberships = append(memberships, teamMembershipExport{
+			TeamID:   t.ID,
+			TeamName: t.Name,
+			Role:     role,
+		})
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].TeamID < memberships[j].TeamID
+	})
+	return memberships, nil
+}
+
+// withoutDeletedComments skips comments that their authors deleted, which
+// remain in storage only as placeholders in their threads.
+func withoutDeletedComments(comments []types.EntryComment) []types.EntryComment {
+	filtered := []types.EntryComment{}
+	for _, c := range comments {
+		if !c.Deleted {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+func sortEntriesByDate(entries []types.JournalEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Date < entries[j].Date
+	})
+}
+
+// writeMarkdownExport writes a zip archive with one markdown file per week's
+// entry and draft. Each file starts with YAML front matter that holds the
+// entry's metadata. The archive also includes the complete export as JSON,
+// since some of the data doesn't belong to any one week.
+func writeMarkdownExport(w io.Writer, export userExport) error {
+	zw := zip.NewWriter(w)
+
+	pageViews := map[string]int{}
+	for _, pv := range export.PageViews {
+		pageViews[pv.Date] = pv.Views
+	}
+	reactions := map[string][]types.Reaction{}
+	for _, r := range export.ReactionsReceived {
+		reactions[r.EntryDate] = append(reactions[r.EntryDate], r.Reaction)
+	}
+
+	for _, j := range export.Entries {
+		f, err := zw.Create("entries/" + j.Date + ".md")
+		if err != nil {
+			return err
+		}
+		fm := entryFrontMatter(export.Username, j)
+		fm = append(fm, fmt.Sprintf("pageViews: %d", pageViews[j.Date]))
+		fm = append(fm, reactionsFrontMatter(reactions[j.Date])...)
+		if err := writeMarkdownFile(f, fm, j.Markdown); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range export.Drafts {
+		f, err := zw.Create("drafts/" + d.Date + ".md")
+		if err != nil {
+			return err
+		}
+		fm := entryFrontMatter(export.Username, d)
+		fm = append(fm, "draft: true")
+		if err := writeMarkdownFile(f, fm, d.Markdown); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("export.json")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(export); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func entryFrontMatter(author string, j types.JournalEntry) []string {
+	fm := []string{
+		"author: " + yamlString(author),
+		"date: " + yamlString(j.Date),
+		"lastModified: " + yamlString(j.LastModified),
+	}
+	if j.Visibility != types.VisibilityDefault {
+		fm = append(fm, "visibility: "+yamlString(string(j.Visibility)))
+	}
+	if j.TeamID != "" {
+		fm = append(fm, "teamId: "+yamlString(j.TeamID))
+	}
+	return fm
+}
+
+func reactionsFrontMatter(reactions []types.Reaction) []string {
+	if len(reactions) == 0 {
+		return []string{"reactions: []"}
+	}
+	fm := []string{"reactions:"}
+	for _, r := range reactions {
+		fm = append(fm,
+			"  - username: "+yamlString(r.Username),
+			"    symbol: "+yamlString(r.Symbol),
+			"    timestamp: "+yamlString(r.Timestamp))
+	}
+	return fm
+}
+
+func writeMarkdownFile(w io.Writer, frontMatter []string, markdown string) error {
+	_, err := fmt.Fprintf(w, "---\n%s\n---\n\n%s\n", strings.Join(frontMatter, "\n"), strings.TrimRight(markdown, "\n"))
+	return err
+}
+
+// yamlString quotes a string for YAML front matter. Go's escape sequences are
+// all valid in YAML's double-quoted scalars.
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
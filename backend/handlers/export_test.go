@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func newExportTestDatastore() mockDatastore {
+	return mockDatastore{
+		userProfile: types.UserProfile{AboutMarkdown: "I like lighthouses", TwitterHandle: "alice"},
+		journalEntries: []types.JournalEntry{
+			{Date: "2019-11-29", LastModified: "2019-11-29T12:00:00Z", Markdown: "# Wrote a \"short\" story\n\n* It's about a lighthouse\n", Visibility: types.VisibilityPublic},
+			{Date: "2019-11-22", LastModified: "2019-11-22T12:00:00Z", Markdown: "Repainted the kitchen"},
+		},
+		journalDrafts: []types.JournalEntry{
+			{Date: "2019-12-06", LastModified: "2019-12-04T09:00:00Z", Markdown: "Still writing"},
+		},
+		reactions: []types.Reaction{
+			{Username: "bob", Symbol: "🎉", Timestamp: "2019-11-29T14:00:00Z"},
+			{Username: "carol", Symbol: "", Timestamp: "2019-11-29T15:00:00Z"},
+		},
+		givenReactions: []types.EntryReaction{
+			{EntryAuthor: "bob", EntryDate: "2019-11-29", Reaction: types.Reaction{Username: "alice", Symbol: "👍", Timestamp: "2019-11-30T10:00:00Z"}},
+			{EntryAuthor: "carol", EntryDate: "2019-11-29", Reaction: types.Reaction{Username: "alice", Symbol: "", Timestamp: "2019-11-30T11:00:00Z"}},
+			{EntryAuthor: "alice", EntryDate: "2019-11-29", Reaction: types.Reaction{Username: "bob", Symbol: "🎉", Timestamp: "2019-11-29T14:00:00Z"}},
+		},
+		comments: []types.Comment{
+			{ID: "comment-1", Username: "bob", Markdown: "Love the lighthouse", Timestamp: "2019-11-29T16:00:00Z"},
+			{ID: "comment-2", Username: "carol", Timestamp: "2019-11-29T17:00:00Z", Deleted: true},
+		},
+		givenComments: []types.EntryComment{
+			{EntryAuthor: "bob", EntryDate: "2019-11-29", Comment: types.Comment{ID: "comment-3", Username: "alice", Markdown: "Congrats!", Timestamp: "2019-11-30T09:00:00Z"}},
+		},
+		following: map[string][]string{
+			"alice": {"bob"},
+			"carol": {"alice"},
+		},
+		teams: map[string]types.Team{
+			"eng":    {ID: "eng", Name: "Engineering", Members: []types.TeamMember{{Username: "alice", Role: types.TeamRoleAdmin}}},
+			"design": {ID: "design", Name: "Design", Members: []types.TeamMember{{Username: "bob", Role: types.TeamRoleAdmin}}, Invitees: []string{"alice"}},
+			"sales":  {ID: "sales", Name: "Sales", Members: []types.TeamMember{{Username: "bob", Role: types.TeamRoleAdmin}}},
+		},
+		notifications: map[string]types.Notification{
+			"n-1": {ID: "n-1", Recipient: "alice", Type: types.NotificationReaction, Actor: "bob", EntryAuthor: "alice", EntryDate: "2019-11-29", Symbol: "🎉", Timestamp: "2019-11-29T14:00:00Z", Read: true},
+			"n-2": {ID: "n-2", Recipient: "alice", Type: types.NotificationMention, Actor: "bob", EntryAuthor: "bob", EntryDate: "2019-12-06", Timestamp: "2019-12-06T10:00:00Z"},
+			"n-3": {ID: "n-3", Recipient: "bob", Type: types.NotificationReaction, Actor: "alice", EntryAuthor: "bob", EntryDate: "2019-11-29", Symbol: "👍", Timestamp: "2019-11-30T10:00:00Z"},
+		},
+		pageViewCounts: []analytics.PageViewCount{
+			{Path: "/alice/2019-11-29", Views: 42},
+			{Path: "/alice/2019-11-22", Views: 7},
+		},
+		dailyPageViews: []types.DailyPageViews{
+			{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 30},
+			{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 12},
+		},
+	}
+}
+
+func TestUserMeExportJSON(t *testing.T) {
+	ds := newExportTestDatastore()
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, http.MethodGet, "/api/user/me/export", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !strings.HasSuffix(w.Header().Get("Content-Disposition"), `.json"`) {
+		t.Errorf("unexpected Content-Disposition: %s", w.Header().Get("Content-Disposition"))
+	}
+	var export userExport
+	if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+		t.Fatalf("response is not valid JSON: %s", w.Body.String())
+	}
+
+	if export.Username != "alice" || export.Profile.AboutMarkdown != "I like lighthouses" {
+		t.Errorf("unexpected user data: %+v", export)
+	}
+	if len(export.Entries) != 2 || export.Entries[0].Date != "2019-11-22" || export.Entries[1].Date != "2019-11-29" {
+		t.Errorf("expected entries sorted by date, got %+v", export.Entries)
+	}
+	if len(export.Drafts) != 1 || export.Drafts[0].Markdown != "Still writing" {
+		t.Errorf("unexpected drafts: %+v", export.Drafts)
+	}
+	givenExpected := []types.EntryReaction{
+		{EntryAuthor: "bob", EntryDate: "2019-11-29", Reaction: types.Reaction{Username: "alice", Symbol: "👍", Timestamp: "2019-11-30T10:00:00Z"}},
+	}
+	if !reflect.DeepEqual(export.ReactionsGiven, givenExpected) {
+		t.Errorf("reactionsGiven=%+v, want %+v", export.ReactionsGiven, givenExpected)
+	}
+	// The mock returns bob's reaction for both entries. Carol's cleared reaction
+	// is left out.
+	if len(export.ReactionsReceived) != 2 || export.ReactionsReceived[0].Username != "bob" || export.ReactionsReceived[0].EntryAuthor != "alice" {
+		t.Errorf("unexpected reactionsReceived: %+v", export.ReactionsReceived)
+	}
+	if len(export.CommentsWritten) != 1 || export.CommentsWritten[0].ID != "comment-3" || export.CommentsWritten[0].EntryAuthor != "bob" {
+		t.Errorf("unexpected commentsWritten: %+v", export.CommentsWritten)
+	}
+	// The mock returns bob's comment for both entries. Carol's deleted comment
+	// is left out.
+	if len(export.CommentsReceived) != 2 || export.CommentsReceived[0].ID != "comment-1" || export.CommentsReceived[0].EntryAuthor != "alice" {
+		t.Errorf("unexpected commentsReceived: %+v", export.CommentsReceived)
+	}
+	if !reflect.DeepEqual(export.Following, []string{"bob"}) || !reflect.DeepEqual(export.Followers, []string{"carol"}) {
+		t.Errorf("following=%v, followers=%v, want [bob] and [carol]", export.Following, export.Followers)
+	}
+	teamsExpected := []teamMembershipExport{
+		{TeamID: "design", TeamName: "Design", Role: "invited"},
+		{TeamID: "eng", TeamName: "Engineering", Role: "admin"},
+	}
+	if !reflect.DeepEqual(export.Teams, teamsExpected) {
+		t.Errorf("teams=%+v, want %+v", export.Teams, teamsExpected)
+	}
+	// Notification history includes notifications that alice already read.
+	if len(export.Notifications) != 2 || export.Notifications[0].ID != "n-1" || export.Notifications[1].ID != "n-2" {
+		t.Errorf("unexpected notifications: %+v", export.Notifications)
+	}
+	pageViewsExpected := []types.EntryPageViews{
+		{Date: "2019-11-22", Views: 7},
+		{Date: "2019-11-29", Views: 42},
+	}
+	if !reflect.DeepEqual(export.PageViews, pageViewsExpected) {
+		t.Errorf("pageViews=%+v, want %+v", export.PageViews, pageViewsExpected)
+	}
+	if len(export.DailyPageViews) != 2 {
+		t.Errorf("unexpected dailyPageViews: %+v", export.DailyPageViews)
+	}
+}
+
+func TestUserMeExportMarkdown(t *testing.T) {
+	ds := newExportTestDatastore()
+	ds.reactions = ds.reactions[:1]
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, http.MethodGet, "/api/user/me/export?format=markdown", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "application/zip" {
+		t.Errorf("unexpected Content-Type: %s", w.Header().Get("Content-Type"))
+	}
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("response is not a valid zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(contents)
+	}
+
+	entryExpected := `---
+author: "alice"
+date: "2019-11-29"
+lastModified: "2019-11-29T12:00:00Z"
+visibility: "public"
+pageViews: 42
+reactions:
+  - username: "bob"
+    symbol: "🎉"
+    timestamp: "2019-11-29T14:00:00Z"
+---
+
+# Wrote a "short" story
+
+* It's about a lighthouse
+`
+	if files["entries/2019-11-29.md"] != entryExpected {
+		t.Errorf("got entry file:\n%s\nwant:\n%s", files["entries/2019-11-29.md"], entryExpected)
+	}
+	draftExpected := `---
+author: "alice"
+date: "2019-12-06"
+lastModified: "2019-12-04T09:00:00Z"
+draft: true
+---
+
+Still writing
+`
+	if files["drafts/2019-12-06.md"] != draftExpected {
+		t.Errorf("got draft file:\n%s\nwant:\n%s", files["drafts/2019-12-06.md"], draftExpected)
+	}
+	if _, ok := files["entries/2019-11-22.md"]; !ok {
+		t.Errorf("expected a file for every entry, got %d files", len(files))
+	}
+	var export userExport
+	if err := json.Unmarshal([]byte(files["export.json"]), &export); err != nil || export.Username != "alice" {
+		t.Errorf("expected export.json with the complete export, got %s", files["export.json"])
+	}
+}
+
+func TestUserMeExportRejectsInvalidRequests(t *testing.T) {
+	ds := newExportTestDatastore()
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, http.MethodGet, "/api/user/me/export", "", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("logged out: status=%d, want %d", w.Code, http.StatusForbidden)
+	}
+	w = sendTeamsRequest(s, http.MethodGet, "/api/user/me/export?format=pdf", "mock_token_A", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid format: status=%d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
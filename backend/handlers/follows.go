@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+func (s defaultServer) followOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) followPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to follow users", http.StatusForbidden)
+			return
+		}
+
+		followee, err := usernameFromRequestPath(r)
+		if err != nil {
+			log.Printf("Failed to retrieve username from request path: %s", err)
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+		if followee == username {
+			http.Error(w, "You can't follow yourself", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.datastore.Follow(username, followee); err != nil {
+			log.Printf("Failed to add %s as a follower of %s: %s", username, followee, err)
+			http.Error(w, "Failed to follow user", http.StatusInternalServerError)
+			return
+		}
+
+		type followResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(followResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) followDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to unfollow users", http.StatusForbidden)
+			return
+		}
+
+		followee, err := usernameFromRequestPath(r)
+		if err != nil {
+			log.Printf("Failed to retrieve username from request path: %s", err)
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.datastore.Unfollow(username, followee); err != nil {
+			log.Printf("Failed to remove %s as a follower of %s: %s", username, followee, err)
+			http.Error(w, "Failed to unfollow user", http.StatusInternalServerError)
+			return
+		}
+
+		type unfollowResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(unfollowResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) userMeFollowingGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to see who you follow", http.StatusForbidden)
+			return
+		}
+
+		following, err := s.datastore.GetFollowing(username)
+		if err != nil {
+			log.Printf("Failed to retrieve users that %s follows: %s", username, err)
+			http.Error(w, "Failed to retrieve followed users", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(following); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// feedGet returns recent entries from the users that the logged-in user
+// follows, in the same order as the global recent entries list. Like that
+// list, it skips entries too short to be relevant.
+func (s defaultServer) feedGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to see your feed", http.StatusForbidden)
+			return
+		}
+
+		start, err := parseStart(r.URL.Query().Get("start"))
+		if err != nil {
+			http.Error(w, "Invalid start parameter", http.StatusBadRequest)
+			return
+		}
+		limit, err := parseLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+
+		following, err := s.datastore.GetFollowing(username)
+		if err != nil {
+			log.Printf("Failed to retrieve users that %s follows: %s", username, err)
+			http.Error(w, "Failed to retrieve feed", http.StatusInternalServerError)
+			return
+		}
+
+		entries := []recentEntry{}
+		for _, followee := range following {
+			userEntries, err := getVisibleEntries(s.datastore, username, followee)
+			if err != nil {
+				log.Printf("Failed to retrieve entries for user %s: %s", followee, err)
+				http.Error(w, "Failed to retrieve feed", http.StatusInternalServerError)
+				return
+			}
+			for _, entry := range userEntries {
+				if len(entry.Markdown) < minimumRelevantLength {
+					continue
+				}
+				entries = append(entries, recentEntry{
+					Author:       followee,
+					Date:         entry.Date,
+					lastModified: entry.LastModified,
+					Markdown:     entry.Markdown,
+				})
+			}
+		}
+
+		entries = paginateRecentEntries(sortRecentEntries(entries), start, limit)
+
+		if err := json.NewEncoder(w).Encode(entries); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetFollowing(username string) ([]string, error) {
+	following, ok := ds.following[username]
+	if !ok {
+		return []string{}, nil
+	}
+	return following, nil
+}
+
+func (ds mockDatastore) GetFollowers(username string) ([]string, error) {
+	followers := []string{}
+	for follower, following := range ds.following {
+		if isStringInSlice(username, following) {
+			followers = append(followers, follower)
+		}
+	}
+	return followers, nil
+}
+
+func (ds *mockDatastore) Follow(follower string, followee string) error {
+	if ds.following == nil {
+		ds.following = map[string][]string{}
+	}
+	if !isStringInSlice(followee, ds.following[follower]) {
+		ds.following[follower] = append(ds.following[follower], followee)
+	}
+	return nil
+}
+
+func (ds *mockDatastore) Unfollow(follower string, followee string) error {
+	ds.following[follower] = removeString(ds.following[follower], followee)
+	return nil
+}
+
+func TestFollowAndUnfollow(t *testing.T) {
+	ds := mockDatastore{}
+	s := newTeamsTestServer(&ds)
+
+	steps := []struct {
+		explanation        string
+		method             string
+		path               string
+		authToken          string
+		httpStatusExpected int
+		followingExpected  []string
+	}{
+		{"anonymous users can't follow", "POST", "/api/follow/bob", "", http.StatusForbidden, nil},
+		{"users can't follow themselves", "POST", "/api/follow/alice", "mock_token_A", http.StatusBadRequest, nil},
+		{"rejects invalid username", "POST", "/api/follow/undefined", "mock_token_A", http.StatusBadRequest, nil},
+		{"alice follows bob", "POST", "/api/follow/bob", "mock_token_A", http.StatusOK, []string{"bob"}},
+		{"following is idempotent", "POST", "/api/follow/bob", "mock_token_A", http.StatusOK, []string{"bob"}},
+		{"alice follows carol", "POST", "/api/follow/carol", "mock_token_A", http.StatusOK, []string{"bob", "carol"}},
+		{"alice unfollows bob", "DELETE", "/api/follow/bob", "mock_token_A", http.StatusOK, []string{"carol"}},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, step.method, step.path, step.authToken, "")
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				step.explanation, status, step.httpStatusExpected)
+		}
+		if step.followingExpected == nil {
+			continue
+		}
+
+		w = sendTeamsRequest(s, "GET", "/api/user/me/following", step.authToken, "")
+		var response []string
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if !reflect.DeepEqual(response, step.followingExpected) {
+			t.Fatalf("%s: Unexpected following list: got %v want %v", step.explanation, response, step.followingExpected)
+		}
+	}
+}
+
+func TestFeedGetReturnsEntriesFromFollowedUsersOnly(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-15", LastModified: "2019-11-15", Markdown: "Shipped"},
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22", Markdown: "Wrote the spec for the billing page"},
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29", Markdown: "Shipped the new billing page to everyone"},
+		},
+		users: []string{"alice", "bob", "carol"},
+		following: map[string][]string{
+			"alice": []string{"bob"},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, "GET", "/api/feed?start=0&limit=10", "", "")
+	if status := w.Code; status != http.StatusForbidden {
+		t.Fatalf("anonymous request returned wrong status code: got %v want %v",
+			status, http.StatusForbidden)
+	}
+
+	w = sendTeamsRequest(s, "GET", "/api/feed?start=0&limit=10", "mock_token_A", "")
+	if status := w.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var response []recentEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+
+	// The feed filters out short entries, just like the global recent list.
+	expected := []recentEntry{
+		recentEntry{Author: "bob", Date: "2019-11-29", Markdown: "Shipped the new billing page to everyone"},
+		recentEntry{Author: "bob", Date: "2019-11-22", Markdown: "Wrote the spec for the billing page"},
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Fatalf("Unexpected response: got %v want %v", response, expected)
+	}
+
+	w = sendTeamsRequest(s, "GET", "/api/feed?start=1&limit=5", "mock_token_A", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if !reflect.DeepEqual(response, expected[1:]) {
+		t.Fatalf("Unexpected paginated response: got %v want %v", response, expected[1:])
+	}
+}
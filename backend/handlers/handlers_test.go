@@ -5,8 +5,8 @@ import (
 	"os"
 	"path"
 
+	"github.com/mtlynch/whatgotdone/backend/analytics"
 	"github.com/mtlynch/whatgotdone/backend/datastore"
-	ga "github.com/mtlynch/whatgotdone/backend/google_analytics"
 	"github.com/mtlynch/whatgotdone/backend/types"
 )
 
@@ -15,14 +15,36 @@ type mockDatastore struct {
 	journalDrafts  []types.JournalEntry
 	users          []string
 	reactions      []types.Reaction
-	pageViewCounts []ga.PageViewCount
+	givenReactions []types.EntryReaction
+	pageViewCounts []analytics.PageViewCount
+	dailyPageViews []types.DailyPageViews
+	pageViewSync   *types.PageViewSync
 	userProfile    types.UserProfile
+	teams          map[string]types.Team
+	following      map[string][]string
+	reminders      map[string]types.ReminderPreferences
+	digests        map[string]types.DigestSubscription
+	notifications  map[string]types.Notification
+	notifyPrefs    map[string]types.NotificationPreferences
+	webhooks       []types.Webhook
+	deliveries     []types.WebhookDelivery
+	slack          map[string]types.SlackIntegration
+	comments       []types.Comment
+	givenComments  []types.EntryComment
+	userStats      map[string]types.UserStats
+	trending       *types.TrendingEntries
+	jobStatuses    map[string]types.JobStatus
+	deletions      map[string]types.AccountDeletion
 }
 
 func (ds mockDatastore) Users() ([]string, error) {
 	return ds.users, nil
 }
 
+func (ds mockDatastore) AllUsernames() ([]string, error) {
+	return ds.users, nil
+}
+
 func (ds mockDatastore) GetEntries(username string) ([]types.JournalEntry, error) {
 	return ds.journalEntries, nil
 }
@@ -37,6 +59,10 @@ func (ds mockDatastore) GetDraft(username string, date string) (types.JournalEnt
 	}
 }
 
+func (ds mockDatastore) GetDrafts(username string) ([]types.JournalEntry, error) {
+	return ds.journalDrafts, nil
+}
+
 func (ds mockDatastore) InsertEntry(username string, j types.JournalEntry) error {
 	return nil
 }
@@ -45,6 +71,14 @@ func (ds mockDatastore) InsertDraft(username string, j types.JournalEntry) error
 	return nil
 }
 
+func (ds mockDatastore) DeleteEntries(username string) error {
+	return nil
+}
+
+func (ds mockDatastore) DeleteDrafts(username string) error {
+	return nil
+}
+
 func (ds mockDatastore) Close() error {
 	return nil
 }
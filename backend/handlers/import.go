@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/importer"
+)
+
+// maxImportSize is the size of the largest zip archive that a user can import.
+const maxImportSize = 20 * 1024 * 1024
+
+func (s *defaultServer) importOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+// importPost publishes past entries for the logged-in user from a zip archive
+// of markdown files in the request body. With dryRun=true, it only reports
+// what the import would publish. Entries that conflict with existing entries
+// replace them only with overwrite=true.
+func (s *defaultServer) importPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to import entries", http.StatusForbidden)
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+		if err != nil {
+			http.Error(w, "Import must be a zip archive of at most 20 MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+		files, err := importer.ReadZip(body)
+		if err != nil {
+			log.Printf("Failed to read import archive from %s: %s", username, err)
+			http.Error(w, "Import must be a valid zip archive of markdown files", http.StatusBadRequest)
+			return
+		}
+
+		plan, err := importer.Prepare(s.datastore, username, files, time.Now())
+		if err != nil {
+			log.Printf("Failed to prepare import for %s: %s", username, err)
+			http.Error(w, "Failed to import entries", http.StatusInternalServerError)
+			return
+		}
+
+		type importResponse struct {
+			importer.Plan
+			DryRun    bool `json:"dryRun"`
+			Published int  `json:"published"`
+		}
+		resp := importResponse{
+			Plan:   plan,
+			DryRun: r.URL.Query().Get("dryRun") == "true",
+		}
+
+		if len(plan.Invalid) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+		} else if !resp.DryRun {
+			resp.Published, err = importer.Publish(s.datastore, username, plan, r.URL.Query().Get("overwrite") == "true")
+			if err != nil {
+				log.Printf("Failed to import entries for %s after publishing %d: %s", username, resp.Published, err)
+				http.Error(w, "Failed to import entries", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			panic(err)
+		}
+	}
+}
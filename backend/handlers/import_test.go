@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// entryRecordingDatastore captures the entries that the server publishes.
+type entryRecordingDatastore struct {
+	*mockDatastore
+	inserted *[]types.JournalEntry
+}
+
+func (ds entryRecordingDatastore) InsertEntry(username string, j types.JournalEntry) error {
+	*ds.inserted = append(*ds.inserted, j)
+	return nil
+}
+
+func newImportTestServer(ds entryRecordingDatastore) defaultServer {
+	s := defaultServer{
+		authenticator: mockAuthenticator{
+			tokensToUsers: map[string]string{
+				"mock_token_A": "alice",
+			},
+		},
+		datastore:      ds,
+		router:         mux.NewRouter(),
+		csrfMiddleware: dummyCsrfMiddleware(),
+	}
+	s.routes()
+	return s
+}
+
+func makeImportZip(t *testing.T, files map[string]string) string {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, contents := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+type importResponseForTest struct {
+	Entries []struct {
+		Date     string `json:"date"`
+		Conflict bool   `json:"conflict"`
+	} `json:"entries"`
+	Invalid []struct {
+		File   string `json:"file"`
+		Reason string `json:"reason"`
+	} `json:"invalid"`
+	DryRun    bool `json:"dryRun"`
+	Published int  `json:"published"`
+}
+
+func TestImportPost(t *testing.T) {
+	inserted := []types.JournalEntry{}
+	ds := entryRecordingDatastore{
+		mockDatastore: &mockDatastore{
+			journalEntries: []types.JournalEntry{
+				{Date: "2019-11-22", Markdown: "Already published"},
+			},
+		},
+		inserted: &inserted,
+	}
+	s := newImportTestServer(ds)
+	archive := makeImportZip(t, map[string]string{
+		"2019-11-15.md": "Planned the offsite",
+		"2019-11-22.md": "Replacement",
+	})
+
+	w := sendTeamsRequest(s, http.MethodPost, "/api/import?dryRun=true", "mock_token_A", archive)
+	if w.Code != http.StatusOK {
+		t.Fatalf("dry run: status=%d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var preview importResponseForTest
+	if err := json.Unmarshal(w.Body.Bytes(), &preview); err != nil {
+		t.Fatalf("response is not valid JSON: %s", w.Body.String())
+	}
+	if !preview.DryRun || preview.Published != 0 || len(preview.Entries) != 2 || preview.Entries[0].Conflict || !preview.Entries[1].Conflict {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+	if len(inserted) != 0 {
+		t.Fatalf("dry run must not publish entries, got %+v", inserted)
+	}
+
+	w = sendTeamsRequest(s, http.MethodPost, "/api/import", "mock_token_A", archive)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: status=%d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var result importResponseForTest
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("response is not valid JSON: %s", w.Body.String())
+	}
+	if result.Published != 1 || len(inserted) != 1 || inserted[0].Date != "2019-11-15" {
+		t.Errorf("expected only the new entry to be published, got %d: %+v", result.Published, inserted)
+	}
+
+	inserted = inserted[:0]
+	w = sendTeamsRequest(s, http.MethodPost, "/api/import?overwrite=true", "mock_token_A", archive)
+	if w.Code != http.StatusOK {
+		t.Fatalf("overwrite: status=%d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if len(inserted) != 2 {
+		t.Errorf("expected overwrite to publish both entries, got %+v", inserted)
+	}
+}
+
+func TestImportPostRejectsInvalidImports(t *testing.T) {
+	inserted := []types.JournalEntry{}
+	ds := entryRecordingDatastore{
+		mockDatastore: &mockDatastore{},
+		inserted:      &inserted,
+	}
+	s := newImportTestServer(ds)
+
+	w := sendTeamsRequest(s, http.MethodPost, "/api/import", "", makeImportZip(t, map[string]string{"2019-11-15.md": "Planned"}))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("logged out: status=%d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w = sendTeamsRequest(s, http.MethodPost, "/api/import", "mock_token_A", "not a zip")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid zip: status=%d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = sendTeamsRequest(s, http.MethodPost, "/api/import", "mock_token_A", makeImportZip(t, map[string]string{
+		"2019-11-15.md": "Planned the offsite",
+		"2019-11-14.md": "A Thursday",
+	}))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid date: status=%d, want %d", w.Code, http.StatusBadRequest)
+	}
+	var result importResponseForTest
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("response is not valid JSON: %s", w.Body.String())
+	}
+	if len(result.Invalid) != 1 || result.Invalid[0].File != "2019-11-14.md" {
+		t.Errorf("unexpected invalid files: %+v", result.Invalid)
+	}
+	if len(inserted) != 0 {
+		t.Errorf("expected nothing to be published when any file is invalid, got %+v", inserted)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// jobsGet returns the status of the server's scheduled background jobs.
+func (s defaultServer) jobsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.canViewJobs(r) {
+			http.Error(w, "Only site admins can view background jobs", http.StatusForbidden)
+			return
+		}
+
+		statuses := []types.JobStatus{}
+		if s.scheduler != nil {
+			var err error
+			statuses, err = s.scheduler.Statuses()
+			if err != nil {
+				log.Printf("Failed to retrieve job statuses: %s", err)
+				http.Error(w, "Failed to retrieve job statuses", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		type jobsResponse struct {
+			Jobs []types.JobStatus `json:"jobs"`
+		}
+		if err := json.NewEncoder(w).Encode(jobsResponse{Jobs: statuses}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// runDueJobs starts every background job that is due and responds without
+// waiting for the jobs to finish. App Engine cron requests it every minute
+// because App Engine may stop idle instances, along with the scheduler's
+// background goroutine. Job leases keep the cron request and the in-process
+// scheduler from running the same job twice.
+func (s defaultServer) runDueJobs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Verify that the request came from a trusted job runner so that
+		// external users can't force jobs to run.
+		if !s.canRunJobs(r) {
+			http.Error(w, "Job runs must come from App Engine cron or include the job runner secret", http.StatusForbidden)
+			return
+		}
+		if s.scheduler != nil {
+			s.scheduler.StartDue(time.Now())
+		}
+		w.WriteHeader(http.StatusAccepted)
+		if err := json.NewEncoder(w).Encode(true); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// canRunJobs returns true if the request may trigger background jobs. Requests
+// qualify if they carry the job runner secret as a bearer token or, when the
+// server runs on App Engine, if they come from App Engine cron.
+func (s defaultServer) canRunJobs(r *http.Request) bool {
+	if s.jobRunnerSecret != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.jobRunnerSecret)) == 1 {
+			return true
+		}
+	}
+	return s.onAppEngine && isAppEngineCronRequest(r)
+}
+
+// isAppEngineCronRequest returns true if App Engine cron sent the request. App
+// Engine removes the X-Appengine-Cron header from requests that come from
+// outside App Engine, but other hosts don't, so only trust the header on App
+// Engine.
+func isAppEngineCronRequest(r *http.Request) bool {
+	cronHeader := r.Header.Get("X-Appengine-Cron")
+	if cronHeader != "true" {
+		log.Printf("X-Appengine-Cron=[%v]", cronHeader)
+		return false
+	}
+	return true
+}
+
+// jobRunnerSecretFromEnv reads the secret that lets job runners other than App
+// Engine cron trigger background jobs from the JOB_RUNNER_SECRET environment
+// variable.
+func jobRunnerSecretFromEnv() string {
+	return os.Getenv("JOB_RUNNER_SECRET")
+}
+
+// isAppEngineRuntime returns true if the server is running on App Engine.
+func isAppEngineRuntime() bool {
+	return os.Getenv("GAE_ENV") == "standard"
+}
@@ -0,0 +1,18 @@
+// +build !dev
+
+package handlers
+
+import (
+	"net/http"
+)
+
+// canViewJobs returns true if the request may view the status of background
+// jobs. Job statuses reveal details of the server's operation, so only site
+// admins can view them.
+func (s defaultServer) canViewJobs(r *http.Request) bool {
+	username, err := s.loggedInUser(r)
+	if err != nil {
+		return false
+	}
+	return s.isSiteAdmin(username)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/mtlynch/whatgotdone/backend/handlers/entry"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// readMentionedUsers returns the users that an author mentions in an entry's
+// markdown, ignoring mentions of the author and of usernames that don't belong
+// to any What Got Done user.
+func (s defaultServer) readMentionedUsers(author string, markdown string) ([]string, error) {
+	candidates := entry.ReadMentions(markdown)
+	if len(candidates) == 0 {
+		return []string{}, nil
+	}
+	users, err := s.datastore.Users()
+	if err != nil {
+		return nil, err
+	}
+	return entry.MentionedUsers(author, markdown, users), nil
+}
+
+// recordMentionNotifications notifies users whom an entry mentions. To avoid
+// repeat notifications when authors edit an entry, it skips users whom the
+// previous version of the entry already mentioned. It also skips users who
+// can't read the entry.
+func (s defaultServer) recordMentionNotifications(author string, j types.JournalEntry, previousMentions []string) error {
+	for _, username := range j.Mentions {
+		if isStringInSlice(username, previousMentions) {
+			continue
+		}
+		visible, err := filterVisibleEntries(s.datastore, username, author, []types.JournalEntry{j})
+		if err != nil {
+			return err
+		}
+		if len(visible) == 0 {
+			continue
+		}
+		err = s.datastore.SetNotification(types.Notification{
+			ID:          fmt.Sprintf("%s:%s:%s:%s", types.NotificationMention, username, author, j.Date),
+			Recipient:   username,
+			Type:        types.NotificationMention,
+			Actor:       author,
+			EntryAuthor: author,
+			EntryDate:   j.Date,
+			Timestamp:   j.LastModified,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func mentionRecipients(ds mockDatastore) []string {
+	recipients := []string{}
+	for _, n := range ds.notifications {
+		if n.Type == types.NotificationMention {
+			recipients = append(recipients, n.Recipient)
+		}
+	}
+	sort.Strings(recipients)
+	return recipients
+}
+
+func TestEntryPostNotifiesMentionedUsers(t *testing.T) {
+	var tests = []struct {
+		explanation        string
+		existing           []types.JournalEntry
+		body               string
+		recipientsExpected []string
+	}{
+		{
+			"notifies mentioned users who exist",
+			[]types.JournalEntry{},
+			`{"entryContent": "Paired with @bob and @carol. Thanks @nobody!"}`,
+			[]string{"bob", "carol"},
+		},
+		{
+			"ignores mentions of the author",
+			[]types.JournalEntry{},
+			`{"entryContent": "Note to @alice: finish the docs"}`,
+			[]string{},
+		},
+		{
+			"ignores mentions in code",
+			[]types.JournalEntry{},
+			"{\"entryContent\": \"Added `@bob` handling\"}",
+			[]string{},
+		},
+		{
+			"skips users whom the previous version already mentioned",
+			[]types.JournalEntry{
+				types.JournalEntry{Date: "2019-11-29", Markdown: "Paired with @bob", Mentions: []string{"bob"}},
+			},
+			`{"entryContent": "Paired with @bob and @carol"}`,
+			[]string{"carol"},
+		},
+		{
+			"skips users who can't read the entry",
+			[]types.JournalEntry{},
+			`{"entryContent": "Paired with @bob", "visibility": "private"}`,
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		ds := mockDatastore{
+			journalEntries: tt.existing,
+			users:          []string{"alice", "bob", "carol"},
+		}
+		s := newTeamsTestServer(&ds)
+
+		w := sendTeamsRequest(s, "POST", "/api/entry/2019-11-29", "mock_token_A", tt.body)
+		if status := w.Code; status != http.StatusOK {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, http.StatusOK)
+		}
+		if recipients := mentionRecipients(ds); !reflect.DeepEqual(recipients, tt.recipientsExpected) {
+			t.Fatalf("%s: got %v want %v", tt.explanation, recipients, tt.recipientsExpected)
+		}
+	}
+}
+
+func TestReadMentionedUsers(t *testing.T) {
+	ds := mockDatastore{
+		users: []string{"alice", "bob", "carol"},
+	}
+	s := defaultServer{datastore: &ds}
+
+	mentions, err := s.readMentionedUsers("alice", "Thanks @carol, @alice, @dave, and @bob")
+	if err != nil {
+		t.Fatalf("readMentionedUsers failed: %v", err)
+	}
+	expected := []string{"carol", "bob"}
+	if !reflect.DeepEqual(mentions, expected) {
+		t.Fatalf("got %v want %v", mentions, expected)
+	}
+}
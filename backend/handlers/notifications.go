@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (s defaultServer) notificationsOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) notificationsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view notifications", http.StatusForbidden)
+			return
+		}
+
+		notifications, err := s.datastore.GetUnreadNotifications(username)
+		if err != nil {
+			log.Printf("Failed to retrieve notifications for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve notifications", http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(notifications, func(i, j int) bool {
+			return notifications[i].Timestamp > notifications[j].Timestamp
+		})
+
+		if err := json.NewEncoder(w).Encode(notifications); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// notificationsReadPost marks the notifications with the given IDs as read. If
+// the request contains no IDs, it marks all of the user's notifications as
+// read.
+func (s defaultServer) notificationsReadPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to update notifications", http.StatusForbidden)
+			return
+		}
+
+		type markReadRequest struct {
+			IDs []string `json:"ids"`
+		}
+		var mrr markReadRequest
+		if err := json.NewDecoder(r.Body).Decode(&mrr); err != nil {
+			log.Printf("Invalid mark read request: %v", err)
+			http.Error(w, "Invalid mark read request", http.StatusBadRequest)
+			return
+		}
+
+		// Only look up the user's own notifications so that users can't mark
+		// other users' notifications as read.
+		unread, err := s.datastore.GetUnreadNotifications(username)
+		if err != nil {
+			log.Printf("Failed to retrieve notifications for %s: %s", username, err)
+			http.Error(w, "Failed to update notifications", http.StatusInternalServerError)
+			return
+		}
+		for _, n := range unread {
+			if len(mrr.IDs) > 0 && !isStringInSlice(n.ID, mrr.IDs) {
+				continue
+			}
+			n.Read = true
+			if err := s.datastore.SetNotification(n); err != nil {
+				log.Printf("Failed to mark notification %s as read: %s", n.ID, err)
+				http.Error(w, "Failed to update notifications", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		type markReadResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(markReadResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) notificationPreferencesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view notification settings", http.StatusForbidden)
+			return
+		}
+
+		p, err := s.datastore.GetNotificationPreferences(username)
+		if _, ok := err.(datastore.NotificationPreferencesNotFoundError); ok {
+			p = types.NotificationPreferences{EmailEnabled: false}
+		} else if err != nil {
+			log.Printf("Failed to retrieve notification preferences for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve notification settings", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(p); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) notificationPreferencesPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to update notification settings", http.StatusForbidden)
+			return
+		}
+
+		var p types.NotificationPreferences
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			log.Printf("Invalid notification preferences request: %v", err)
+			http.Error(w, "Invalid notification preferences request", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.datastore.SetNotificationPreferences(username, p); err != nil {
+			log.Printf("Failed to save notification preferences for %s: %s", username, err)
+			http.Error(w, "Failed to update notification settings", http.StatusInternalServerError)
+			return
+		}
+
+		type notificationPreferencesResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(notificationPreferencesResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// recordReactionNotification notifies an entry's author that another user
+// reacted to it. Each reader has at most one reaction notification per entry.
+// Changing a reaction updates the earlier notification's symbol without
+// notifying the author again, and clearing a reaction removes the
+// notification.
+func (s defaultServer) recordReactionNotification(reaction types.Reaction, entryAuthor string, entryDate string) error {
+	if reaction.Username == entryAuthor {
+		return nil
+	}
+	id := fmt.Sprintf("%s:%s:%s:%s", types.NotificationReaction, reaction.Username, entryAuthor, entryDate)
+	if reaction.Symbol == "" {
+		return s.datastore.DeleteNotification(id)
+	}
+
+	n := types.Notification{
+		ID:          id,
+		Recipient:   entryAuthor,
+		Type:        types.NotificationReaction,
+		Actor:       reaction.Username,
+		EntryAuthor: entryAuthor,
+		EntryDate:   entryDate,
+		Symbol:      reaction.Symbol,
+		Timestamp:   reaction.Timestamp,
+	}
+	existing, err := s.datastore.GetNotification(id)
+	if err == nil {
+		n.Read = existing.Read
+		n.Emailed = existing.Emailed
+	} else if _, ok := err.(datastore.NotificationNotFoundError); !ok {
+		return err
+	}
+	return s.datastore.SetNotification(n)
+}
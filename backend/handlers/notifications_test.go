@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetNotification(id string) (types.Notification, error) {
+	if n, ok := ds.notifications[id]; ok {
+		return n, nil
+	}
+	return types.Notification{}, datastore.NotificationNotFoundError{ID: id}
+}
+
+func (ds *mockDatastore) DeleteNotification(id string) error {
+	delete(ds.notifications, id)
+	return nil
+}
+
+func (ds *mockDatastore) SetNotification(n types.Notification) error {
+	if ds.notifications == nil {
+		ds.notifications = map[string]types.Notification{}
+	}
+	ds.notifications[n.ID] = n
+	return nil
+}
+
+func (ds mockDatastore) GetUnreadNotifications(recipient string) ([]types.Notification, error) {
+	unread := []types.Notification{}
+	for _, n := range ds.notifications {
+		if n.Recipient == recipient && !n.Read {
+			unread = append(unread, n)
+		}
+	}
+	return unread, nil
+}
+
+func (ds mockDatastore) GetNotifications(recipient string) ([]types.Notification, error) {
+	notifications := []types.Notification{}
+	for _, n := range ds.notifications {
+		if n.Recipient == recipient {
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications, nil
+}
+
+func (ds mockDatastore) GetNotificationsInvolving(username string) ([]types.Notification, error) {
+	notifications := []types.Notification{}
+	for _, n := range ds.notifications {
+		if n.Recipient == username || n.Actor == username || n.EntryAuthor == username {
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications, nil
+}
+
+func (ds mockDatastore) GetNotificationPreferences(username string) (types.NotificationPreferences, error) {
+	if p, ok := ds.notifyPrefs[username]; ok {
+		return p, nil
+	}
+	return types.NotificationPreferences{}, datastore.NotificationPreferencesNotFoundError{Username: username}
+}
+
+func (ds *mockDatastore) SetNotificationPreferences(username string, p types.NotificationPreferences) error {
+	if ds.notifyPrefs == nil {
+		ds.notifyPrefs = map[string]types.NotificationPreferences{}
+	}
+	p.Username = username
+	ds.notifyPrefs[username] = p
+	return nil
+}
+
+func (ds *mockDatastore) DeleteNotificationPreferences(username string) error {
+	delete(ds.notifyPrefs, username)
+	return nil
+}
+
+func (ds mockDatastore) GetNotificationEmailSubscribers() ([]types.NotificationPreferences, error) {
+	prefs := []types.NotificationPreferences{}
+	for _, p := range ds.notifyPrefs {
+		if p.EmailEnabled {
+			prefs = append(prefs, p)
+		}
+	}
+	return prefs, nil
+}
+
+func getNotifications(t *testing.T, s defaultServer, authToken string) []types.Notification {
+	w := sendTeamsRequest(s, "GET", "/api/notifications", authToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to retrieve notifications: got status %v", w.Code)
+	}
+	var notifications []types.Notification
+	if err := json.Unmarshal(w.Body.Bytes(), &notifications); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	return notifications
+}
+
+func TestReactionsCreateNotifications(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T20:00:00Z", Markdown: "Shipped billing"},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	reactions := []struct {
+		authToken string
+		body      string
+	}{
+		// Bob reacts to alice's entry, then changes his reaction.
+		{"mock_token_B", `{"reactionSymbol": "👍"}`},
+		{"mock_token_B", `{"reactionSymbol": "🎉"}`},
+		// Carol reacts, then clears her reaction.
+		{"mock_token_C", `{"reactionSymbol": "👍"}`},
+		{"mock_token_C", `{"reactionSymbol": ""}`},
+		// Alice reacts to her own entry.
+		{"mock_token_A", `{"reactionSymbol": "🎉"}`},
+	}
+	for _, r := range reactions {
+		w := sendTeamsRequest(s, "POST", "/api/reactions/entry/alice/2019-11-29", r.authToken, r.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("failed to add reaction: got status %v", w.Code)
+		}
+	}
+
+	notifications := getNotifications(t, s, "mock_token_A")
+	actors := []string{}
+	symbols := []string{}
+	for _, n := range notifications {
+		actors = append(actors, n.Actor)
+		symbols = append(symbols, n.Symbol)
+	}
+	// Clearing a reaction retracts its notification.
+	if len(notifications) != 1 || !reflect.DeepEqual(actors, []string{"bob"}) {
+		t.Fatalf("unexpected notifications: got %+v", notifications)
+	}
+	if !reflect.DeepEqual(symbols, []string{"🎉"}) {
+		t.Fatalf("expected bob's latest reaction to replace his earlier one: %v", symbols)
+	}
+
+	if n := getNotifications(t, s, "mock_token_B"); len(n) != 0 {
+		t.Fatalf("bob should have no notifications, got %+v", n)
+	}
+}
+
+func TestChangingReactionDoesNotRenotify(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T20:00:00Z", Markdown: "Shipped billing"},
+		},
+		notifications: map[string]types.Notification{
+			"reaction:bob:alice:2019-11-29": types.Notification{
+				ID:          "reaction:bob:alice:2019-11-29",
+				Recipient:   "alice",
+				Type:        types.NotificationReaction,
+				Actor:       "bob",
+				EntryAuthor: "alice",
+				EntryDate:   "2019-11-29",
+				Symbol:      "👍",
+				Read:        true,
+				Emailed:     true,
+			},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	if w := sendTeamsRequest(s, "POST", "/api/reactions/entry/alice/2019-11-29", "mock_token_B", `{"reactionSymbol": "🎉"}`); w.Code != http.StatusOK {
+		t.Fatalf("failed to change reaction: got status %v", w.Code)
+	}
+	n := ds.notifications["reaction:bob:alice:2019-11-29"]
+	if n.Symbol != "🎉" || !n.Read || !n.Emailed {
+		t.Fatalf("expected the notification to keep its read and emailed state, got %+v", n)
+	}
+}
+
+func TestNotificationsMarkRead(t *testing.T) {
+	ds := mockDatastore{
+		notifications: map[string]types.Notification{
+			"n1": types.Notification{ID: "n1", Recipient: "alice", Type: types.NotificationReaction, Actor: "bob", Timestamp: "2019-11-29T20:00:00Z"},
+			"n2": types.Notification{ID: "n2", Recipient: "alice", Type: types.NotificationReaction, Actor: "carol", Timestamp: "2019-11-29T21:00:00Z"},
+			"n3": types.Notification{ID: "n3", Recipient: "alice", Type: types.NotificationReaction, Actor: "dave", Timestamp: "2019-11-29T19:00:00Z"},
+			"n4": types.Notification{ID: "n4", Recipient: "bob", Type: types.NotificationReaction, Actor: "alice", Timestamp: "2019-11-29T19:00:00Z"},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	ids := func(notifications []types.Notification) []string {
+		result := []string{}
+		for _, n := range notifications {
+			result = append(result, n.ID)
+		}
+		return result
+	}
+
+	if got := ids(getNotifications(t, s, "mock_token_A")); !reflect.DeepEqual(got, []string{"n2", "n1", "n3"}) {
+		t.Fatalf("unexpected unread notifications: got %v", got)
+	}
+
+	if w := sendTeamsRequest(s, "POST", "/api/notifications/read", "", `{"ids": ["n1"]}`); w.Code != http.StatusForbidden {
+		t.Fatalf("anonymous users should not be able to mark notifications read: got %v", w.Code)
+	}
+
+	// Alice can't mark bob's notification as read.
+	if w := sendTeamsRequest(s, "POST", "/api/notifications/read", "mock_token_A", `{"ids": ["n1", "n4"]}`); w.Code != http.StatusOK {
+		t.Fatalf("failed to mark notifications read: got %v", w.Code)
+	}
+	if got := ids(getNotifications(t, s, "mock_token_A")); !reflect.DeepEqual(got, []string{"n2", "n3"}) {
+		t.Fatalf("unexpected unread notifications: got %v", got)
+	}
+	if ds.notifications["n4"].Read {
+		t.Fatalf("alice marked bob's notification as read")
+	}
+
+	// An empty list of IDs marks all notifications as read.
+	if w := sendTeamsRequest(s, "POST", "/api/notifications/read", "mock_token_A", `{}`); w.Code != http.StatusOK {
+		t.Fatalf("failed to mark notifications read: got %v", w.Code)
+	}
+	if got := ids(getNotifications(t, s, "mock_token_A")); len(got) != 0 {
+		t.Fatalf("unexpected unread notifications: got %v", got)
+	}
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	ds := mockDatastore{}
+	s := newTeamsTestServer(&ds)
+
+	if w := sendTeamsRequest(s, "POST", "/api/notifications/preferences", "", `{"emailEnabled": true}`); w.Code != http.StatusForbidden {
+		t.Fatalf("anonymous users should not be able to update preferences: got %v", w.Code)
+	}
+	if w := sendTeamsRequest(s, "POST", "/api/notifications/preferences", "mock_token_A", `{"emailEnabled": true}`); w.Code != http.StatusOK {
+		t.Fatalf("failed to update preferences: got %v", w.Code)
+	}
+
+	w := sendTeamsRequest(s, "GET", "/api/notifications/preferences", "mock_token_A", "")
+	var p types.NotificationPreferences
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if !p.EmailEnabled {
+		t.Fatalf("expected email notifications to be enabled")
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+)
+
+// countPageView records a view of the requested entry page before serving it,
+// if the server counts page views itself.
+func (s defaultServer) countPageView(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.pageViewCounter != nil {
+			if path, ok := entryPagePath(r); ok && !analytics.IsCrawler(r.UserAgent()) {
+				s.pageViewCounter.Record(path, clientIP(r), r.UserAgent(), time.Now())
+			}
+		}
+		h(w, r)
+	}
+}
+
+// entryPagePath returns the request's path if the request is for an entry
+// page, which has the form /{username}/{date}.
+func entryPagePath(r *http.Request) (string, bool) {
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) != 3 || parts[0] != "" {
+		return "", false
+	}
+	if !validate.Username(parts[1]) || !validate.EntryDate(parts[2]) {
+		return "", false
+	}
+	return r.URL.Path, true
+}
+
+// clientIP returns the IP address of the client that made the request. The
+// server trusts forwarding headers only when the request comes from a proxy
+// on a loopback or private network address, such as App Engine's frontend or a
+// local reverse proxy, since any client can send them. App Engine sets
+// X-Appengine-User-IP to the client's address, and other proxies append the
+// address they received the request from to X-Forwarded-For.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isKnownProxy(host) {
+		return host
+	}
+	if userIP := strings.TrimSpace(r.Header.Get("X-Appengine-User-IP")); userIP != "" {
+		return userIP
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		return strings.TrimSpace(hops[len(hops)-1])
+	}
+	return host
+}
+
+var proxyNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("fc00::/7"),
+}
+
+// isKnownProxy returns true if the address belongs to a proxy that the server
+// can trust to set forwarding headers.
+func isKnownProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return true
+	}
+	for _, n := range proxyNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/pageviews"
+)
+
+// pageViewRecordingDatastore captures the page view totals that the counter
+// saves.
+type pageViewRecordingDatastore struct {
+	*mockDatastore
+	saved map[string]int
+}
+
+func (ds pageViewRecordingDatastore) GetPageViews(path string) (int, error) {
+	if views, ok := ds.saved[path]; ok {
+		return views, nil
+	}
+	return 0, datastore.PageViewsNotFoundError{Path: path}
+}
+
+func (ds pageViewRecordingDatastore) InsertPageViews(path string, pageViews int) error {
+	ds.saved[path] = pageViews
+	return nil
+}
+
+func (ds pageViewRecordingDatastore) IncrementPageViews(path string, n int) error {
+	ds.saved[path] += n
+	return nil
+}
+
+func TestCountPageView(t *testing.T) {
+	ds := pageViewRecordingDatastore{
+		mockDatastore: &mockDatastore{
+			users: []string{"alice"},
+		},
+		saved: map[string]int{},
+	}
+	counter := pageviews.New(ds)
+	s := defaultServer{
+		datastore:       ds,
+		pageViewCounter: counter,
+	}
+	served := 0
+	h := s.countPageView(func(w http.ResponseWriter, r *http.Request) {
+		served++
+	})
+
+	for _, tt := range []struct {
+		path          string
+		userAgent     string
+		forwardedFor  string
+		remoteAddress string
+	}{
+		{"/alice/2019-11-29", "Mozilla/5.0 Firefox", "", "203.0.113.1:4000"},
+		{"/alice/2019-11-29", "Mozilla/5.0 Firefox", "", "203.0.113.1:4001"},
+		{"/alice/2019-11-29", "Mozilla/5.0 Firefox", "198.51.100.7, 10.0.0.1", "10.0.0.2:4000"},
+		{"/alice/2019-11-29", "Googlebot/2.1", "", "203.0.113.2:4000"},
+		{"/alice/2019-11-29", "", "", "203.0.113.3:4000"},
+		{"/alice/2019-11-28", "Mozilla/5.0 Firefox", "", "203.0.113.4:4000"},
+		{"/alice/2019-11-29/extra", "Mozilla/5.0 Firefox", "", "203.0.113.5:4000"},
+		{"/js/app.js", "Mozilla/5.0 Firefox", "", "203.0.113.6:4000"},
+	} {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.RemoteAddr = tt.remoteAddress
+		req.Header.Set("User-Agent", tt.userAgent)
+		if tt.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+		h(httptest.NewRecorder(), req)
+	}
+
+	if served != 8 {
+		t.Fatalf("expected every request to be served, got %d", served)
+	}
+	if err := counter.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	expected := map[string]int{
+		"/alice/2019-11-29": 2,
+	}
+	if !reflect.DeepEqual(ds.saved, expected) {
+		t.Fatalf("unexpected page views: got %v want %v", ds.saved, expected)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		remoteAddress string
+		headers       map[string]string
+		ipExpected    string
+	}{
+		{
+			"direct request uses the remote address",
+			"203.0.113.1:4000",
+			map[string]string{},
+			"203.0.113.1",
+		},
+		{
+			"direct request ignores forwarding headers",
+			"203.0.113.1:4000",
+			map[string]string{"X-Forwarded-For": "198.51.100.7", "X-Appengine-User-IP": "198.51.100.8"},
+			"203.0.113.1",
+		},
+		{
+			"proxied request uses the App Engine user IP",
+			"169.254.1.1:4000",
+			map[string]string{"X-Forwarded-For": "198.51.100.7, 198.51.100.9", "X-Appengine-User-IP": "198.51.100.9"},
+			"198.51.100.9",
+		},
+		{
+			"proxied request uses the last X-Forwarded-For hop",
+			"10.0.0.2:4000",
+			map[string]string{"X-Forwarded-For": "198.51.100.7, 198.51.100.9"},
+			"198.51.100.9",
+		},
+		{
+			"proxied request without forwarding headers uses the proxy's address",
+			"127.0.0.1:4000",
+			map[string]string{},
+			"127.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/alice/2019-11-29", nil)
+		req.RemoteAddr = tt.remoteAddress
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		if ipActual := clientIP(req); ipActual != tt.ipExpected {
+			t.Errorf("%s: got %s, want %s", tt.explanation, ipActual, tt.ipExpected)
+		}
+	}
+}
+
+func TestCountPageViewWithoutCounter(t *testing.T) {
+	s := defaultServer{}
+	served := false
+	h := s.countPageView(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})
+	h(httptest.NewRecorder(), httptest.NewRequest("GET", "/alice/2019-11-29", nil))
+	if !served {
+		t.Fatalf("expected request to be served")
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// maxPageViewSeriesDays is the longest date range a client can request in a
+// page view time series.
+const maxPageViewSeriesDays = 5 * 366
+
+type pageViewBucket struct {
+	// Start is the first day of the bucket in YYYY-MM-DD format.
+	Start string `json:"start"`
+	Views int    `json:"views"`
+}
+
+type pageViewSeriesResponse struct {
+	Path        string           `json:"path"`
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Granularity string           `json:"granularity"`
+	Views       int              `json:"views"`
+	Series      []pageViewBucket `json:"series"`
+}
+
+// pageViewSeriesGet responds with the page views of an entry over time. The
+// from and to query parameters bound the range in YYYY-MM-DD format and
+// default to the entry's date and the current day. The granularity query
+// parameter can be day (the default), week, or month. Weeks start on Monday.
+func (s defaultServer) pageViewSeriesGet(w http.ResponseWriter, r *http.Request, path string, entryDate string) {
+	q := r.URL.Query()
+	from, to, err := parsePageViewRange(q.Get("from"), q.Get("to"), entryDate, time.Now())
+	if err != nil {
+		log.Printf("Invalid page view range: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	granularity := q.Get("granularity")
+	if granularity == "" {
+		granularity = "day"
+	}
+	bucketStart, ok := pageViewBucketStarts[granularity]
+	if !ok {
+		http.Error(w, "Invalid granularity: must be day, week, or month", http.StatusBadRequest)
+		return
+	}
+
+	daily, err := s.datastore.GetDailyPageViews(path, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		log.Printf("Failed to retrieve daily pageviews for %s: %v", path, err)
+		http.Error(w, "Failed to retrieve pageviews", http.StatusInternalServerError)
+		return
+	}
+
+	resp := pageViewSeriesResponse{
+		Path:        path,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Granularity: granularity,
+		Series:      []pageViewBucket{},
+	}
+	// Include empty buckets so that clients can plot the series directly.
+	index := map[string]int{}
+	for d := bucketStart(from); !d.After(to); d = nextPageViewBucket(granularity, d) {
+		index[d.Format("2006-01-02")] = len(resp.Series)
+		resp.Series = append(resp.Series, pageViewBucket{Start: d.Format("2006-01-02")})
+	}
+	for _, pv := range daily {
+		d, err := time.Parse("2006-01-02", pv.Date)
+		if err != nil {
+			log.Printf("Ignoring pageviews with invalid date: %+v", pv)
+			continue
+		}
+		i, ok := index[bucketStart(d).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		resp.Series[i].Views += pv.Views
+		resp.Views += pv.Views
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		panic(err)
+	}
+}
+
+func parsePageViewRange(fromParam string, toParam string, entryDate string, now time.Time) (time.Time, time.Time, error) {
+	if fromParam == "" {
+		fromParam = entryDate
+	}
+	from, err := time.Parse("2006-01-02", fromParam)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid from date: must be YYYY-MM-DD")
+	}
+	now = now.UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toParam != "" {
+		to, err = time.Parse("2006-01-02", toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid to date: must be YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("Invalid date range: from must not be after to")
+	}
+	if to.Sub(from) > maxPageViewSeriesDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("Invalid date range: range is too long")
+	}
+	return from, to, nil
+}
+
+var pageViewBucketStarts = map[string]func(time.Time) time.Time{
+	"day": func(t time.Time) time.Time {
+		return t
+	},
+	"week": func(t time.Time) time.Time {
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -daysSinceMonday)
+	},
+	"month": func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	},
+}
+
+func nextPageViewBucket(granularity string, start time.Time) time.Time {
+	switch granularity {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetDailyPageViews(path string, from string, to string) ([]types.DailyPageViews, error) {
+	daily := []types.DailyPageViews{}
+	for _, pv := range ds.dailyPageViews {
+		if pv.Path == path && pv.Date >= from && pv.Date <= to {
+			daily = append(daily, pv)
+		}
+	}
+	return daily, nil
+}
+
+func (ds *mockDatastore) InsertDailyPageViews(pv types.DailyPageViews) error {
+	for i := range ds.dailyPageViews {
+		if ds.dailyPageViews[i].Path == pv.Path && ds.dailyPageViews[i].Date == pv.Date {
+			ds.dailyPageViews[i] = pv
+			return nil
+		}
+	}
+	ds.dailyPageViews = append(ds.dailyPageViews, pv)
+	return nil
+}
+
+func (ds *mockDatastore) IncrementDailyPageViews(path string, date string, n int) error {
+	for i := range ds.dailyPageViews {
+		if ds.dailyPageViews[i].Path == path && ds.dailyPageViews[i].Date == date {
+			ds.dailyPageViews[i].Views += n
+			return nil
+		}
+	}
+	ds.dailyPageViews = append(ds.dailyPageViews, types.DailyPageViews{Path: path, Date: date, Views: n})
+	return nil
+}
+
+type mockAnalyticsSource struct {
+	daily []analytics.DailyPageViewCount
+	err   error
+	// ranges records the date ranges of each request.
+	ranges [][2]string
+}
+
+func (f *mockAnalyticsSource) DailyPageViewsByPath(startDate, endDate string) ([]analytics.DailyPageViewCount, error) {
+	f.ranges = append(f.ranges, [2]string{startDate, endDate})
+	if f.err != nil {
+		return nil, f.err
+	}
+	daily := []analytics.DailyPageViewCount{}
+	for _, d := range f.daily {
+		if d.Date >= startDate && d.Date <= endDate {
+			daily = append(daily, d)
+		}
+	}
+	return daily, nil
+}
+
+func TestPageViewsGetSeries(t *testing.T) {
+	ds := mockDatastore{
+		users: []string{"alice"},
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", Markdown: "Shipped the feature"},
+		},
+		dailyPageViews: []types.DailyPageViews{
+			types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 5},
+			types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 3},
+			types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2019-12-02", Views: 2},
+			types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2020-01-03", Views: 1},
+			types.DailyPageViews{Path: "/alice/2019-11-22", Date: "2019-11-30", Views: 7},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	var tests = []struct {
+		explanation      string
+		query            string
+		responseExpected pageViewSeriesResponse
+	}{
+		{
+			"daily series includes empty days",
+			"&from=2019-11-29&to=2019-12-02",
+			pageViewSeriesResponse{
+				Path: "/alice/2019-11-29", From: "2019-11-29", To: "2019-12-02", Granularity: "day", Views: 10,
+				Series: []pageViewBucket{
+					{Start: "2019-11-29", Views: 5},
+					{Start: "2019-11-30", Views: 3},
+					{Start: "2019-12-01", Views: 0},
+					{Start: "2019-12-02", Views: 2},
+				},
+			},
+		},
+		{
+			"weekly series starts on Mondays",
+			"&from=2019-11-29&to=2019-12-08&granularity=week",
+			pageViewSeriesResponse{
+				Path: "/alice/2019-11-29", From: "2019-11-29", To: "2019-12-08", Granularity: "week", Views: 10,
+				Series: []pageViewBucket{
+					{Start: "2019-11-25", Views: 8},
+					{Start: "2019-12-02", Views: 2},
+				},
+			},
+		},
+		{
+			"monthly series defaults from to entry date",
+			"&to=2020-01-31&granularity=month",
+			pageViewSeriesResponse{
+				Path: "/alice/2019-11-29", From: "2019-11-29", To: "2020-01-31", Granularity: "month", Views: 11,
+				Series: []pageViewBucket{
+					{Start: "2019-11-01", Views: 8},
+					{Start: "2019-12-01", Views: 2},
+					{Start: "2020-01-01", Views: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		w := sendTeamsRequest(s, "GET", "/api/pageViews?path=/alice/2019-11-29"+tt.query, "", "")
+		if status := w.Code; status != http.StatusOK {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, http.StatusOK)
+		}
+		var response pageViewSeriesResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if !reflect.DeepEqual(response, tt.responseExpected) {
+			t.Fatalf("%s: got %+v want %+v", tt.explanation, response, tt.responseExpected)
+		}
+	}
+}
+
+func TestPageViewsGetSeriesRejectsInvalidParameters(t *testing.T) {
+	ds := mockDatastore{
+		users: []string{"alice"},
+	}
+	s := newTeamsTestServer(&ds)
+
+	for _, tt := range []struct {
+		explanation string
+		query       string
+	}{
+		{"invalid from date", "&from=2019-11"},
+		{"invalid to date", "&to=yesterday"},
+		{"from after to", "&from=2019-12-06&to=2019-11-29"},
+		{"range too long", "&from=2019-11-29&to=2029-11-29"},
+		{"invalid granularity", "&granularity=hour"},
+	} {
+		w := sendTeamsRequest(s, "GET", "/api/pageViews?path=/alice/2019-11-29"+tt.query, "", "")
+		if status := w.Code; status != http.StatusBadRequest {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestParsePageViewRangeDefaultsToToday(t *testing.T) {
+	now := time.Date(2019, 12, 3, 22, 15, 0, 0, time.UTC)
+	from, to, err := parsePageViewRange("", "", "2019-11-29", now)
+	if err != nil {
+		t.Fatalf("parsePageViewRange failed: %v", err)
+	}
+	if from.Format("2006-01-02") != "2019-11-29" || to.Format("2006-01-02") != "2019-12-03" {
+		t.Fatalf("unexpected range: %v - %v", from, to)
+	}
+}
+
+func TestSyncPageViewsStoresDailyViews(t *testing.T) {
+	ds := mockDatastore{
+		users: []string{"alice"},
+	}
+	source := mockAnalyticsSource{
+		daily: []analytics.DailyPageViewCount{
+			analytics.DailyPageViewCount{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 4},
+			analytics.DailyPageViewCount{Path: "/alice/2019-11-29?utm_source=twitter", Date: "2019-11-29", Views: 1},
+			analytics.DailyPageViewCount{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 2},
+			analytics.DailyPageViewCount{Path: "/privacy-policy", Date: "2019-11-30", Views: 9},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+	s.analyticsSource = &source
+
+	if err := s.syncPageViews(time.Now()); err != nil {
+		t.Fatalf("syncPageViews failed: %v", err)
+	}
+
+	sort.Slice(ds.dailyPageViews, func(i, j int) bool {
+		return ds.dailyPageViews[i].Date < ds.dailyPageViews[j].Date
+	})
+	expected := []types.DailyPageViews{
+		types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2019-11-29", Views: 5},
+		types.DailyPageViews{Path: "/alice/2019-11-29", Date: "2019-11-30", Views: 2},
+	}
+	if !reflect.DeepEqual(ds.dailyPageViews, expected) {
+		t.Fatalf("unexpected daily page views: got %+v want %+v", ds.dailyPageViews, expected)
+	}
+}
@@ -25,7 +25,7 @@ func (s *defaultServer) projectGet() http.HandlerFunc {
 			return
 		}
 
-		entries, err := s.datastore.GetEntries(username)
+		entries, err := getVisibleEntries(s.datastore, s.viewerFromRequest(r), username)
 		if err != nil {
 			log.Printf("Failed to retrieve entries: %s", err)
 			http.Error(w, fmt.Sprintf("Failed to retrieve entries for %s", username), http.StatusInternalServerError)
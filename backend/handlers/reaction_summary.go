@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// maxReactionSummaryBatchSize is the maximum number of entries a client can
+// request in a single batch of reaction summaries.
+const maxReactionSummaryBatchSize = 50
+
+// reactionSummary aggregates the reactions to a single entry.
+type reactionSummary struct {
+	// Counts maps each reaction symbol to the number of readers who reacted
+	// with it.
+	Counts map[string]int `json:"counts"`
+	// MyReaction is the logged-in user's reaction to the entry or an empty
+	// string if they haven't reacted.
+	MyReaction string `json:"myReaction"`
+}
+
+// entryKey identifies a single entry in a batch request.
+type entryKey struct {
+	author string
+	date   string
+}
+
+func (k entryKey) String() string {
+	return k.author + "/" + k.date
+}
+
+// reactionSummaryGet returns reaction counts for a single entry along with the
+// logged-in user's reaction.
+func (s defaultServer) reactionSummaryGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		date, err := dateFromRequestPath(r)
+		if err != nil {
+			log.Printf("Invalid date: %s - %s", date, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		entryAuthor, err := usernameFromRequestPath(r)
+		if err != nil {
+			log.Printf("Failed to retrieve username from request path: %s", err)
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		viewer := s.viewerFromRequest(r)
+		hidden, err := isEntryHidden(s.datastore, viewer, entryAuthor, date)
+		if err != nil {
+			log.Printf("Failed to check entry visibility: %s", err)
+			http.Error(w, "Failed to retrieve reactions", http.StatusInternalServerError)
+			return
+		}
+		if hidden {
+			http.Error(w, "Entry not found", http.StatusNotFound)
+			return
+		}
+
+		reactions, err := s.datastore.GetReactions(entryAuthor, date)
+		if err != nil {
+			log.Printf("Failed to retrieve reactions: %s", err)
+			http.Error(w, "Failed to retrieve reactions", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(summarizeReactions(reactions, viewer)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// reactionSummariesGet returns reaction summaries for several entries at once.
+// The entries query parameter is a comma-separated list of entries in
+// username/YYYY-MM-DD format. The response maps each entry to its summary and
+// omits entries that the logged-in user can't read.
+func (s defaultServer) reactionSummariesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys, err := parseEntryKeys(r.URL.Query().Get("entries"))
+		if err != nil {
+			log.Printf("Invalid entries parameter: %s", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		viewer := s.viewerFromRequest(r)
+		hidden, err := hiddenEntryKeys(s.datastore, viewer, keys)
+		if err != nil {
+			log.Printf("Failed to check entry visibility: %s", err)
+			http.Error(w, "Failed to retrieve reactions", http.StatusInternalServerError)
+			return
+		}
+
+		summaries := map[string]reactionSummary{}
+		for _, k := range keys {
+			if hidden[k] {
+				continue
+			}
+			reactions, err := s.datastore.GetReactions(k.author, k.date)
+			if err != nil {
+				log.Printf("Failed to retrieve reactions for %s: %s", k, err)
+				http.Error(w, "Failed to retrieve reactions", http.StatusInternalServerError)
+				return
+			}
+			summaries[k.String()] = summarizeReactions(reactions, viewer)
+		}
+
+		if err := json.NewEncoder(w).Encode(summaries); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// summarizeReactions counts the reactions to an entry by symbol, ignoring
+// reactions that readers have cleared.
+func summarizeReactions(reactions []types.Reaction, viewer string) reactionSummary {
+	summary := reactionSummary{
+		Counts: map[string]int{},
+	}
+	for _, reaction := range withoutClearedReactions(reactions) {
+		summary.Counts[reaction.Symbol]++
+		if viewer != anonymousViewer && reaction.Username == viewer {
+			summary.MyReaction = reaction.Symbol
+		}
+	}
+	return summary
+}
+
+// withoutClearedReactions filters out reactions that readers have cleared.
+func withoutClearedReactions(reactions []types.Reaction) []types.Reaction {
+	filtered := []types.Reaction{}
+	for _, reaction := range reactions {
+		if reaction.Symbol != "" {
+			filtered = append(filtered, reaction)
+		}
+	}
+	return filtered
+}
+
+func parseEntryKeys(raw string) ([]entryKey, error) {
+	if raw == "" {
+		return nil, errors.New("Request is missing required parameter: entries")
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxReactionSummaryBatchSize {
+		return nil, fmt.Errorf("Too many entries: limit is %d", maxReactionSummaryBatchSize)
+	}
+	keys := []entryKey{}
+	seen := map[entryKey]bool{}
+	for _, part := range parts {
+		fields := strings.Split(part, "/")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("Invalid entry: %s", part)
+		}
+		k := entryKey{author: fields[0], date: fields[1]}
+		if !validate.Username(k.author) || !isValidDateParameter(k.date) {
+			return nil, fmt.Errorf("Invalid entry: %s", part)
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// hiddenEntryKeys returns the set of entries that exist but that the viewer
+// is not allowed to read. It retrieves each author's entries at most once.
+func hiddenEntryKeys(ds datastore.Datastore, viewer string, keys []entryKey) (map[entryKey]bool, error) {
+	byAuthor := map[string][]string{}
+	for _, k := range keys {
+		byAuthor[k.author] = append(byAuthor[k.author], k.date)
+	}
+	hidden := map[entryKey]bool{}
+	for author, dates := range byAuthor {
+		entries, err := ds.GetEntries(author)
+		if err != nil {
+			return nil, err
+		}
+		visible, err := filterVisibleEntries(ds, viewer, author, entries)
+		if err != nil {
+			return nil, err
+		}
+		for _, date := range dates {
+			if _, exists := findEntryByDate(entries, date); !exists {
+				continue
+			}
+			if _, ok := findEntryByDate(visible, date); !ok {
+				hidden[entryKey{author: author, date: date}] = true
+			}
+		}
+	}
+	return hidden, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func newReactionSummaryTestData() mockDatastore {
+	return mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-22", Markdown: "Wrote the spec"},
+			types.JournalEntry{Date: "2019-11-29", Markdown: "Shipped the feature", Visibility: types.VisibilityPrivate},
+		},
+		reactions: []types.Reaction{
+			types.Reaction{Username: "bob", Symbol: "👍", Timestamp: "2019-11-22T12:00:00Z"},
+			types.Reaction{Username: "carol", Symbol: "👍", Timestamp: "2019-11-22T13:00:00Z"},
+			types.Reaction{Username: "dave", Symbol: "🎉", Timestamp: "2019-11-22T14:00:00Z"},
+			types.Reaction{Username: "erin", Symbol: "", Timestamp: "2019-11-22T15:00:00Z"},
+		},
+	}
+}
+
+func TestReactionSummaryGet(t *testing.T) {
+	ds := newReactionSummaryTestData()
+	s := newTeamsTestServer(&ds)
+
+	var tests = []struct {
+		explanation        string
+		path               string
+		authToken          string
+		httpStatusExpected int
+		summaryExpected    reactionSummary
+	}{
+		{
+			"anonymous users see counts without their own reaction",
+			"/api/reactions/summary/entry/alice/2019-11-22",
+			"",
+			http.StatusOK,
+			reactionSummary{Counts: map[string]int{"👍": 2, "🎉": 1}},
+		},
+		{
+			"logged-in users see their own reaction",
+			"/api/reactions/summary/entry/alice/2019-11-22",
+			"mock_token_B",
+			http.StatusOK,
+			reactionSummary{Counts: map[string]int{"👍": 2, "🎉": 1}, MyReaction: "👍"},
+		},
+		{
+			"hidden entries return not found",
+			"/api/reactions/summary/entry/alice/2019-11-29",
+			"mock_token_B",
+			http.StatusNotFound,
+			reactionSummary{},
+		},
+		{
+			"rejects invalid date",
+			"/api/reactions/summary/entry/alice/2019-11-2",
+			"",
+			http.StatusBadRequest,
+			reactionSummary{},
+		},
+	}
+	for _, tt := range tests {
+		w := sendTeamsRequest(s, "GET", tt.path, tt.authToken, "")
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+		if tt.httpStatusExpected != http.StatusOK {
+			continue
+		}
+		var response reactionSummary
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if !reflect.DeepEqual(response, tt.summaryExpected) {
+			t.Fatalf("%s: got %+v want %+v", tt.explanation, response, tt.summaryExpected)
+		}
+	}
+}
+
+func TestReactionSummariesGet(t *testing.T) {
+	ds := newReactionSummaryTestData()
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, "GET", "/api/reactions/summary?entries=alice/2019-11-22,alice/2019-11-29,bob/2019-11-22,alice/2019-11-22", "mock_token_C", "")
+	if status := w.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var response map[string]reactionSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	// The mock datastore returns the same entries and reactions for every
+	// author, so bob's entry has the same summary as alice's.
+	expected := map[string]reactionSummary{
+		"alice/2019-11-22": reactionSummary{Counts: map[string]int{"👍": 2, "🎉": 1}, MyReaction: "👍"},
+		"bob/2019-11-22":   reactionSummary{Counts: map[string]int{"👍": 2, "🎉": 1}, MyReaction: "👍"},
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Fatalf("Unexpected response: got %+v want %+v", response, expected)
+	}
+}
+
+func TestReactionSummariesGetRejectsInvalidRequests(t *testing.T) {
+	ds := newReactionSummaryTestData()
+	s := newTeamsTestServer(&ds)
+
+	tooMany := "alice/2019-11-22"
+	for i := 0; i < maxReactionSummaryBatchSize; i++ {
+		tooMany += ",alice/2019-11-22"
+	}
+	for _, tt := range []struct {
+		explanation string
+		entries     string
+	}{
+		{"missing entries parameter", ""},
+		{"entry without date", "alice"},
+		{"entry with invalid date", "alice/2019-11"},
+		{"entry with invalid username", "undefined/2019-11-22"},
+		{"too many entries", tooMany},
+	} {
+		w := sendTeamsRequest(s, "GET", "/api/reactions/summary?entries="+tt.entries, "", "")
+		if status := w.Code; status != http.StatusBadRequest {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// defaultReactionSymbols are the reactions readers can choose from when the
+// site doesn't configure its own set.
+var defaultReactionSymbols = []string{"👍", "🙁", "🎉"}
+
+// reactionSymbolsFromEnv reads the site-wide reaction set from the
+// REACTION_SYMBOLS environment variable, a comma-separated list of symbols. If
+// the variable is unset or invalid, it returns the default reaction set.
+func reactionSymbolsFromEnv() []string {
+	raw := os.Getenv("REACTION_SYMBOLS")
+	if raw == "" {
+		return defaultReactionSymbols
+	}
+	symbols := []string{}
+	for _, s := range strings.Split(raw, ",") {
+		symbols = append(symbols, strings.TrimSpace(s))
+	}
+	if !validate.ReactionSymbols(symbols) {
+		log.Printf("Invalid REACTION_SYMBOLS value [%s], using default reaction symbols", raw)
+		return defaultReactionSymbols
+	}
+	return symbols
+}
+
+// siteReactionSymbols returns the reaction symbols that apply to entries that
+// don't belong to a team with its own reaction set.
+func (s defaultServer) siteReactionSymbols() []string {
+	if len(s.reactionSymbols) == 0 {
+		return defaultReactionSymbols
+	}
+	return s.reactionSymbols
+}
+
+// reactionSymbolsForTeam returns the reaction symbols that apply to entries
+// shared with the given team.
+func (s defaultServer) reactionSymbolsForTeam(teamID string) ([]string, error) {
+	if teamID == "" {
+		return s.siteReactionSymbols(), nil
+	}
+	team, err := s.datastore.GetTeam(teamID)
+	if _, ok := err.(datastore.TeamNotFoundError); ok {
+		return s.siteReactionSymbols(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(team.ReactionSymbols) == 0 {
+		return s.siteReactionSymbols(), nil
+	}
+	return team.ReactionSymbols, nil
+}
+
+// reactionSymbolsForEntry returns the reaction symbols that readers can choose
+// from when reacting to the given entry.
+func (s defaultServer) reactionSymbolsForEntry(author string, date string) ([]string, error) {
+	entries, err := s.datastore.GetEntries(author)
+	if err != nil {
+		return nil, err
+	}
+	j, ok := findEntryByDate(entries, date)
+	if !ok {
+		return s.siteReactionSymbols(), nil
+	}
+	return s.reactionSymbolsForTeam(j.TeamID)
+}
+
+// reactionSymbolsGet returns the reaction symbols clients should offer. If the
+// request specifies a teamId query parameter, it returns the reaction symbols
+// for entries shared with that team.
+func (s defaultServer) reactionSymbolsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		teamID := r.URL.Query().Get("teamId")
+		if teamID != "" {
+			if !validate.TeamID(teamID) {
+				http.Error(w, "Invalid team ID", http.StatusBadRequest)
+				return
+			}
+			isMember, err := teamMembershipChecker{ds: s.datastore, teams: map[string]types.Team{}}.isMember(teamID, s.viewerFromRequest(r))
+			if err != nil {
+				log.Printf("Failed to check membership in team %s: %s", teamID, err)
+				http.Error(w, "Failed to retrieve reaction symbols", http.StatusInternalServerError)
+				return
+			}
+			if !isMember {
+				http.Error(w, "Team not found", http.StatusNotFound)
+				return
+			}
+		}
+
+		symbols, err := s.reactionSymbolsForTeam(teamID)
+		if err != nil {
+			log.Printf("Failed to retrieve reaction symbols for team %s: %s", teamID, err)
+			http.Error(w, "Failed to retrieve reaction symbols", http.StatusInternalServerError)
+			return
+		}
+
+		type reactionSymbolsResponse struct {
+			Symbols []string `json:"symbols"`
+		}
+		if err := json.NewEncoder(w).Encode(reactionSymbolsResponse{Symbols: symbols}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamReactionSymbolsPost overrides the reaction symbols for entries shared
+// with a team. An empty list of symbols restores the site's reaction symbols.
+func (s defaultServer) teamReactionSymbolsPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to change team reactions", http.StatusForbidden)
+			return
+		}
+
+		type reactionSymbolsRequest struct {
+			Symbols []string `json:"symbols"`
+		}
+		var rr reactionSymbolsRequest
+		if err := json.NewDecoder(r.Body).Decode(&rr); err != nil {
+			log.Printf("Failed to decode request: %s", err)
+			http.Error(w, "Failed to decode request", http.StatusBadRequest)
+			return
+		}
+		if len(rr.Symbols) > 0 && !validate.ReactionSymbols(rr.Symbols) {
+			http.Error(w, "Invalid reaction symbols", http.StatusBadRequest)
+			return
+		}
+
+		team, ok := s.updateTeam(w, r, func(team *types.Team) error {
+			if !team.IsAdmin(username) {
+				return teamChangeError{http.StatusForbidden, "Only team admins can change team reactions"}
+			}
+			team.ReactionSymbols = rr.Symbols
+			return nil
+		})
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(team); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func newReactionSymbolsTestData() mockDatastore {
+	return mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-22", Markdown: "Wrote the spec"},
+			types.JournalEntry{Date: "2019-11-29", Markdown: "Shipped the feature", Visibility: types.VisibilityTeam, TeamID: "acme"},
+		},
+		teams: map[string]types.Team{
+			"acme": types.Team{
+				ID: "acme",
+				Members: []types.TeamMember{
+					types.TeamMember{Username: "alice", Role: types.TeamRoleAdmin},
+					types.TeamMember{Username: "bob", Role: types.TeamRoleMember},
+				},
+			},
+		},
+	}
+}
+
+func TestReactionSymbolsGet(t *testing.T) {
+	ds := newReactionSymbolsTestData()
+	team := ds.teams["acme"]
+	team.ReactionSymbols = []string{"🚀", "👀"}
+	ds.teams["acme"] = team
+	s := defaultServer{
+		authenticator: mockAuthenticator{
+			tokensToUsers: map[string]string{
+				"mock_token_A": "alice",
+				"mock_token_C": "carol",
+			},
+		},
+		datastore:       &ds,
+		router:          mux.NewRouter(),
+		csrfMiddleware:  dummyCsrfMiddleware(),
+		reactionSymbols: []string{"👍", "❤️"},
+	}
+	s.routes()
+
+	var tests = []struct {
+		explanation        string
+		path               string
+		authToken          string
+		httpStatusExpected int
+		symbolsExpected    []string
+	}{
+		{"returns site reaction set", "/api/reactions/symbols", "", http.StatusOK, []string{"👍", "❤️"}},
+		{"returns team reaction set to members", "/api/reactions/symbols?teamId=acme", "mock_token_A", http.StatusOK, []string{"🚀", "👀"}},
+		{"hides team from non-members", "/api/reactions/symbols?teamId=acme", "mock_token_C", http.StatusNotFound, nil},
+		{"hides team from anonymous users", "/api/reactions/symbols?teamId=acme", "", http.StatusNotFound, nil},
+		{"rejects invalid team ID", "/api/reactions/symbols?teamId=Acme!", "mock_token_A", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		w := sendTeamsRequest(s, "GET", tt.path, tt.authToken, "")
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+		if tt.httpStatusExpected != http.StatusOK {
+			continue
+		}
+		var response struct {
+			Symbols []string `json:"symbols"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if !reflect.DeepEqual(response.Symbols, tt.symbolsExpected) {
+			t.Fatalf("%s: got %v want %v", tt.explanation, response.Symbols, tt.symbolsExpected)
+		}
+	}
+}
+
+func TestReactionsPostEnforcesActiveReactionSet(t *testing.T) {
+	ds := newReactionSymbolsTestData()
+	s := newTeamsTestServer(&ds)
+
+	steps := []struct {
+		explanation        string
+		method             string
+		path               string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"accepts symbol from site set", "POST", "/api/reactions/entry/alice/2019-11-22", "mock_token_B", `{"reactionSymbol": "🎉"}`, http.StatusOK},
+		{"rejects symbol outside site set", "POST", "/api/reactions/entry/alice/2019-11-22", "mock_token_B", `{"reactionSymbol": "🚀"}`, http.StatusBadRequest},
+		{"non-admins can't change team reactions", "POST", "/api/teams/acme/reactions", "mock_token_B", `{"symbols": ["🚀"]}`, http.StatusForbidden},
+		{"rejects invalid team reaction set", "POST", "/api/teams/acme/reactions", "mock_token_A", `{"symbols": ["🚀", "🚀"]}`, http.StatusBadRequest},
+		{"admin overrides team reactions", "POST", "/api/teams/acme/reactions", "mock_token_A", `{"symbols": ["🚀", "👀"]}`, http.StatusOK},
+		{"accepts symbol from team set", "POST", "/api/reactions/entry/alice/2019-11-29", "mock_token_B", `{"reactionSymbol": "🚀"}`, http.StatusOK},
+		{"rejects site symbol outside team set", "POST", "/api/reactions/entry/alice/2019-11-29", "mock_token_B", `{"reactionSymbol": "🎉"}`, http.StatusBadRequest},
+		{"team override doesn't apply to other entries", "POST", "/api/reactions/entry/alice/2019-11-22", "mock_token_B", `{"reactionSymbol": "🚀"}`, http.StatusBadRequest},
+		{"readers can always clear their reaction", "POST", "/api/reactions/entry/alice/2019-11-29", "mock_token_B", `{"reactionSymbol": ""}`, http.StatusOK},
+		{"admin restores site reactions", "POST", "/api/teams/acme/reactions", "mock_token_A", `{"symbols": []}`, http.StatusOK},
+		{"accepts site symbol after override is removed", "POST", "/api/reactions/entry/alice/2019-11-29", "mock_token_B", `{"reactionSymbol": "🎉"}`, http.StatusOK},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, step.method, step.path, step.authToken, step.body)
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				step.explanation, status, step.httpStatusExpected)
+		}
+	}
+}
+
+func TestReactionsGetPreservesRetiredSymbols(t *testing.T) {
+	ds := newReactionSymbolsTestData()
+	ds.reactions = []types.Reaction{
+		types.Reaction{Username: "bob", Symbol: "🚀", Timestamp: "2019-11-22T12:00:00Z"},
+	}
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, "GET", "/api/reactions/entry/alice/2019-11-22", "", "")
+	var response []types.Reaction
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if !reflect.DeepEqual(response, ds.reactions) {
+		t.Fatalf("Unexpected response: got %v want %v", response, ds.reactions)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
 	"github.com/mtlynch/whatgotdone/backend/types"
 )
 
@@ -31,21 +32,25 @@ func (s defaultServer) reactionsGet() http.HandlerFunc {
 			return
 		}
 
-		reactions, err := s.datastore.GetReactions(entryAuthor, date)
+		hidden, err := isEntryHidden(s.datastore, s.viewerFromRequest(r), entryAuthor, date)
 		if err != nil {
-			log.Printf("Failed to retrieve reactions: %s", err)
+			log.Printf("Failed to check entry visibility: %s", err)
 			http.Error(w, "Failed to retrieve reactions", http.StatusInternalServerError)
 			return
 		}
+		if hidden {
+			http.Error(w, "Entry not found", http.StatusNotFound)
+			return
+		}
 
-		reactionsFiltered := []types.Reaction{}
-		for _, reaction := range reactions {
-			if reaction.Symbol != "" {
-				reactionsFiltered = append(reactionsFiltered, reaction)
-			}
+		reactions, err := s.datastore.GetReactions(entryAuthor, date)
+		if err != nil {
+			log.Printf("Failed to retrieve reactions: %s", err)
+			http.Error(w, "Failed to retrieve reactions", http.StatusInternalServerError)
+			return
 		}
 
-		if err := json.NewEncoder(w).Encode(reactionsFiltered); err != nil {
+		if err := json.NewEncoder(w).Encode(withoutClearedReactions(reactions)); err != nil {
 			panic(err)
 		}
 	}
@@ -80,6 +85,33 @@ func (s defaultServer) reactionsPost() http.HandlerFunc {
 			return
 		}
 
+		hidden, err := isEntryHidden(s.datastore, username, entryAuthor, entryDate)
+		if err != nil {
+			log.Printf("Failed to check entry visibility: %s", err)
+			http.Error(w, "Failed to add reaction", http.StatusInternalServerError)
+			return
+		}
+		if hidden {
+			http.Error(w, "Entry not found", http.StatusNotFound)
+			return
+		}
+
+		// Readers can always clear their reaction, even if the entry's reaction
+		// set no longer includes it.
+		if reactionSymbol != "" {
+			symbols, err := s.reactionSymbolsForEntry(entryAuthor, entryDate)
+			if err != nil {
+				log.Printf("Failed to retrieve reaction symbols for %s/%s: %s", entryAuthor, entryDate, err)
+				http.Error(w, "Failed to add reaction", http.StatusInternalServerError)
+				return
+			}
+			if !isStringInSlice(reactionSymbol, symbols) {
+				log.Printf("Invalid reaction choice for %s/%s: %s", entryAuthor, entryDate, reactionSymbol)
+				http.Error(w, "Invalid reaction choice", http.StatusBadRequest)
+				return
+			}
+		}
+
 		if reactionSymbol != "" {
 			log.Printf("Adding reaction %s -> [%s] for %s/%s", username, reactionSymbol, entryAuthor, entryDate)
 		} else {
@@ -98,6 +130,21 @@ func (s defaultServer) reactionsPost() http.HandlerFunc {
 			return
 		}
 
+		// The reaction is already saved, so a notification failure shouldn't
+		// fail the request.
+		if err := s.recordReactionNotification(reaction, entryAuthor, entryDate); err != nil {
+			log.Printf("Failed to record reaction notification for %s/%s: %s", entryAuthor, entryDate, err)
+		}
+		if reactionSymbol != "" {
+			s.dispatchWebhook(entryAuthor, types.WebhookReactionAdded, reactionEventData{
+				EntryAuthor: entryAuthor,
+				EntryDate:   entryDate,
+				Username:    username,
+				Symbol:      reactionSymbol,
+				Timestamp:   reaction.Timestamp,
+			})
+		}
+
 		type reactionResponse struct {
 			Ok bool `json:"ok"`
 		}
@@ -126,19 +173,9 @@ func reactionSymbolFromRequest(r *http.Request) (string, error) {
 	}
 
 	reactionSymbol := *rr.ReactionSymbol
-	if !isValidReaction(reactionSymbol) {
+	if reactionSymbol != "" && !validate.ReactionSymbol(reactionSymbol) {
 		return "", fmt.Errorf("Invalid reaction choice: %s", reactionSymbol)
 	}
 
 	return reactionSymbol, nil
 }
-
-func isValidReaction(reaction string) bool {
-	validReactionSymbols := [...]string{"", "👍", "🙁", "🎉"}
-	for _, v := range validReactionSymbols {
-		if reaction == v {
-			return true
-		}
-	}
-	return false
-}
@@ -17,11 +17,25 @@ func (ds mockDatastore) GetReactions(entryAuthor string, entryDate string) ([]ty
 	return ds.reactions, nil
 }
 
+func (ds mockDatastore) GetReactionsByUser(username string) ([]types.EntryReaction, error) {
+	reactions := []types.EntryReaction{}
+	for _, r := range ds.givenReactions {
+		if r.Username == username {
+			reactions = append(reactions, r)
+		}
+	}
+	return reactions, nil
+}
+
 func (ds *mockDatastore) AddReaction(entryAuthor string, entryDate string, reaction types.Reaction) error {
 	ds.reactions = append(ds.reactions, reaction)
 	return nil
 }
 
+func (ds mockDatastore) DeleteReaction(entryAuthor string, entryDate string, username string) error {
+	return nil
+}
+
 // Create a dummy CSRF middleware that never rejects HTTP requests.
 func dummyCsrfMiddleware() httpMiddlewareHandler {
 	return func(h http.Handler) http.Handler {
@@ -9,6 +9,11 @@ import (
 	"strconv"
 )
 
+// minimumRelevantLength is the length below which entries are too short to
+// feature in lists of entries from all users. This filters low-effort posts or
+// test posts.
+const minimumRelevantLength = 30
+
 type recentEntry struct {
 	Author string `json:"author"`
 	Date   string `json:"date"`
@@ -37,17 +42,16 @@ func (s *defaultServer) recentEntriesGet() http.HandlerFunc {
 			return
 		}
 
+		viewer := s.viewerFromRequest(r)
 		entries := []recentEntry{}
 		for _, username := range users {
-			userEntries, err := s.datastore.GetEntries(username)
+			userEntries, err := getVisibleEntries(s.datastore, viewer, username)
 			if err != nil {
 				log.Printf("Failed to retrieve entries for user %s: %s", username, err)
 				http.Error(w, "Failed to retrieve users", http.StatusInternalServerError)
 				return
 			}
 			for _, entry := range userEntries {
-				// Filter low-effort posts or test posts from the recent list.
-				const minimumRelevantLength = 30
 				if len(entry.Markdown) < minimumRelevantLength {
 					continue
 				}
@@ -60,25 +64,7 @@ func (s *defaultServer) recentEntriesGet() http.HandlerFunc {
 			}
 		}
 
-		sort.Slice(entries, func(i, j int) bool {
-			if entries[i].Date < entries[j].Date {
-				return true
-			}
-			if entries[i].Date > entries[j].Date {
-				return false
-			}
-			return entries[i].lastModified < entries[j].lastModified
-		})
-
-		// Reverse the order of entries.
-		for i := len(entries)/2 - 1; i >= 0; i-- {
-			opp := len(entries) - 1 - i
-			entries[i], entries[opp] = entries[opp], entries[i]
-		}
-
-		start = min(len(entries), start)
-		end := min(len(entries), start+limit)
-		entries = entries[start:end]
+		entries = paginateRecentEntries(sortRecentEntries(entries), start, limit)
 
 		if err := json.NewEncoder(w).Encode(entries); err != nil {
 			panic(err)
@@ -86,6 +72,33 @@ func (s *defaultServer) recentEntriesGet() http.HandlerFunc {
 	}
 }
 
+// sortRecentEntries sorts entries so that the newest entry dates come first.
+// Entries with the same date are ordered by most recently modified first.
+func sortRecentEntries(entries []recentEntry) []recentEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Date < entries[j].Date {
+			return true
+		}
+		if entries[i].Date > entries[j].Date {
+			return false
+		}
+		return entries[i].lastModified < entries[j].lastModified
+	})
+
+	// Reverse the order of entries.
+	for i := len(entries)/2 - 1; i >= 0; i-- {
+		opp := len(entries) - 1 - i
+		entries[i], entries[opp] = entries[opp], entries[i]
+	}
+	return entries
+}
+
+func paginateRecentEntries(entries []recentEntry, start, limit int) []recentEntry {
+	start = min(len(entries), start)
+	end := min(len(entries), start+limit)
+	return entries[start:end]
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
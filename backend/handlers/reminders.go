@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/reminders"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (s defaultServer) remindersOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) remindersGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view your reminder settings", http.StatusForbidden)
+			return
+		}
+
+		p, err := getReminderPreferences(s.datastore, username)
+		if err != nil {
+			log.Printf("Failed to retrieve reminder preferences for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve reminder settings", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(p); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) remindersPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to update your reminder settings", http.StatusForbidden)
+			return
+		}
+
+		type remindersRequest struct {
+			Enabled  bool   `json:"enabled"`
+			SendTime string `json:"sendTime"`
+			TimeZone string `json:"timeZone"`
+		}
+		var rr remindersRequest
+		if err := json.NewDecoder(r.Body).Decode(&rr); err != nil {
+			log.Printf("Invalid reminders request: %v", err)
+			http.Error(w, "Invalid reminders request", http.StatusBadRequest)
+			return
+		}
+		if !validate.ReminderTime(rr.SendTime) {
+			http.Error(w, "Invalid send time: must be HH:MM", http.StatusBadRequest)
+			return
+		}
+		if !validate.TimeZone(rr.TimeZone) {
+			http.Error(w, "Invalid time zone", http.StatusBadRequest)
+			return
+		}
+
+		p, err := getReminderPreferences(s.datastore, username)
+		if err != nil {
+			log.Printf("Failed to retrieve reminder preferences for %s: %s", username, err)
+			http.Error(w, "Failed to update reminder settings", http.StatusInternalServerError)
+			return
+		}
+		// Preserve LastSentFor so that changing settings on a Friday doesn't
+		// trigger a second reminder for the same week.
+		p.Enabled = rr.Enabled
+		p.SendTime = rr.SendTime
+		p.TimeZone = rr.TimeZone
+
+		if err := s.datastore.SetReminderPreferences(username, p); err != nil {
+			log.Printf("Failed to save reminder preferences for %s: %s", username, err)
+			http.Error(w, "Failed to update reminder settings", http.StatusInternalServerError)
+			return
+		}
+
+		type remindersResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(remindersResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// getReminderPreferences retrieves a user's reminder settings, falling back to
+// the defaults if the user has never saved any.
+func getReminderPreferences(ds datastore.Datastore, username string) (types.ReminderPreferences, error) {
+	p, err := ds.GetReminderPreferences(username)
+	if _, ok := err.(datastore.ReminderPreferencesNotFoundError); ok {
+		return types.ReminderPreferences{
+			Username: username,
+			Enabled:  false,
+			SendTime: reminders.DefaultSendTime,
+			TimeZone: reminders.DefaultTimeZone,
+		}, nil
+	} else if err != nil {
+		return types.ReminderPreferences{}, err
+	}
+	return p, nil
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetReminderPreferences(username string) (types.ReminderPreferences, error) {
+	if p, ok := ds.reminders[username]; ok {
+		return p, nil
+	}
+	return types.ReminderPreferences{}, datastore.ReminderPreferencesNotFoundError{Username: username}
+}
+
+func (ds *mockDatastore) SetReminderPreferences(username string, p types.ReminderPreferences) error {
+	if ds.reminders == nil {
+		ds.reminders = map[string]types.ReminderPreferences{}
+	}
+	p.Username = username
+	ds.reminders[username] = p
+	return nil
+}
+
+func (ds *mockDatastore) DeleteReminderPreferences(username string) error {
+	delete(ds.reminders, username)
+	return nil
+}
+
+func (ds mockDatastore) GetEnabledReminders() ([]types.ReminderPreferences, error) {
+	prefs := []types.ReminderPreferences{}
+	for _, p := range ds.reminders {
+		if p.Enabled {
+			prefs = append(prefs, p)
+		}
+	}
+	return prefs, nil
+}
+
+func TestRemindersGetAndPost(t *testing.T) {
+	ds := mockDatastore{
+		reminders: map[string]types.ReminderPreferences{
+			"bob": types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "09:00", TimeZone: "UTC", LastSentFor: "2019-11-29"},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	steps := []struct {
+		explanation        string
+		method             string
+		authToken          string
+		body               string
+		httpStatusExpected int
+		prefsExpected      types.ReminderPreferences
+	}{
+		{
+			"anonymous users can't view reminder settings",
+			"GET", "", "", http.StatusForbidden, types.ReminderPreferences{},
+		},
+		{
+			"users without saved settings get defaults",
+			"GET", "mock_token_A", "", http.StatusOK,
+			types.ReminderPreferences{Enabled: false, SendTime: "17:00", TimeZone: "UTC"},
+		},
+		{
+			"alice enables reminders",
+			"POST", "mock_token_A", `{"enabled": true, "sendTime": "16:30", "timeZone": "America/New_York"}`, http.StatusOK,
+			types.ReminderPreferences{Enabled: true, SendTime: "16:30", TimeZone: "America/New_York"},
+		},
+		{
+			"rejects invalid send time",
+			"POST", "mock_token_A", `{"enabled": true, "sendTime": "4:30pm", "timeZone": "UTC"}`, http.StatusBadRequest,
+			types.ReminderPreferences{Enabled: true, SendTime: "16:30", TimeZone: "America/New_York"},
+		},
+		{
+			"rejects invalid time zone",
+			"POST", "mock_token_A", `{"enabled": true, "sendTime": "16:30", "timeZone": "Nowhere/Special"}`, http.StatusBadRequest,
+			types.ReminderPreferences{Enabled: true, SendTime: "16:30", TimeZone: "America/New_York"},
+		},
+		{
+			"anonymous users can't update reminder settings",
+			"POST", "", `{"enabled": true, "sendTime": "16:30", "timeZone": "UTC"}`, http.StatusForbidden,
+			types.ReminderPreferences{Enabled: true, SendTime: "16:30", TimeZone: "America/New_York"},
+		},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, step.method, "/api/user/me/reminders", step.authToken, step.body)
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				step.explanation, status, step.httpStatusExpected)
+		}
+		if step.method == "GET" && step.httpStatusExpected == http.StatusOK {
+			var response types.ReminderPreferences
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+			}
+			if !reflect.DeepEqual(response, step.prefsExpected) {
+				t.Fatalf("%s: unexpected response: got %+v want %+v", step.explanation, response, step.prefsExpected)
+			}
+		}
+		if step.method == "POST" && step.authToken != "" {
+			stored := ds.reminders["alice"]
+			stored.Username = ""
+			if !reflect.DeepEqual(stored, step.prefsExpected) {
+				t.Fatalf("%s: unexpected stored preferences: got %+v want %+v", step.explanation, stored, step.prefsExpected)
+			}
+		}
+	}
+
+	// Updating settings preserves the record of the last reminder sent.
+	w := sendTeamsRequest(s, "POST", "/api/user/me/reminders", "mock_token_B", `{"enabled": true, "sendTime": "10:00", "timeZone": "UTC"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to update bob's reminders: %v", w.Code)
+	}
+	if ds.reminders["bob"].LastSentFor != "2019-11-29" {
+		t.Fatalf("updating reminder settings cleared lastSentFor: got %v", ds.reminders["bob"].LastSentFor)
+	}
+}
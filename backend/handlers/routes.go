@@ -6,30 +6,100 @@ import (
 
 func (s *defaultServer) routes() {
 	s.router.Use(s.enableCors)
+	s.router.Use(s.skipCsrfForSignedLinks)
 	s.router.Use(s.enableCsrf)
+	s.router.Use(s.blockWritesFromDeletedAccounts)
 
 	// Handle routes that require backend logic.
 	s.router.HandleFunc("/api/entries/{username}", s.entriesGet()).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/entries/{username}/project/{project}", s.projectOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/entries/{username}/project/{project}", s.projectGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/entries/{username}/tags", s.userTagsGet()).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/entry/{date}", s.entryOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/entry/{date}", s.entryPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/comments/entry/{username}/{date}", s.commentsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/comments/entry/{username}/{date}", s.commentsGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/comments/entry/{username}/{date}", s.commentsPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/comments/entry/{username}/{date}/{commentID}", s.commentsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/comments/entry/{username}/{date}/{commentID}", s.commentPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/comments/entry/{username}/{date}/{commentID}", s.commentDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/digest/unsubscribe", s.digestUnsubscribeGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/digest/unsubscribe", s.digestUnsubscribePost()).Methods(http.MethodPost)
 	s.router.HandleFunc("/api/draft/{date}", s.draftOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/draft/{date}", s.draftGet()).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/draft/{date}", s.draftPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/feed", s.feedGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/import", s.importOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/import", s.importPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/follow/{username}", s.followOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/follow/{username}", s.followPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/follow/{username}", s.followDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/notifications", s.notificationsGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/notifications/preferences", s.notificationsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/notifications/preferences", s.notificationPreferencesGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/notifications/preferences", s.notificationPreferencesPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/notifications/read", s.notificationsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/notifications/read", s.notificationsReadPost()).Methods(http.MethodPost)
 	s.router.HandleFunc("/api/pageViews", s.pageViewsOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/pageViews", s.pageViewsGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/reactions/symbols", s.reactionSymbolsGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/reactions/summary", s.reactionSummariesGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/reactions/summary/entry/{username}/{date}", s.reactionSummaryGet()).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/reactions/entry/{username}/{date}", s.reactionsOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/reactions/entry/{username}/{date}", s.reactionsGet()).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/reactions/entry/{username}/{date}", s.reactionsPost()).Methods(http.MethodPost)
 	s.router.HandleFunc("/api/recentEntries", s.recentEntriesGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/trending", s.trendingGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/search", s.searchGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/tags/{tag}", s.tagEntriesGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/teams", s.teamsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/teams", s.teamsPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/teams/{teamID}", s.teamGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/teams/{teamID}/slack", s.slackOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/teams/{teamID}/slack", s.teamSlackGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/teams/{teamID}/slack", s.teamSlackPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/teams/{teamID}/slack", s.teamSlackDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/teams/{teamID}/reactions", s.teamsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/teams/{teamID}/reactions", s.teamReactionSymbolsPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/teams/{teamID}/entries", s.teamEntriesGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/teams/{teamID}/invitations", s.teamsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/teams/{teamID}/invitations", s.teamInvitationsPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/teams/{teamID}/join", s.teamsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/teams/{teamID}/join", s.teamJoinPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/teams/{teamID}/members/{username}", s.teamsOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/teams/{teamID}/members/{username}", s.teamMemberPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/teams/{teamID}/members/{username}", s.teamMemberDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/user/me", s.userOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/user/me", s.userMeGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me", s.userMeDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/user/me/reminders", s.remindersOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/user/me/reminders", s.remindersGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/reminders", s.remindersPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/user/me/digest", s.digestOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/user/me/digest", s.digestGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/digest", s.digestPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/user/me/digest", s.digestDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/user/me/slack", s.slackOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/user/me/slack", s.userSlackGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/slack", s.userSlackPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/user/me/slack", s.userSlackDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/user/me/webhooks", s.webhooksOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/user/me/webhooks", s.webhooksGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/webhooks", s.webhooksPost()).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/user/me/webhooks/{webhookID}", s.webhooksOptions()).Methods(http.MethodOptions)
+	s.router.HandleFunc("/api/user/me/webhooks/{webhookID}", s.webhookDelete()).Methods(http.MethodDelete)
+	s.router.HandleFunc("/api/user/me/webhooks/{webhookID}/deliveries", s.webhookDeliveriesGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/stats", s.userMeStatsGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/export", s.userMeExportGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/following", s.userMeFollowingGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/user/me/teams", s.userMeTeamsGet()).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/user/{username}", s.userGet()).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/user", s.userOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/user", s.userPost()).Methods(http.MethodPost)
 	s.router.HandleFunc("/api/logout", s.logoutOptions()).Methods(http.MethodOptions)
 	s.router.HandleFunc("/api/logout", s.logoutPost()).Methods(http.MethodPost)
-	s.router.HandleFunc("/api/tasks/refreshGoogleAnalytics", s.refreshGoogleAnalytics()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/jobs", s.jobsGet()).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/tasks/runJobs", s.runDueJobs()).Methods(http.MethodGet)
 
 	// Catchall for when no API route matches.
 	s.router.PathPrefix("/api").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -40,6 +110,6 @@ func (s *defaultServer) routes() {
 
 	// Serve index.html, the base page HTML before Vue rendering happens, and
 	// render certain page elements server-side.
-	s.router.PathPrefix("/{username}/{date}").HandlerFunc(s.enableCsp(s.serveStaticResource())).Methods(http.MethodGet)
+	s.router.PathPrefix("/{username}/{date}").HandlerFunc(s.enableCsp(s.countPageView(s.serveStaticResource()))).Methods(http.MethodGet)
 	s.router.PathPrefix("/").HandlerFunc(s.enableCsp(s.serveStaticResource())).Methods(http.MethodGet)
 }
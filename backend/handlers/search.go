@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/mtlynch/whatgotdone/backend/handlers/entry"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/search"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type searchResult struct {
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Snippet string `json:"snippet"`
+}
+
+type searchFilters struct {
+	author  string
+	project string
+	from    string
+	to      string
+}
+
+func (s *defaultServer) searchGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		terms := search.Tokenize(r.URL.Query().Get("q"))
+		if len(terms) == 0 {
+			http.Error(w, "Search query must contain at least one word", http.StatusBadRequest)
+			return
+		}
+
+		filters, err := searchFiltersFromRequest(r)
+		if err != nil {
+			log.Printf("Invalid search filters: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Look up the term likely to match the fewest entries so that common
+		// words in the query don't pull in most of the index.
+		indexTerm := search.MostSelectiveTerm(terms)
+		refs, err := s.datastore.SearchEntries(indexTerm)
+		if err != nil {
+			log.Printf("Failed to search entries for %s: %s", indexTerm, err)
+			http.Error(w, "Failed to search entries", http.StatusInternalServerError)
+			return
+		}
+
+		viewer := s.viewerFromRequest(r)
+		entriesByAuthor := map[string][]types.JournalEntry{}
+		results := []searchResult{}
+		for _, ref := range refs {
+			if !filters.matchesRef(ref) {
+				continue
+			}
+			if _, ok := entriesByAuthor[ref.Author]; !ok {
+				entries, err := getVisibleEntries(s.datastore, viewer, ref.Author)
+				if err != nil {
+					log.Printf("Failed to retrieve entries for user %s: %s", ref.Author, err)
+					http.Error(w, "Failed to search entries", http.StatusInternalServerError)
+					return
+				}
+				entriesByAuthor[ref.Author] = entries
+			}
+			j, ok := findEntryByDate(entriesByAuthor[ref.Author], ref.Date)
+			if !ok {
+				// The entry is hidden from this viewer, or the index refers to an
+				// entry that no longer exists.
+				continue
+			}
+			markdown := j.Markdown
+			if filters.project != "" {
+				markdown, err = entry.ReadProject(j.Markdown, filters.project)
+				if err != nil {
+					continue
+				}
+			}
+			// The index only narrows results to entries that contain one term,
+			// so check the remaining terms against the full text.
+			if !search.MatchesAll(markdown, terms) {
+				continue
+			}
+			results = append(results, searchResult{
+				Author:  ref.Author,
+				Date:    ref.Date,
+				Snippet: search.Snippet(markdown, terms),
+			})
+		}
+
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].Date != results[j].Date {
+				return results[i].Date > results[j].Date
+			}
+			return results[i].Author < results[j].Author
+		})
+
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func searchFiltersFromRequest(r *http.Request) (searchFilters, error) {
+	f := searchFilters{
+		author:  r.URL.Query().Get("author"),
+		project: r.URL.Query().Get("project"),
+		from:    r.URL.Query().Get("from"),
+		to:      r.URL.Query().Get("to"),
+	}
+	if f.author != "" && !validate.Username(f.author) {
+		return searchFilters{}, errors.New("Invalid author")
+	}
+	if f.from != "" && !isValidDateParameter(f.from) {
+		return searchFilters{}, errors.New("Invalid from date: must be YYYY-MM-DD")
+	}
+	if f.to != "" && !isValidDateParameter(f.to) {
+		return searchFilters{}, errors.New("Invalid to date: must be YYYY-MM-DD")
+	}
+	return f, nil
+}
+
+func (f searchFilters) matchesRef(ref types.EntryRef) bool {
+	if f.author != "" && ref.Author != f.author {
+		return false
+	}
+	if f.from != "" && ref.Date < f.from {
+		return false
+	}
+	if f.to != "" && ref.Date > f.to {
+		return false
+	}
+	return true
+}
+
+func findEntryByDate(entries []types.JournalEntry, date string) (types.JournalEntry, bool) {
+	for _, j := range entries {
+		if j.Date == date {
+			return j, true
+		}
+	}
+	return types.JournalEntry{}, false
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/search"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) IndexEntry(username string, j types.JournalEntry) error {
+	return nil
+}
+
+func (ds mockDatastore) SearchEntries(term string) ([]types.EntryRef, error) {
+	refs := []types.EntryRef{}
+	for _, username := range ds.users {
+		for _, j := range ds.journalEntries {
+			for _, t := range search.Tokenize(j.Markdown) {
+				if t == term {
+					refs = append(refs, types.EntryRef{Author: username, Date: j.Date})
+					break
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+func TestSearchGet(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29", Markdown: "# Billing\n\n* Shipped the new billing page"},
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22", Markdown: "# Billing\n\n* Designed the billing page\n\n# Hiring\n\n* Shipped a job post"},
+			types.JournalEntry{Date: "2019-11-15", LastModified: "2019-11-15", Markdown: "Took a nap"},
+		},
+		users: []string{"bob"},
+	}
+	router := mux.NewRouter()
+	s := defaultServer{
+		datastore:      &ds,
+		router:         router,
+		csrfMiddleware: dummyCsrfMiddleware(),
+	}
+	s.routes()
+
+	var tests = []struct {
+		explanation        string
+		query              string
+		httpStatusExpected int
+		resultsExpected    []searchResult
+	}{
+		{
+			"returns matches in descending date order",
+			"q=billing",
+			http.StatusOK,
+			[]searchResult{
+				searchResult{Author: "bob", Date: "2019-11-29", Snippet: "# **Billing** * Shipped the new **billing** page"},
+				searchResult{Author: "bob", Date: "2019-11-22", Snippet: "# **Billing** * Designed the **billing** page # Hiring * Shipped a job post"},
+			},
+		},
+		{
+			"requires every term to match",
+			"q=shipped+billing",
+			http.StatusOK,
+			[]searchResult{
+				searchResult{Author: "bob", Date: "2019-11-29", Snippet: "# **Billing** * **Shipped** the new **billing** page"},
+				searchResult{Author: "bob", Date: "2019-11-22", Snippet: "# **Billing** * Designed the **billing** page # Hiring * **Shipped** a job post"},
+			},
+		},
+		{
+			"restricts matches to the given project",
+			"q=shipped&project=hiring",
+			http.StatusOK,
+			[]searchResult{
+				searchResult{Author: "bob", Date: "2019-11-22", Snippet: "* **Shipped** a job post"},
+			},
+		},
+		{
+			"restricts matches to the given date range",
+			"q=billing&from=2019-11-23&to=2019-12-31",
+			http.StatusOK,
+			[]searchResult{
+				searchResult{Author: "bob", Date: "2019-11-29", Snippet: "# **Billing** * Shipped the new **billing** page"},
+			},
+		},
+		{
+			"restricts matches to the given author",
+			"q=billing&author=alice",
+			http.StatusOK,
+			[]searchResult{},
+		},
+		{
+			"returns empty results when nothing matches",
+			"q=coupons",
+			http.StatusOK,
+			[]searchResult{},
+		},
+		{
+			"rejects missing query",
+			"",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"rejects query without any words",
+			"q=%23%21",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"rejects invalid date filter",
+			"q=billing&from=last-week",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"rejects invalid author filter",
+			"q=billing&author=bad%20user",
+			http.StatusBadRequest,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("GET", "/api/search?"+tt.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+		if tt.httpStatusExpected != http.StatusOK {
+			continue
+		}
+
+		var response []searchResult
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if !reflect.DeepEqual(response, tt.resultsExpected) {
+			t.Fatalf("%s: Unexpected response: got %v want %v", tt.explanation, response, tt.resultsExpected)
+		}
+	}
+}
@@ -3,14 +3,58 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/mtlynch/whatgotdone/backend/accounts"
+	"github.com/mtlynch/whatgotdone/backend/analytics"
 	"github.com/mtlynch/whatgotdone/backend/auth"
 	"github.com/mtlynch/whatgotdone/backend/datastore"
-	ga "github.com/mtlynch/whatgotdone/backend/google_analytics"
+	"github.com/mtlynch/whatgotdone/backend/digest"
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/notifications"
+	"github.com/mtlynch/whatgotdone/backend/pageviews"
+	"github.com/mtlynch/whatgotdone/backend/reminders"
+	"github.com/mtlynch/whatgotdone/backend/scheduler"
+	"github.com/mtlynch/whatgotdone/backend/slack"
+	"github.com/mtlynch/whatgotdone/backend/trending"
+	"github.com/mtlynch/whatgotdone/backend/types"
+	"github.com/mtlynch/whatgotdone/backend/webhooks"
 )
 
+// reminderCheckInterval is how often the server checks for users who are due
+// for a weekly reminder email.
+const reminderCheckInterval = 5 * time.Minute
+
+// digestCheckInterval is how often the server checks for users who are due for
+// an email digest.
+const digestCheckInterval = 15 * time.Minute
+
+// notificationEmailInterval is how often the server batches unread
+// notifications into emails.
+const notificationEmailInterval = time.Hour
+
+// pageViewFlushInterval is how often the server saves the page views that it
+// counts itself.
+const pageViewFlushInterval = time.Minute
+
+// analyticsSyncInterval is how often the server copies page views from its
+// analytics source.
+const analyticsSyncInterval = 10 * time.Minute
+
+// accountPurgeInterval is how often the server purges the data of users who
+// confirmed that they want to delete their accounts.
+const accountPurgeInterval = time.Minute
+
+// trendingUpdateInterval is how often the server recalculates the trending
+// ranking.
+const trendingUpdateInterval = 15 * time.Minute
+
+// schedulerTickInterval is how often the server checks for due background
+// jobs.
+const schedulerTickInterval = time.Minute
+
 // Server handles HTTP requests for the What Got Done backend.
 type Server interface {
 	Router() *mux.Router
@@ -19,32 +63,108 @@ type Server interface {
 // New creates a new What Got Done server with all the state it needs to
 // satisfy HTTP requests.
 func New() Server {
-	var fetcher *ga.MetricFetcher
-	f, err := ga.New()
+	source, err := newAnalyticsSource()
 	if err != nil {
-		log.Printf("Failed to load Google Analytics metrics fetcher: %s", err)
+		log.Printf("Failed to load analytics source: %s", err)
+	}
+	ds := newDatastore()
+	mailer, mailerErr := mail.New()
+	digestSigner := digest.NewSigner(getDigestUnsubscribeSecret(mailerErr == nil))
+	jobs := scheduler.New(ds)
+	jobs.Register(scheduler.Job{
+		Name:     "purge-deleted-accounts",
+		Interval: accountPurgeInterval,
+		Run:      accounts.New(ds).PurgeConfirmed,
+	})
+	jobs.Register(scheduler.Job{
+		Name:     "rank-trending-entries",
+		Interval: trendingUpdateInterval,
+		Run:      trending.New(ds, minimumRelevantLength).Update,
+	})
+	if mailerErr != nil {
+		log.Printf("Failed to load mailer, email reminders, digests, and notifications are disabled: %s", mailerErr)
 	} else {
-		fetcher = &f
+		jobs.Register(scheduler.Job{
+			Name:     "send-reminders",
+			Interval: reminderCheckInterval,
+			Run:      reminders.New(ds, mailer).SendDue,
+		})
+		jobs.Register(scheduler.Job{
+			Name:     "send-digests",
+			Interval: digestCheckInterval,
+			Run: digest.New(ds, mailer, digestSigner, func(viewer string, author string, entries []types.JournalEntry) ([]types.JournalEntry, error) {
+				return filterVisibleEntries(ds, viewer, author, entries)
+			}).SendDue,
+		})
+		notificationSender := notifications.New(ds, mailer)
+		jobs.Register(scheduler.Job{
+			Name:     "email-notifications",
+			Interval: notificationEmailInterval,
+			Run: func(time.Time) error {
+				return notificationSender.SendPending()
+			},
+		})
 	}
+	// Count page views on the server when no analytics source is available.
+	// Both write the same totals, so only one of them can be active. Every
+	// instance flushes its own counts, so flushing isn't a scheduled job.
+	var counter *pageviews.Counter
+	if source == nil {
+		log.Print("Counting page views on the server instead of an analytics source")
+		counter = pageviews.New(ds)
+		counter.Start(pageViewFlushInterval)
+	}
+	slackPoster := slack.New()
 	s := defaultServer{
-		authenticator:          auth.New(),
-		datastore:              newDatastore(),
-		router:                 mux.NewRouter(),
-		csrfMiddleware:         newCsrfMiddleware(),
-		googleAnalyticsFetcher: fetcher,
+		authenticator:     auth.New(),
+		datastore:         ds,
+		digestSigner:      digestSigner,
+		router:            mux.NewRouter(),
+		csrfMiddleware:    newCsrfMiddleware(),
+		analyticsSource:   source,
+		webhookDispatcher: webhooks.New(ds),
+		slackPoster:       &slackPoster,
+		reactionSymbols:   reactionSymbolsFromEnv(),
+		pageViewCounter:   counter,
+		scheduler:         jobs,
+		adminUsernames:    adminUsernamesFromEnv(),
+		jobRunnerSecret:   jobRunnerSecretFromEnv(),
+		onAppEngine:       isAppEngineRuntime(),
+		mailer:            mailer,
+	}
+	if source != nil {
+		jobs.Register(scheduler.Job{
+			Name:     "sync-page-views",
+			Interval: analyticsSyncInterval,
+			Run:      s.syncPageViews,
+		})
 	}
 	s.routes()
+	jobs.Start(schedulerTickInterval)
 	return s
 }
 
 type httpMiddlewareHandler func(http.Handler) http.Handler
 
 type defaultServer struct {
-	authenticator          auth.Authenticator
-	datastore              datastore.Datastore
-	router                 *mux.Router
-	csrfMiddleware         httpMiddlewareHandler
-	googleAnalyticsFetcher *ga.MetricFetcher
+	authenticator     auth.Authenticator
+	datastore         datastore.Datastore
+	digestSigner      digest.Signer
+	router            *mux.Router
+	csrfMiddleware    httpMiddlewareHandler
+	analyticsSource   analytics.Source
+	webhookDispatcher *webhooks.Dispatcher
+	slackPoster       *slack.Poster
+	reactionSymbols   []string
+	pageViewCounter   *pageviews.Counter
+	scheduler         *scheduler.Scheduler
+	adminUsernames    []string
+	// jobRunnerSecret lets job runners other than App Engine cron trigger
+	// background jobs.
+	jobRunnerSecret string
+	onAppEngine     bool
+	// mailer is nil if the server can't send email.
+	mailer mail.Mailer
 }
 
 // Router returns the underlying router interface for the server.
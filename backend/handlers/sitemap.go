@@ -8,6 +8,7 @@ import (
 	"github.com/ikeikeikeike/go-sitemap-generator/stm"
 
 	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/site"
 )
 
 func (s defaultServer) sitemapGet() http.HandlerFunc {
@@ -19,7 +20,7 @@ func (s defaultServer) sitemapGet() http.HandlerFunc {
 
 func buildSitemap(ds datastore.Datastore) *stm.Sitemap {
 	sm := stm.NewSitemap(1)
-	sm.SetDefaultHost("https://whatgotdone.com")
+	sm.SetDefaultHost(site.URL)
 
 	sm.Create()
 	sm.Add(stm.URL{{"loc", "/"}, {"changefreq", "daily"}})
@@ -38,7 +39,7 @@ func addUsersAndEntries(sm *stm.Sitemap, ds datastore.Datastore) {
 	}
 	for _, u := range users {
 		sm.Add(stm.URL{{"loc", fmt.Sprintf("/%s", u)}})
-		entries, err := ds.GetEntries(u)
+		entries, err := getVisibleEntries(ds, anonymousViewer, u)
 		if err != nil {
 			log.Printf("error getting entries for %s: %v", u, err)
 			continue
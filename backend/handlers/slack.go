@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/entry"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/site"
+	"github.com/mtlynch/whatgotdone/backend/slack"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (s defaultServer) slackOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) userSlackGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view your Slack integration", http.StatusForbidden)
+			return
+		}
+		s.writeSlackIntegration(w, types.UserSlackOwner(username))
+	}
+}
+
+func (s defaultServer) userSlackPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to configure a Slack integration", http.StatusForbidden)
+			return
+		}
+		s.saveSlackIntegration(w, r, types.UserSlackOwner(username), username)
+	}
+}
+
+func (s defaultServer) userSlackDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to remove a Slack integration", http.StatusForbidden)
+			return
+		}
+		s.deleteSlackIntegration(w, types.UserSlackOwner(username))
+	}
+}
+
+func (s defaultServer) teamSlackGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view a team's Slack integration", http.StatusForbidden)
+			return
+		}
+		team, ok := s.teamForRequest(w, r)
+		if !ok {
+			return
+		}
+		if _, isMember := team.Member(username); !isMember {
+			http.Error(w, "Only team members can view the team's Slack integration", http.StatusForbidden)
+			return
+		}
+		s.writeSlackIntegration(w, types.TeamSlackOwner(team.ID))
+	}
+}
+
+func (s defaultServer) teamSlackPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to configure a Slack integration", http.StatusForbidden)
+			return
+		}
+		team, ok := s.teamForRequest(w, r)
+		if !ok {
+			return
+		}
+		if !team.IsAdmin(username) {
+			http.Error(w, "Only team admins can configure the team's Slack integration", http.StatusForbidden)
+			return
+		}
+		s.saveSlackIntegration(w, r, types.TeamSlackOwner(team.ID), username)
+	}
+}
+
+func (s defaultServer) teamSlackDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to remove a Slack integration", http.StatusForbidden)
+			return
+		}
+		team, ok := s.teamForRequest(w, r)
+		if !ok {
+			return
+		}
+		if !team.IsAdmin(username) {
+			http.Error(w, "Only team admins can remove the team's Slack integration", http.StatusForbidden)
+			return
+		}
+		s.deleteSlackIntegration(w, types.TeamSlackOwner(team.ID))
+	}
+}
+
+// writeSlackIntegration responds with whether the owner has a Slack
+// integration. The response omits the webhook URL because anyone who knows it
+// can post to the channel.
+func (s defaultServer) writeSlackIntegration(w http.ResponseWriter, owner string) {
+	type slackIntegrationResponse struct {
+		Configured bool   `json:"configured"`
+		CreatedBy  string `json:"createdBy,omitempty"`
+	}
+	i, err := s.datastore.GetSlackIntegration(owner)
+	if _, ok := err.(datastore.SlackIntegrationNotFoundError); ok {
+		if err := json.NewEncoder(w).Encode(slackIntegrationResponse{Configured: false}); err != nil {
+			panic(err)
+		}
+		return
+	} else if err != nil {
+		log.Printf("Failed to retrieve Slack integration for %s: %s", owner, err)
+		http.Error(w, "Failed to retrieve Slack integration", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(slackIntegrationResponse{Configured: true, CreatedBy: i.CreatedBy}); err != nil {
+		panic(err)
+	}
+}
+
+func (s defaultServer) saveSlackIntegration(w http.ResponseWriter, r *http.Request, owner string, createdBy string) {
+	type slackIntegrationRequest struct {
+		WebhookURL string `json:"webhookUrl"`
+	}
+	var sr slackIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&sr); err != nil {
+		log.Printf("Invalid Slack integration request: %v", err)
+		http.Error(w, "Invalid Slack integration request", http.StatusBadRequest)
+		return
+	}
+	if !validate.SlackWebhookURL(sr.WebhookURL) {
+		http.Error(w, "Invalid webhook URL: must be a Slack incoming webhook URL starting with https://hooks.slack.com/", http.StatusBadRequest)
+		return
+	}
+
+	err := s.datastore.SetSlackIntegration(types.SlackIntegration{
+		Owner:      owner,
+		WebhookURL: sr.WebhookURL,
+		CreatedBy:  createdBy,
+	})
+	if err != nil {
+		log.Printf("Failed to save Slack integration for %s: %s", owner, err)
+		http.Error(w, "Failed to save Slack integration", http.StatusInternalServerError)
+		return
+	}
+
+	type slackIntegrationResponse struct {
+		Ok bool `json:"ok"`
+	}
+	if err := json.NewEncoder(w).Encode(slackIntegrationResponse{Ok: true}); err != nil {
+		panic(err)
+	}
+}
+
+func (s defaultServer) deleteSlackIntegration(w http.ResponseWriter, owner string) {
+	if err := s.datastore.DeleteSlackIntegration(owner); err != nil {
+		log.Printf("Failed to delete Slack integration for %s: %s", owner, err)
+		http.Error(w, "Failed to remove Slack integration", http.StatusInternalServerError)
+		return
+	}
+
+	type slackIntegrationResponse struct {
+		Ok bool `json:"ok"`
+	}
+	if err := json.NewEncoder(w).Encode(slackIntegrationResponse{Ok: true}); err != nil {
+		panic(err)
+	}
+}
+
+// postEntryToSlack announces a newly published entry to the author's Slack
+// integration and to the integrations of teams that can read the entry.
+// Private entries never appear in Slack.
+func (s defaultServer) postEntryToSlack(author string, j types.JournalEntry) error {
+	if s.slackPoster == nil {
+		return nil
+	}
+
+	v := j.Visibility
+	if v == types.VisibilityDefault {
+		var err error
+		v, err = authorDefaultVisibility(s.datastore, author)
+		if err != nil {
+			return err
+		}
+	}
+	if v == types.VisibilityPrivate {
+		return nil
+	}
+
+	owners := []string{types.UserSlackOwner(author)}
+	if v == types.VisibilityTeam {
+		owners = append(owners, types.TeamSlackOwner(j.TeamID))
+	} else {
+		teams, err := s.datastore.GetTeamsForUser(author)
+		if err != nil {
+			return err
+		}
+		for _, team := range teams {
+			if _, isMember := team.Member(author); isMember {
+				owners = append(owners, types.TeamSlackOwner(team.ID))
+			}
+		}
+	}
+
+	urls := []string{}
+	for _, owner := range owners {
+		i, err := s.datastore.GetSlackIntegration(owner)
+		if _, ok := err.(datastore.SlackIntegrationNotFoundError); ok {
+			continue
+		} else if err != nil {
+			return err
+		}
+		urls = append(urls, i.WebhookURL)
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	m := slack.EntryMessage(author, j.Date, site.EntryURL(author, j.Date), entry.ReadProjectHeadings(j.Markdown))
+	// Post in the background so that slow Slack responses don't delay
+	// publishing.
+	go func() {
+		for _, url := range urls {
+			if err := s.slackPoster.Post(url, m); err != nil {
+				log.Printf("Failed to post entry %s/%s to Slack: %s", author, j.Date, err)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/slack"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetSlackIntegration(owner string) (types.SlackIntegration, error) {
+	if i, ok := ds.slack[owner]; ok {
+		return i, nil
+	}
+	return types.SlackIntegration{}, datastore.SlackIntegrationNotFoundError{Owner: owner}
+}
+
+func (ds *mockDatastore) SetSlackIntegration(i types.SlackIntegration) error {
+	if ds.slack == nil {
+		ds.slack = map[string]types.SlackIntegration{}
+	}
+	ds.slack[i.Owner] = i
+	return nil
+}
+
+func (ds *mockDatastore) DeleteSlackIntegration(owner string) error {
+	delete(ds.slack, owner)
+	return nil
+}
+
+func TestSlackIntegrationConfiguration(t *testing.T) {
+	ds := mockDatastore{
+		teams: map[string]types.Team{
+			"eng": types.Team{
+				ID: "eng",
+				Members: []types.TeamMember{
+					types.TeamMember{Username: "alice", Role: types.TeamRoleAdmin},
+					types.TeamMember{Username: "bob", Role: types.TeamRoleMember},
+				},
+			},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	steps := []struct {
+		explanation        string
+		method             string
+		path               string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"anonymous users can't configure Slack", "POST", "/api/user/me/slack", "", `{"webhookUrl": "https://hooks.slack.com/services/T/B/X"}`, http.StatusForbidden},
+		{"rejects invalid webhook URL", "POST", "/api/user/me/slack", "mock_token_A", `{"webhookUrl": "hooks.slack.com"}`, http.StatusBadRequest},
+		{"rejects non-Slack webhook URL", "POST", "/api/user/me/slack", "mock_token_A", `{"webhookUrl": "http://169.254.169.254/latest/meta-data/"}`, http.StatusBadRequest},
+		{"alice configures her own Slack", "POST", "/api/user/me/slack", "mock_token_A", `{"webhookUrl": "https://hooks.slack.com/services/T/B/A"}`, http.StatusOK},
+		{"team members can't configure team Slack", "POST", "/api/teams/eng/slack", "mock_token_B", `{"webhookUrl": "https://hooks.slack.com/services/T/B/E"}`, http.StatusForbidden},
+		{"team admins configure team Slack", "POST", "/api/teams/eng/slack", "mock_token_A", `{"webhookUrl": "https://hooks.slack.com/services/T/B/E"}`, http.StatusOK},
+		{"team members can view team Slack status", "GET", "/api/teams/eng/slack", "mock_token_B", "", http.StatusOK},
+		{"non-members can't view team Slack status", "GET", "/api/teams/eng/slack", "mock_token_C", "", http.StatusForbidden},
+		{"unknown team returns not found", "GET", "/api/teams/nope/slack", "mock_token_A", "", http.StatusNotFound},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, step.method, step.path, step.authToken, step.body)
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				step.explanation, status, step.httpStatusExpected)
+		}
+	}
+
+	if ds.slack["user:alice"].WebhookURL != "https://hooks.slack.com/services/T/B/A" {
+		t.Fatalf("unexpected user integration: %+v", ds.slack["user:alice"])
+	}
+	if ds.slack["team:eng"].WebhookURL != "https://hooks.slack.com/services/T/B/E" {
+		t.Fatalf("unexpected team integration: %+v", ds.slack["team:eng"])
+	}
+
+	w := sendTeamsRequest(s, "GET", "/api/teams/eng/slack", "mock_token_B", "")
+	var status map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if status["configured"] != true {
+		t.Fatalf("expected team integration to be configured: %v", status)
+	}
+	if _, ok := status["webhookUrl"]; ok {
+		t.Fatalf("response should not reveal the webhook URL: %v", status)
+	}
+
+	if w := sendTeamsRequest(s, "DELETE", "/api/user/me/slack", "mock_token_A", ""); w.Code != http.StatusOK {
+		t.Fatalf("failed to remove Slack integration: %v", w.Code)
+	}
+	if _, ok := ds.slack["user:alice"]; ok {
+		t.Fatalf("integration still exists after deletion")
+	}
+}
+
+func TestEntryPostAnnouncesNewEntriesInSlack(t *testing.T) {
+	received := make(chan string, 10)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var m slack.Message
+		json.Unmarshal(body, &m)
+		received <- r.URL.Path + " " + m.Text
+	}))
+	defer stub.Close()
+
+	var tests = []struct {
+		explanation      string
+		path             string
+		body             string
+		messagesExpected []string
+	}{
+		{
+			"new public entry goes to user and team channels",
+			"/api/entry/2019-11-29",
+			`{"entryContent": "# Billing\n\n* Shipped\n\n# Hiring\n\n* Posted a job"}`,
+			[]string{
+				"/alice *alice* published <https://whatgotdone.com/alice/2019-11-29|their update for the week ending 2019-11-29>\nProjects: Billing, Hiring",
+				"/eng *alice* published <https://whatgotdone.com/alice/2019-11-29|their update for the week ending 2019-11-29>\nProjects: Billing, Hiring",
+			},
+		},
+		{
+			"editing an existing entry doesn't post",
+			"/api/entry/2019-11-22",
+			`{"entryContent": "Wrote more docs"}`,
+			[]string{},
+		},
+		{
+			"private entries don't post",
+			"/api/entry/2019-11-29",
+			`{"entryContent": "Secret", "visibility": "private"}`,
+			[]string{},
+		},
+		{
+			"team entries only post to their team",
+			"/api/entry/2019-11-29",
+			`{"entryContent": "Team stuff", "visibility": "team", "teamId": "eng"}`,
+			[]string{
+				"/alice *alice* published <https://whatgotdone.com/alice/2019-11-29|their update for the week ending 2019-11-29>",
+				"/eng *alice* published <https://whatgotdone.com/alice/2019-11-29|their update for the week ending 2019-11-29>",
+			},
+		},
+	}
+	for _, tt := range tests {
+		ds := mockDatastore{
+			journalEntries: []types.JournalEntry{
+				types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22T20:00:00Z", Markdown: "Wrote docs"},
+			},
+			teams: map[string]types.Team{
+				"eng": types.Team{
+					ID:      "eng",
+					Members: []types.TeamMember{types.TeamMember{Username: "alice", Role: types.TeamRoleMember}},
+				},
+				"sales": types.Team{
+					ID:       "sales",
+					Invitees: []string{"alice"},
+				},
+			},
+			slack: map[string]types.SlackIntegration{
+				"user:alice": types.SlackIntegration{Owner: "user:alice", WebhookURL: stub.URL + "/alice"},
+				"team:eng":   types.SlackIntegration{Owner: "team:eng", WebhookURL: stub.URL + "/eng"},
+				"team:sales": types.SlackIntegration{Owner: "team:sales", WebhookURL: stub.URL + "/sales"},
+			},
+		}
+		// The stub listens on loopback, which the default client refuses.
+		poster := slack.Poster{Client: stub.Client()}
+		s := defaultServer{
+			authenticator: mockAuthenticator{
+				tokensToUsers: map[string]string{
+					"mock_token_A": "alice",
+				},
+			},
+			datastore:      &ds,
+			router:         mux.NewRouter(),
+			csrfMiddleware: dummyCsrfMiddleware(),
+			slackPoster:    &poster,
+		}
+		s.routes()
+
+		w := sendTeamsRequest(s, "POST", tt.path, "mock_token_A", tt.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: request failed with status %v", tt.explanation, w.Code)
+		}
+
+		messages := []string{}
+		for range tt.messagesExpected {
+			select {
+			case m := <-received:
+				messages = append(messages, m)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s: timed out waiting for Slack messages", tt.explanation)
+			}
+		}
+		// Wait briefly to catch any unexpected extra messages.
+		select {
+		case m := <-received:
+			t.Fatalf("%s: unexpected Slack message: %s", tt.explanation, m)
+		case <-time.After(50 * time.Millisecond):
+		}
+		sort.Strings(messages)
+		for i := range messages {
+			if messages[i] != tt.messagesExpected[i] {
+				t.Fatalf("%s: unexpected message: got %q want %q", tt.explanation, messages[i], tt.messagesExpected[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/dates"
+	"github.com/mtlynch/whatgotdone/backend/handlers/entry"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// userStatsTTL is how long cached stats remain valid. Publishing or editing an
+// entry invalidates them sooner, but new page views and reactions only appear
+// once the cached stats expire.
+const userStatsTTL = time.Hour
+
+// maxTopProjects is the number of projects to include in a user's stats.
+const maxTopProjects = 10
+
+// userMeStatsGet returns publishing and readership stats for the logged-in
+// user's entries.
+func (s defaultServer) userMeStatsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must be logged in to retrieve your stats", http.StatusForbidden)
+			return
+		}
+
+		entries, err := s.datastore.GetEntries(username)
+		if err != nil {
+			log.Printf("Failed to retrieve entries for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve stats", http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+		stats, err := s.datastore.GetUserStats(username)
+		if _, ok := err.(datastore.UserStatsNotFoundError); ok {
+			stats = types.UserStats{}
+		} else if err != nil {
+			// The cache is only an optimization, so recompute the stats instead of
+			// failing the request.
+			log.Printf("Failed to retrieve cached stats for %s: %s", username, err)
+			stats = types.UserStats{}
+		}
+
+		if !isUserStatsFresh(stats, entries, now) {
+			stats, err = s.computeUserStats(username, entries, now)
+			if err != nil {
+				log.Printf("Failed to compute stats for %s: %s", username, err)
+				http.Error(w, "Failed to retrieve stats", http.StatusInternalServerError)
+				return
+			}
+			if err := s.datastore.SetUserStats(username, stats); err != nil {
+				log.Printf("Failed to cache stats for %s: %s", username, err)
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// isUserStatsFresh returns true if the cached stats are recent enough to serve
+// and reflect the user's current entries.
+func isUserStatsFresh(stats types.UserStats, entries []types.JournalEntry, now time.Time) bool {
+	computedAt, err := time.Parse(time.RFC3339, stats.ComputedAt)
+	if err != nil {
+		return false
+	}
+	if now.Sub(computedAt) > userStatsTTL {
+		return false
+	}
+	return stats.EntryCount == len(entries) && stats.LatestModified == latestModified(entries)
+}
+
+func latestModified(entries []types.JournalEntry) string {
+	latest := ""
+	for _, j := range entries {
+		if j.LastModified > latest {
+			latest = j.LastModified
+		}
+	}
+	return latest
+}
+
+// computeUserStats calculates stats from the user's entries along with the
+// reactions and page views those entries received.
+func (s defaultServer) computeUserStats(username string, entries []types.JournalEntry, now time.Time) (types.UserStats, error) {
+	stats := summarizeEntries(entries, now)
+	stats.EntryPageViews = []types.EntryPageViews{}
+	stats.ReactionsBySymbol = map[string]int{}
+
+	for _, j := range entries {
+		views, err := s.datastore.GetPageViews("/" + username + "/" + j.Date)
+		if _, ok := err.(datastore.PageViewsNotFoundError); ok {
+			views = 0
+		} else if err != nil {
+			return types.UserStats{}, err
+		}
+		stats.TotalPageViews += views
+		stats.EntryPageViews = append(stats.EntryPageViews, types.EntryPageViews{
+			Date:  j.Date,
+			Views: views,
+		})
+
+		reactions, err := s.datastore.GetReactions(username, j.Date)
+		if err != nil {
+			return types.UserStats{}, err
+		}
+		for _, reaction := range withoutClearedReactions(reactions) {
+			// Authors reacting to their own entries doesn't count as a reaction
+			// received.
+			if reaction.Username == username {
+				continue
+			}
+			stats.ReactionsReceived++
+			stats.ReactionsBySymbol[reaction.Symbol]++
+		}
+	}
+	return stats, nil
+}
+
+// summarizeEntries calculates the stats that depend only on the content and
+// dates of a user's entries.
+func summarizeEntries(entries []types.JournalEntry, now time.Time) types.UserStats {
+	sorted := make([]types.JournalEntry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date < sorted[j].Date
+	})
+
+	stats := types.UserStats{
+		ComputedAt:      now.UTC().Format(time.RFC3339),
+		EntryCount:      len(sorted),
+		LatestModified:  latestModified(sorted),
+		EntriesPerMonth: []types.MonthlyCount{},
+		TopProjects:     []types.ProjectCount{},
+	}
+
+	totalWords := 0
+	projectCounts := map[string]int{}
+	for _, j := range sorted {
+		month := j.Date[:len("2006-01")]
+		if n := len(stats.EntriesPerMonth); n > 0 && stats.EntriesPerMonth[n-1].Month == month {
+			stats.EntriesPerMonth[n-1].Count++
+		} else {
+			stats.EntriesPerMonth = append(stats.EntriesPerMonth, types.MonthlyCount{Month: month, Count: 1})
+		}
+
+		totalWords += countWords(j.Markdown)
+
+		seen := map[string]bool{}
+		for _, project := range entry.ReadProjectHeadings(j.Markdown) {
+			if seen[project] {
+				continue
+			}
+			seen[project] = true
+			projectCounts[project]++
+		}
+	}
+	if len(sorted) > 0 {
+		stats.AverageEntryWords = totalWords / len(sorted)
+	}
+	stats.TopProjects = topProjects(projectCounts, maxTopProjects)
+	stats.CurrentStreak, stats.LongestStreak = publishingStreaks(sorted, now)
+	return stats
+}
+
+// countWords returns the number of words in an entry's markdown, ignoring
+// tokens that contain no letters or digits, such as heading and list markers.
+func countWords(markdown string) int {
+	words := 0
+	for _, field := range strings.Fields(markdown) {
+		if strings.IndexFunc(field, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) >= 0 {
+			words++
+		}
+	}
+	return words
+}
+
+// topProjects returns the projects that appear in the most entries, breaking
+// ties alphabetically.
+func topProjects(counts map[string]int, limit int) []types.ProjectCount {
+	projects := []types.ProjectCount{}
+	for project, count := range counts {
+		projects = append(projects, types.ProjectCount{Project: project, Count: count})
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Count != projects[j].Count {
+			return projects[i].Count > projects[j].Count
+		}
+		return projects[i].Project < projects[j].Project
+	})
+	if len(projects) > limit {
+		projects = projects[:limit]
+	}
+	return projects
+}
+
+// publishingStreaks returns the user's current and longest runs of consecutive
+// weeks with a published entry. Entries must be sorted by date. The current
+// streak is still alive if the user hasn't published for this week yet but
+// published last week.
+func publishingStreaks(entries []types.JournalEntry, now time.Time) (int, int) {
+	published := map[string]bool{}
+	longest := 0
+	run := 0
+	var previous time.Time
+	for _, j := range entries {
+		t, err := time.Parse("2006-01-02", j.Date)
+		if err != nil {
+			continue
+		}
+		published[j.Date] = true
+		if run > 0 && t.Sub(previous) == 7*24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		previous = t
+		if run > longest {
+			longest = run
+		}
+	}
+
+	week, err := time.Parse("2006-01-02", dates.WeekEnding(now))
+	if err != nil {
+		return 0, longest
+	}
+	if !published[week.Format("2006-01-02")] {
+		week = week.AddDate(0, 0, -7)
+	}
+	current := 0
+	for published[week.Format("2006-01-02")] {
+		current++
+		week = week.AddDate(0, 0, -7)
+	}
+	return current, longest
+}
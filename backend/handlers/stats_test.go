@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetUserStats(username string) (types.UserStats, error) {
+	stats, ok := ds.userStats[username]
+	if !ok {
+		return types.UserStats{}, datastore.UserStatsNotFoundError{Username: username}
+	}
+	return stats, nil
+}
+
+func (ds *mockDatastore) SetUserStats(username string, stats types.UserStats) error {
+	if ds.userStats == nil {
+		ds.userStats = map[string]types.UserStats{}
+	}
+	ds.userStats[username] = stats
+	return nil
+}
+
+func (ds mockDatastore) DeleteUserStats(username string) error {
+	return nil
+}
+
+func TestPublishingStreaks(t *testing.T) {
+	// A Wednesday, so the current week ends on 2019-12-06.
+	now := time.Date(2019, 12, 4, 12, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		explanation     string
+		dates           []string
+		currentExpected int
+		longestExpected int
+	}{
+		{
+			"no entries",
+			[]string{},
+			0,
+			0,
+		},
+		{
+			"streak includes the current week",
+			[]string{"2019-11-15", "2019-11-22", "2019-11-29", "2019-12-06"},
+			4,
+			4,
+		},
+		{
+			"streak continues when the current week is unpublished",
+			[]string{"2019-11-22", "2019-11-29"},
+			2,
+			2,
+		},
+		{
+			"missing last week ends the streak",
+			[]string{"2019-11-08", "2019-11-15", "2019-11-22"},
+			0,
+			3,
+		},
+		{
+			"longest streak is in the past",
+			[]string{"2019-10-04", "2019-10-11", "2019-10-18", "2019-11-08", "2019-11-29"},
+			1,
+			3,
+		},
+	}
+	for _, tt := range tests {
+		entries := []types.JournalEntry{}
+		for _, d := range tt.dates {
+			entries = append(entries, types.JournalEntry{Date: d})
+		}
+		current, longest := publishingStreaks(entries, now)
+		if current != tt.currentExpected || longest != tt.longestExpected {
+			t.Errorf("%s: got streaks (%d, %d), want (%d, %d)", tt.explanation, current, longest, tt.currentExpected, tt.longestExpected)
+		}
+	}
+}
+
+func TestSummarizeEntries(t *testing.T) {
+	entries := []types.JournalEntry{
+		types.JournalEntry{Date: "2019-12-06", LastModified: "2019-12-06T18:00:00Z", Markdown: "# Blog\n\nWrote a post\n\n# Garden\n\nPlanted bulbs"},
+		types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22T18:00:00Z", Markdown: "# Blog\n\nEdited drafts\n\n# Blog\n\nMore drafts"},
+		types.JournalEntry{Date: "2019-11-29", LastModified: "2019-12-07T09:00:00Z", Markdown: "Took the week off"},
+	}
+	stats := summarizeEntries(entries, time.Date(2019, 12, 7, 12, 0, 0, 0, time.UTC))
+
+	if stats.EntryCount != 3 {
+		t.Errorf("EntryCount = %d, want 3", stats.EntryCount)
+	}
+	if stats.LatestModified != "2019-12-07T09:00:00Z" {
+		t.Errorf("LatestModified = %s, want 2019-12-07T09:00:00Z", stats.LatestModified)
+	}
+	monthsExpected := []types.MonthlyCount{
+		{Month: "2019-11", Count: 2},
+		{Month: "2019-12", Count: 1},
+	}
+	if !reflect.DeepEqual(stats.EntriesPerMonth, monthsExpected) {
+		t.Errorf("EntriesPerMonth = %+v, want %+v", stats.EntriesPerMonth, monthsExpected)
+	}
+	// Heading markers don't count as words.
+	if stats.AverageEntryWords != 5 {
+		t.Errorf("AverageEntryWords = %d, want 5", stats.AverageEntryWords)
+	}
+	projectsExpected := []types.ProjectCount{
+		{Project: "Blog", Count: 2},
+		{Project: "Garden", Count: 1},
+	}
+	if !reflect.DeepEqual(stats.TopProjects, projectsExpected) {
+		t.Errorf("TopProjects = %+v, want %+v", stats.TopProjects, projectsExpected)
+	}
+	if stats.CurrentStreak != 3 || stats.LongestStreak != 3 {
+		t.Errorf("streaks = (%d, %d), want (3, 3)", stats.CurrentStreak, stats.LongestStreak)
+	}
+}
+
+func TestUserMeStatsGet(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22T18:00:00Z", Markdown: "# Blog\n\nWrote a post"},
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T18:00:00Z", Markdown: "# Blog\n\nEdited it"},
+		},
+		pageViewCounts: []analytics.PageViewCount{
+			{Path: "/alice/2019-11-22", Views: 10},
+			{Path: "/alice/2019-11-29", Views: 4},
+		},
+		reactions: []types.Reaction{
+			{Username: "bob", Symbol: "👍"},
+			{Username: "carol", Symbol: "🎉"},
+			{Username: "dave", Symbol: ""},
+			{Username: "alice", Symbol: "👍"},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, http.MethodGet, "/api/user/me/stats", "", "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("anonymous request: status=%d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w = sendTeamsRequest(s, http.MethodGet, "/api/user/me/stats", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var stats types.UserStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+	if stats.TotalPageViews != 14 {
+		t.Errorf("TotalPageViews = %d, want 14", stats.TotalPageViews)
+	}
+	viewsExpected := []types.EntryPageViews{
+		{Date: "2019-11-22", Views: 10},
+		{Date: "2019-11-29", Views: 4},
+	}
+	if !reflect.DeepEqual(stats.EntryPageViews, viewsExpected) {
+		t.Errorf("EntryPageViews = %+v, want %+v", stats.EntryPageViews, viewsExpected)
+	}
+	// The mock returns the same reactions for every entry, and the author's own
+	// reaction and the cleared reaction don't count.
+	if stats.ReactionsReceived != 4 {
+		t.Errorf("ReactionsReceived = %d, want 4", stats.ReactionsReceived)
+	}
+	bySymbolExpected := map[string]int{"👍": 2, "🎉": 2}
+	if !reflect.DeepEqual(stats.ReactionsBySymbol, bySymbolExpected) {
+		t.Errorf("ReactionsBySymbol = %v, want %v", stats.ReactionsBySymbol, bySymbolExpected)
+	}
+	if _, ok := ds.userStats["alice"]; !ok {
+		t.Fatalf("expected stats to be cached")
+	}
+
+	// New page views don't appear until the cache expires.
+	ds.pageViewCounts[0].Views = 100
+	w = sendTeamsRequest(s, http.MethodGet, "/api/user/me/stats", "mock_token_A", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+	if stats.TotalPageViews != 14 {
+		t.Errorf("expected cached TotalPageViews of 14, got %d", stats.TotalPageViews)
+	}
+
+	// Editing an entry invalidates the cache.
+	ds.journalEntries[1].LastModified = "2019-11-30T08:00:00Z"
+	w = sendTeamsRequest(s, http.MethodGet, "/api/user/me/stats", "mock_token_A", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+	if stats.TotalPageViews != 104 {
+		t.Errorf("expected recomputed TotalPageViews of 104, got %d", stats.TotalPageViews)
+	}
+}
+
+func TestIsUserStatsFresh(t *testing.T) {
+	now := time.Date(2019, 12, 7, 12, 0, 0, 0, time.UTC)
+	entries := []types.JournalEntry{
+		types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T18:00:00Z"},
+	}
+	cached := types.UserStats{
+		ComputedAt:     "2019-12-07T11:30:00Z",
+		EntryCount:     1,
+		LatestModified: "2019-11-29T18:00:00Z",
+	}
+	if !isUserStatsFresh(cached, entries, now) {
+		t.Errorf("expected recent stats to be fresh")
+	}
+	if isUserStatsFresh(cached, entries, now.Add(time.Hour)) {
+		t.Errorf("expected expired stats to be stale")
+	}
+	if isUserStatsFresh(cached, append(entries, types.JournalEntry{Date: "2019-12-06"}), now) {
+		t.Errorf("expected stats to be stale after a new entry")
+	}
+	if isUserStatsFresh(types.UserStats{}, entries, now) {
+		t.Errorf("expected empty stats to be stale")
+	}
+}
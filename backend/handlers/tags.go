@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/mtlynch/whatgotdone/backend/search"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type tagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// tagEntriesGet lists entries from all users that contain a given hashtag.
+func (s *defaultServer) tagEntriesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tag, err := tagFromRequestPath(r)
+		if err != nil {
+			log.Printf("Failed to retrieve tag from request path: %s", err)
+			http.Error(w, "Invalid tag", http.StatusBadRequest)
+			return
+		}
+
+		refs, err := s.datastore.GetTaggedEntries(tag)
+		if err != nil {
+			log.Printf("Failed to retrieve entries tagged %s: %s", tag, err)
+			http.Error(w, "Failed to retrieve tagged entries", http.StatusInternalServerError)
+			return
+		}
+
+		viewer := s.viewerFromRequest(r)
+		entriesByAuthor := map[string][]types.JournalEntry{}
+		entries := []recentEntry{}
+		for _, ref := range refs {
+			if _, ok := entriesByAuthor[ref.Author]; !ok {
+				userEntries, err := getVisibleEntries(s.datastore, viewer, ref.Author)
+				if err != nil {
+					log.Printf("Failed to retrieve entries for user %s: %s", ref.Author, err)
+					http.Error(w, "Failed to retrieve tagged entries", http.StatusInternalServerError)
+					return
+				}
+				entriesByAuthor[ref.Author] = userEntries
+			}
+			j, ok := findEntryByDate(entriesByAuthor[ref.Author], ref.Date)
+			if !ok {
+				continue
+			}
+			entries = append(entries, recentEntry{
+				Author:       ref.Author,
+				Date:         j.Date,
+				lastModified: j.LastModified,
+				Markdown:     j.Markdown,
+			})
+		}
+
+		entries = sortRecentEntries(entries)
+
+		if err := json.NewEncoder(w).Encode(entries); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// userTagsGet lists every hashtag a user has used in their entries along with
+// the number of entries that contain it, most frequent first.
+func (s *defaultServer) userTagsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequestPath(r)
+		if err != nil {
+			log.Printf("Failed to retrieve username from request path: %s", err)
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		entries, err := getVisibleEntries(s.datastore, s.viewerFromRequest(r), username)
+		if err != nil {
+			log.Printf("Failed to retrieve entries: %s", err)
+			http.Error(w, fmt.Sprintf("Failed to retrieve entries for %s", username), http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(countTags(entries)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func countTags(entries []types.JournalEntry) []tagCount {
+	counts := map[string]int{}
+	for _, j := range entries {
+		for _, tag := range search.ReadTags(j.Markdown) {
+			counts[tag]++
+		}
+	}
+	tagCounts := []tagCount{}
+	for tag, count := range counts {
+		tagCounts = append(tagCounts, tagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tagCounts, func(i, j int) bool {
+		if tagCounts[i].Count != tagCounts[j].Count {
+			return tagCounts[i].Count > tagCounts[j].Count
+		}
+		return tagCounts[i].Tag < tagCounts[j].Tag
+	})
+	return tagCounts
+}
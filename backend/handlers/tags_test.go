@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/search"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetTaggedEntries(tag string) ([]types.EntryRef, error) {
+	refs := []types.EntryRef{}
+	for _, username := range ds.users {
+		for _, j := range ds.journalEntries {
+			for _, t := range search.ReadTags(j.Markdown) {
+				if t == tag {
+					refs = append(refs, types.EntryRef{Author: username, Date: j.Date})
+					break
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+func TestTagEntriesGet(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-15", LastModified: "2019-11-15", Markdown: "* Interviewed a candidate #hiring"},
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29", Markdown: "* Fixed the outage #incident\n* Made an offer #hiring"},
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22", Markdown: "* Took a nap"},
+		},
+		users: []string{"bob"},
+	}
+	router := mux.NewRouter()
+	s := defaultServer{
+		datastore:      &ds,
+		router:         router,
+		csrfMiddleware: dummyCsrfMiddleware(),
+	}
+	s.routes()
+
+	var tests = []struct {
+		explanation        string
+		tag                string
+		httpStatusExpected int
+		entriesExpected    []recentEntry
+	}{
+		{
+			"returns tagged entries in descending date order",
+			"hiring",
+			http.StatusOK,
+			[]recentEntry{
+				recentEntry{Author: "bob", Date: "2019-11-29", Markdown: "* Fixed the outage #incident\n* Made an offer #hiring"},
+				recentEntry{Author: "bob", Date: "2019-11-15", Markdown: "* Interviewed a candidate #hiring"},
+			},
+		},
+		{
+			"returns empty list for unused tag",
+			"offsite",
+			http.StatusOK,
+			[]recentEntry{},
+		},
+		{
+			"rejects invalid tag",
+			"Hiring!",
+			http.StatusBadRequest,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("GET", "/api/tags/"+tt.tag, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+		if tt.httpStatusExpected != http.StatusOK {
+			continue
+		}
+
+		var response []recentEntry
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if !reflect.DeepEqual(response, tt.entriesExpected) {
+			t.Fatalf("%s: Unexpected response: got %v want %v", tt.explanation, response, tt.entriesExpected)
+		}
+	}
+}
+
+func TestUserTagsGet(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-15", LastModified: "2019-11-15", Markdown: "* Interviewed a candidate #hiring"},
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22", Markdown: "* Wrote a postmortem #incident"},
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29", Markdown: "* Fixed the outage #incident\n* Made an offer #hiring #Hiring"},
+			types.JournalEntry{Date: "2019-12-06", LastModified: "2019-12-06", Markdown: "* Planned the #offsite\n* More #hiring"},
+		},
+	}
+	router := mux.NewRouter()
+	s := defaultServer{
+		datastore:      &ds,
+		router:         router,
+		csrfMiddleware: dummyCsrfMiddleware(),
+	}
+	s.routes()
+
+	req, err := http.NewRequest("GET", "/api/entries/bob/tags", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	if status := w.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var response []tagCount
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+
+	expected := []tagCount{
+		tagCount{Tag: "hiring", Count: 3},
+		tagCount{Tag: "incident", Count: 2},
+		tagCount{Tag: "offsite", Count: 1},
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Fatalf("Unexpected response: got %v want %v", response, expected)
+	}
+}
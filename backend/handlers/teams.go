@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+const teamNameMaxLength = 100
+
+func (s defaultServer) teamsOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+// teamsPost creates a new team with the logged-in user as its only admin.
+func (s defaultServer) teamsPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to create a team", http.StatusForbidden)
+			return
+		}
+
+		type teamRequest struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		var tr teamRequest
+		if err := json.NewDecoder(r.Body).Decode(&tr); err != nil {
+			log.Printf("Failed to decode request: %s", err)
+			http.Error(w, "Failed to decode request", http.StatusBadRequest)
+			return
+		}
+		if !validate.TeamID(tr.ID) {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+		name := strings.TrimSpace(tr.Name)
+		if name == "" || utf8.RuneCountInString(name) > teamNameMaxLength {
+			http.Error(w, "Invalid team name", http.StatusBadRequest)
+			return
+		}
+
+		team := types.Team{
+			ID:   tr.ID,
+			Name: name,
+			Members: []types.TeamMember{
+				{Username: username, Role: types.TeamRoleAdmin},
+			},
+			Invitees: []string{},
+		}
+		err = s.datastore.CreateTeam(team)
+		if _, ok := err.(datastore.TeamAlreadyExistsError); ok {
+			http.Error(w, "A team with that ID already exists", http.StatusConflict)
+			return
+		} else if err != nil {
+			log.Printf("Failed to create team %s: %s", tr.ID, err)
+			http.Error(w, "Failed to create team", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(team); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamGet returns a team's name, members, and pending invitations. Only
+// members and invitees can see a team.
+func (s defaultServer) teamGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		team, ok := s.teamForRequest(w, r)
+		if !ok {
+			return
+		}
+
+		viewer := s.viewerFromRequest(r)
+		_, isMember := team.Member(viewer)
+		if !isMember && !isStringInSlice(viewer, team.Invitees) {
+			http.Error(w, "Team not found", http.StatusNotFound)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(team); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamEntriesGet returns the latest entry from each team member that the
+// logged-in user can read, most recent first.
+func (s defaultServer) teamEntriesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		team, ok := s.teamForRequest(w, r)
+		if !ok {
+			return
+		}
+
+		viewer := s.viewerFromRequest(r)
+		if _, isMember := team.Member(viewer); !isMember {
+			http.Error(w, "Team not found", http.StatusNotFound)
+			return
+		}
+
+		entries := []recentEntry{}
+		for _, m := range team.Members {
+			memberEntries, err := getVisibleEntries(s.datastore, viewer, m.Username)
+			if err != nil {
+				log.Printf("Failed to retrieve entries for user %s: %s", m.Username, err)
+				http.Error(w, "Failed to retrieve team entries", http.StatusInternalServerError)
+				return
+			}
+			if len(memberEntries) == 0 {
+				continue
+			}
+			latest := memberEntries[0]
+			for _, j := range memberEntries[1:] {
+				if j.Date > latest.Date {
+					latest = j
+				}
+			}
+			entries = append(entries, recentEntry{
+				Author:       m.Username,
+				Date:         latest.Date,
+				lastModified: latest.LastModified,
+				Markdown:     latest.Markdown,
+			})
+		}
+
+		entries = sortRecentEntries(entries)
+
+		if err := json.NewEncoder(w).Encode(entries); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamInvitationsPost invites a user to join a team. Only team admins can
+// invite users.
+func (s defaultServer) teamInvitationsPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to invite team members", http.StatusForbidden)
+			return
+		}
+
+		type invitationRequest struct {
+			Username string `json:"username"`
+		}
+		var ir invitationRequest
+		if err := json.NewDecoder(r.Body).Decode(&ir); err != nil {
+			log.Printf("Failed to decode request: %s", err)
+			http.Error(w, "Failed to decode request", http.StatusBadRequest)
+			return
+		}
+		if !validate.Username(ir.Username) {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		team, ok := s.updateTeam(w, r, func(team *types.Team) error {
+			if !team.IsAdmin(username) {
+				return teamChangeError{http.StatusForbidden, "Only team admins can invite members"}
+			}
+			if _, isMember := team.Member(ir.Username); isMember {
+				return teamChangeError{http.StatusConflict, "User is already a team member"}
+			}
+			if !isStringInSlice(ir.Username, team.Invitees) {
+				team.Invitees = append(team.Invitees, ir.Username)
+			}
+			return nil
+		})
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(team); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamJoinPost accepts the logged-in user's invitation to join a team.
+func (s defaultServer) teamJoinPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to join a team", http.StatusForbidden)
+			return
+		}
+
+		team, ok := s.updateTeam(w, r, func(team *types.Team) error {
+			if _, isMember := team.Member(username); isMember {
+				return nil
+			}
+			if !isStringInSlice(username, team.Invitees) {
+				return teamChangeError{http.StatusForbidden, "You have not been invited to this team"}
+			}
+			team.Invitees = removeString(team.Invitees, username)
+			team.Members = append(team.Members, types.TeamMember{
+				Username: username,
+				Role:     types.TeamRoleMember,
+			})
+			return nil
+		})
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(team); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamMemberPost changes a team member's role. Only team admins can change
+// roles, and every team must keep at least one admin.
+func (s defaultServer) teamMemberPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to change team roles", http.StatusForbidden)
+			return
+		}
+
+		member, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		type roleRequest struct {
+			Role types.TeamRole `json:"role"`
+		}
+		var rr roleRequest
+		if err := json.NewDecoder(r.Body).Decode(&rr); err != nil {
+			log.Printf("Failed to decode request: %s", err)
+			http.Error(w, "Failed to decode request", http.StatusBadRequest)
+			return
+		}
+		if rr.Role != types.TeamRoleAdmin && rr.Role != types.TeamRoleMember {
+			http.Error(w, "Invalid role", http.StatusBadRequest)
+			return
+		}
+
+		// Check the caller's role and the team's admins in the same
+		// transaction as the change so that two admins demoting each other at
+		// once can't leave the team without an admin.
+		team, ok := s.updateTeam(w, r, func(team *types.Team) error {
+			if !team.IsAdmin(username) {
+				return teamChangeError{http.StatusForbidden, "Only team admins can change roles"}
+			}
+			found := false
+			members := []types.TeamMember{}
+			for _, m := range team.Members {
+				if m.Username == member {
+					m.Role = rr.Role
+					found = true
+				}
+				members = append(members, m)
+			}
+			if !found {
+				return teamChangeError{http.StatusNotFound, "User is not a team member"}
+			}
+			team.Members = members
+			if countAdmins(*team) == 0 {
+				return teamChangeError{http.StatusBadRequest, "A team must have at least one admin"}
+			}
+			return nil
+		})
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(team); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamMemberDelete removes a user from a team. Admins can remove any member,
+// and any member can remove themselves. A team always keeps at least one
+// admin, so the last member and the last admin can't leave.
+func (s defaultServer) teamMemberDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to remove team members", http.StatusForbidden)
+			return
+		}
+
+		member, err := usernameFromRequestPath(r)
+		if err != nil {
+			http.Error(w, "Invalid username", http.StatusBadRequest)
+			return
+		}
+
+		team, ok := s.updateTeam(w, r, func(team *types.Team) error {
+			if member != username && !team.IsAdmin(username) {
+				return teamChangeError{http.StatusForbidden, "Only team admins can remove other members"}
+			}
+			members := []types.TeamMember{}
+			for _, m := range team.Members {
+				if m.Username != member {
+					members = append(members, m)
+				}
+			}
+			if len(members) == len(team.Members) {
+				return teamChangeError{http.StatusNotFound, "User is not a team member"}
+			}
+			team.Members = members
+			if countAdmins(*team) == 0 {
+				return teamChangeError{http.StatusBadRequest, "A team must have at least one admin"}
+			}
+			return nil
+		})
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(team); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// userMeTeamsGet lists the teams that the logged-in user belongs to or has
+// been invited to join.
+func (s defaultServer) userMeTeamsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to see your teams", http.StatusForbidden)
+			return
+		}
+
+		teams, err := s.datastore.GetTeamsForUser(username)
+		if err != nil {
+			log.Printf("Failed to retrieve teams for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve teams", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(teams); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// teamForRequest retrieves the team specified in the request path. If the
+// team can't be retrieved, it writes an error response and returns false.
+func (s defaultServer) teamForRequest(w http.ResponseWriter, r *http.Request) (types.Team, bool) {
+	teamID, err := teamIDFromRequestPath(r)
+	if err != nil {
+		log.Printf("Failed to retrieve team ID from request path: %s", err)
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return types.Team{}, false
+	}
+	team, err := s.datastore.GetTeam(teamID)
+	if _, ok := err.(datastore.TeamNotFoundError); ok {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return types.Team{}, false
+	} else if err != nil {
+		log.Printf("Failed to retrieve team %s: %s", teamID, err)
+		http.Error(w, "Failed to retrieve team", http.StatusInternalServerError)
+		return types.Team{}, false
+	}
+	return team, true
+}
+
+// teamChangeError explains why a team change isn't allowed, along with the
+// HTTP status of the response.
+type teamChangeError struct {
+	status  int
+	message string
+}
+
+func (e teamChangeError) Error() string {
+	return e.message
+}
+
+// updateTeam applies a change to the team specified in the request path in a
+// single datastore transaction, so that concurrent changes to the same team
+// can't overwrite each other. Because the transaction may retry, update must
+// check permissions against the team it receives rather than against an
+// earlier read. If the change fails, it writes an error response and returns
+// false.
+func (s defaultServer) updateTeam(w http.ResponseWriter, r *http.Request, update func(*types.Team) error) (types.Team, bool) {
+	teamID, err := teamIDFromRequestPath(r)
+	if err != nil {
+		log.Printf("Failed to retrieve team ID from request path: %s", err)
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return types.Team{}, false
+	}
+	team, err := s.datastore.UpdateTeam(teamID, update)
+	if _, ok := err.(datastore.TeamNotFoundError); ok {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return types.Team{}, false
+	} else if tce, ok := err.(teamChangeError); ok {
+		http.Error(w, tce.message, tce.status)
+		return types.Team{}, false
+	} else if err != nil {
+		log.Printf("Failed to update team %s: %s", teamID, err)
+		http.Error(w, "Failed to update team", http.StatusInternalServerError)
+		return types.Team{}, false
+	}
+	return team, true
+}
+
+func countAdmins(team types.Team) int {
+	admins := 0
+	for _, m := range team.Members {
+		if m.Role == types.TeamRoleAdmin {
+			admins++
+		}
+	}
+	return admins
+}
+
+func removeString(ss []string, s string) []string {
+	filtered := []string{}
+	for _, x := range ss {
+		if x != s {
+			filtered = append(filtered, x)
+		}
+	}
+	return filtered
+}
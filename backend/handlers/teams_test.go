@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetTeam(teamID string) (types.Team, error) {
+	if team, ok := ds.teams[teamID]; ok {
+		return team, nil
+	}
+	return types.Team{}, datastore.TeamNotFoundError{TeamID: teamID}
+}
+
+func (ds *mockDatastore) CreateTeam(team types.Team) error {
+	if _, ok := ds.teams[team.ID]; ok {
+		return datastore.TeamAlreadyExistsError{TeamID: team.ID}
+	}
+	return ds.SetTeam(team)
+}
+
+func (ds *mockDatastore) SetTeam(team types.Team) error {
+	if ds.teams == nil {
+		ds.teams = map[string]types.Team{}
+	}
+	ds.teams[team.ID] = team
+	return nil
+}
+
+func (ds *mockDatastore) UpdateTeam(teamID string, update func(*types.Team) error) (types.Team, error) {
+	team, err := ds.GetTeam(teamID)
+	if err != nil {
+		return types.Team{}, err
+	}
+	// Copy the team's slices so that a failed update leaves the stored team
+	// unchanged.
+	team.Members = append([]types.TeamMember{}, team.Members...)
+	team.Invitees = append([]string{}, team.Invitees...)
+	if err := update(&team); err != nil {
+		return types.Team{}, err
+	}
+	return team, ds.SetTeam(team)
+}
+
+func (ds *mockDatastore) DeleteTeam(teamID string) error {
+	delete(ds.teams, teamID)
+	return nil
+}
+
+func (ds mockDatastore) GetTeamsForUser(username string) ([]types.Team, error) {
+	teams := []types.Team{}
+	for _, team := range ds.teams {
+		if _, ok := team.Member(username); ok || isStringInSlice(username, team.Invitees) {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func newTeamsTestServer(ds *mockDatastore) defaultServer {
+	router := mux.NewRouter()
+	s := defaultServer{
+		authenticator: mockAuthenticator{
+			tokensToUsers: map[string]string{
+				"mock_token_A": "alice",
+				"mock_token_B": "bob",
+				"mock_token_C": "carol",
+			},
+		},
+		datastore:      ds,
+		router:         router,
+		csrfMiddleware: dummyCsrfMiddleware(),
+	}
+	s.routes()
+	return s
+}
+
+func sendTeamsRequest(s defaultServer, method string, path string, authToken string, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, path, bytes.NewBuffer([]byte(body)))
+	if err != nil {
+		panic(err)
+	}
+	if authToken != "" {
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", userKitAuthCookieName, authToken))
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTeamMembershipLifecycle(t *testing.T) {
+	ds := mockDatastore{}
+	s := newTeamsTestServer(&ds)
+
+	steps := []struct {
+		explanation        string
+		method             string
+		path               string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"anonymous users can't create teams", "POST", "/api/teams", "", `{"id": "acme", "name": "Acme"}`, http.StatusForbidden},
+		{"rejects invalid team ID", "POST", "/api/teams", "mock_token_A", `{"id": "Acme!", "name": "Acme"}`, http.StatusBadRequest},
+		{"rejects blank team name", "POST", "/api/teams", "mock_token_A", `{"id": "acme", "name": " "}`, http.StatusBadRequest},
+		{"alice creates a team", "POST", "/api/teams", "mock_token_A", `{"id": "acme", "name": "Acme"}`, http.StatusOK},
+		{"team IDs are unique", "POST", "/api/teams", "mock_token_B", `{"id": "acme", "name": "Acme 2"}`, http.StatusConflict},
+		{"non-members can't see the team", "GET", "/api/teams/acme", "mock_token_B", "", http.StatusNotFound},
+		{"bob can't join without an invitation", "POST", "/api/teams/acme/join", "mock_token_B", "", http.StatusForbidden},
+		{"non-admins can't invite members", "POST", "/api/teams/acme/invitations", "mock_token_B", `{"username": "bob"}`, http.StatusForbidden},
+		{"alice invites bob", "POST", "/api/teams/acme/invitations", "mock_token_A", `{"username": "bob"}`, http.StatusOK},
+		{"invitees can see the team", "GET", "/api/teams/acme", "mock_token_B", "", http.StatusOK},
+		{"bob joins", "POST", "/api/teams/acme/join", "mock_token_B", "", http.StatusOK},
+		{"members can't change roles", "POST", "/api/teams/acme/members/alice", "mock_token_B", `{"role": "member"}`, http.StatusForbidden},
+		{"rejects invalid roles", "POST", "/api/teams/acme/members/bob", "mock_token_A", `{"role": "owner"}`, http.StatusBadRequest},
+		{"can't demote the last admin", "POST", "/api/teams/acme/members/alice", "mock_token_A", `{"role": "member"}`, http.StatusBadRequest},
+		{"alice promotes bob", "POST", "/api/teams/acme/members/bob", "mock_token_A", `{"role": "admin"}`, http.StatusOK},
+		{"bob removes alice", "DELETE", "/api/teams/acme/members/alice", "mock_token_B", "", http.StatusOK},
+		{"the last admin can't leave", "DELETE", "/api/teams/acme/members/bob", "mock_token_B", "", http.StatusBadRequest},
+		{"removing a non-member fails", "DELETE", "/api/teams/acme/members/carol", "mock_token_B", "", http.StatusNotFound},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, step.method, step.path, step.authToken, step.body)
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v (%s)",
+				step.explanation, status, step.httpStatusExpected, w.Body.String())
+		}
+	}
+
+	expected := types.Team{
+		ID:   "acme",
+		Name: "Acme",
+		Members: []types.TeamMember{
+			types.TeamMember{Username: "bob", Role: types.TeamRoleAdmin},
+		},
+		Invitees: []string{},
+	}
+	if !reflect.DeepEqual(ds.teams["acme"], expected) {
+		t.Fatalf("Unexpected team: got %v want %v", ds.teams["acme"], expected)
+	}
+}
+
+func TestTeamEntriesGetReturnsLatestEntryPerMember(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22", Markdown: "Wrote the spec"},
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29", Markdown: "Shipped the feature", Visibility: types.VisibilityTeam, TeamID: "acme"},
+		},
+		teams: map[string]types.Team{
+			"acme": types.Team{
+				ID:   "acme",
+				Name: "Acme",
+				Members: []types.TeamMember{
+					types.TeamMember{Username: "alice", Role: types.TeamRoleAdmin},
+					types.TeamMember{Username: "bob", Role: types.TeamRoleMember},
+				},
+			},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, "GET", "/api/teams/acme/entries", "mock_token_C", "")
+	if status := w.Code; status != http.StatusNotFound {
+		t.Fatalf("non-member request returned wrong status code: got %v want %v",
+			status, http.StatusNotFound)
+	}
+
+	w = sendTeamsRequest(s, "GET", "/api/teams/acme/entries", "mock_token_A", "")
+	if status := w.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var response []recentEntry
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+
+	// For simplicity of the test, both members share the same entries, so sort
+	// by author to make the order deterministic.
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].Author < response[j].Author
+	})
+	expected := []recentEntry{
+		recentEntry{Author: "alice", Date: "2019-11-29", Markdown: "Shipped the feature"},
+		recentEntry{Author: "bob", Date: "2019-11-29", Markdown: "Shipped the feature"},
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Fatalf("Unexpected response: got %v want %v", response, expected)
+	}
+}
+
+func TestTeamEntriesAreHiddenFromNonMembers(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29", Markdown: "Shipped the feature", Visibility: types.VisibilityTeam, TeamID: "acme"},
+		},
+		teams: map[string]types.Team{
+			"acme": types.Team{
+				ID: "acme",
+				Members: []types.TeamMember{
+					types.TeamMember{Username: "alice", Role: types.TeamRoleAdmin},
+					types.TeamMember{Username: "bob", Role: types.TeamRoleMember},
+				},
+			},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	for _, tt := range []struct {
+		authToken     string
+		countExpected int
+	}{
+		{"", 0},
+		{"mock_token_C", 0},
+		{"mock_token_B", 1},
+	} {
+		w := sendTeamsRequest(s, "GET", "/api/entries/alice", tt.authToken, "")
+		var response []types.JournalEntry
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if len(response) != tt.countExpected {
+			t.Fatalf("for token [%s], unexpected entry count: got %v want %v", tt.authToken, len(response), tt.countExpected)
+		}
+	}
+}
+
+func TestEntryPostValidatesTeamVisibility(t *testing.T) {
+	ds := mockDatastore{
+		teams: map[string]types.Team{
+			"acme": types.Team{
+				ID: "acme",
+				Members: []types.TeamMember{
+					types.TeamMember{Username: "alice", Role: types.TeamRoleAdmin},
+				},
+			},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	for _, tt := range []struct {
+		explanation        string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"members can publish to their team", "mock_token_A", `{"entryContent": "hi", "visibility": "team", "teamId": "acme"}`, http.StatusOK},
+		{"non-members can't publish to a team", "mock_token_B", `{"entryContent": "hi", "visibility": "team", "teamId": "acme"}`, http.StatusForbidden},
+		{"team must exist", "mock_token_A", `{"entryContent": "hi", "visibility": "team", "teamId": "nope"}`, http.StatusBadRequest},
+		{"team visibility requires a team", "mock_token_A", `{"entryContent": "hi", "visibility": "team"}`, http.StatusBadRequest},
+		{"other visibilities can't specify a team", "mock_token_A", `{"entryContent": "hi", "visibility": "public", "teamId": "acme"}`, http.StatusBadRequest},
+		{"rejects unknown visibility", "mock_token_A", `{"entryContent": "hi", "visibility": "friends"}`, http.StatusBadRequest},
+	} {
+		w := sendTeamsRequest(s, "POST", "/api/entry/2019-11-29", tt.authToken, tt.body)
+		if status := w.Code; status != tt.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, tt.httpStatusExpected)
+		}
+	}
+}
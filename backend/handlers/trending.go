@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type trendingEntry struct {
+	Author    string `json:"author"`
+	Date      string `json:"date"`
+	Markdown  string `json:"markdown"`
+	Views     int    `json:"views"`
+	Reactions int    `json:"reactions"`
+}
+
+// trendingGet returns recently published entries ordered by a score that
+// combines page views and reactions and decays as entries age. The ranking
+// comes from the cache that the rank-trending-entries job updates, so this
+// handler only reads the entries of authors it needs to fill the requested
+// page. It accepts the same start and limit parameters as recentEntriesGet.
+func (s *defaultServer) trendingGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start, err := parseStart(r.URL.Query().Get("start"))
+		if err != nil {
+			http.Error(w, "Invalid start parameter", http.StatusBadRequest)
+			return
+		}
+		limit, err := parseLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+
+		ranking, err := s.datastore.GetTrendingEntries()
+		if _, ok := err.(datastore.TrendingEntriesNotFoundError); ok {
+			ranking = types.TrendingEntries{}
+		} else if err != nil {
+			log.Printf("Failed to retrieve trending entries: %s", err)
+			http.Error(w, "Failed to retrieve entries", http.StatusInternalServerError)
+			return
+		}
+
+		viewer := s.viewerFromRequest(r)
+		visibleEntries := map[string][]types.JournalEntry{}
+		entries := []trendingEntry{}
+		for _, t := range ranking.Entries {
+			if len(entries) >= start+limit {
+				break
+			}
+			authorEntries, ok := visibleEntries[t.Author]
+			if !ok {
+				authorEntries, err = getVisibleEntries(s.datastore, viewer, t.Author)
+				if err != nil {
+					log.Printf("Failed to retrieve entries for user %s: %s", t.Author, err)
+					http.Error(w, "Failed to retrieve entries", http.StatusInternalServerError)
+					return
+				}
+				visibleEntries[t.Author] = authorEntries
+			}
+			// The ranking may be a few minutes old, so skip entries that have since
+			// been deleted, hidden, or shortened.
+			j, ok := findEntryByDate(authorEntries, t.Date)
+			if !ok || len(j.Markdown) < minimumRelevantLength {
+				continue
+			}
+			entries = append(entries, trendingEntry{
+				Author:    t.Author,
+				Date:      t.Date,
+				Markdown:  j.Markdown,
+				Views:     t.Views,
+				Reactions: t.Reactions,
+			})
+		}
+
+		entries = paginateTrendingEntries(entries, start, limit)
+
+		if err := json.NewEncoder(w).Encode(entries); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func paginateTrendingEntries(entries []trendingEntry, start, limit int) []trendingEntry {
+	start = min(len(entries), start)
+	end := min(len(entries), start+limit)
+	return entries[start:end]
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/analytics"
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/trending"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func (ds mockDatastore) GetTrendingEntries() (types.TrendingEntries, error) {
+	if ds.trending == nil {
+		return types.TrendingEntries{}, datastore.TrendingEntriesNotFoundError{}
+	}
+	return *ds.trending, nil
+}
+
+func (ds *mockDatastore) SetTrendingEntries(t types.TrendingEntries) error {
+	ds.trending = &t
+	return nil
+}
+
+func TestTrendingGet(t *testing.T) {
+	daysAgo := func(n int) string {
+		return time.Now().AddDate(0, 0, -n).Format("2006-01-02")
+	}
+	ds := mockDatastore{
+		users: []string{"alice"},
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: daysAgo(1), Markdown: "Wrote a short story about a lighthouse"},
+			types.JournalEntry{Date: daysAgo(8), Markdown: "Launched my new website to the world"},
+			types.JournalEntry{Date: daysAgo(15), Markdown: "Repainted the kitchen a nice shade of blue"},
+			types.JournalEntry{Date: daysAgo(2), Markdown: "Too short"},
+			types.JournalEntry{Date: daysAgo(60), Markdown: "Wrote a viral post that everyone read"},
+			types.JournalEntry{Date: daysAgo(3), Markdown: "Drafted my private plans for next year", Visibility: types.VisibilityPrivate},
+		},
+		pageViewCounts: []analytics.PageViewCount{
+			{Path: "/alice/" + daysAgo(1), Views: 10},
+			{Path: "/alice/" + daysAgo(8), Views: 200},
+			{Path: "/alice/" + daysAgo(15), Views: 5},
+			{Path: "/alice/" + daysAgo(2), Views: 1000},
+			{Path: "/alice/" + daysAgo(60), Views: 100000},
+			{Path: "/alice/" + daysAgo(3), Views: 5000},
+		},
+		reactions: []types.Reaction{
+			{Username: "bob", Symbol: "👍"},
+			{Username: "alice", Symbol: "🎉"},
+			{Username: "carol", Symbol: ""},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, http.MethodGet, "/api/trending?start=0&limit=10", "", "")
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Fatalf("expected no entries before the ranking job runs, got status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	if err := trending.New(&ds, minimumRelevantLength).Update(time.Now()); err != nil {
+		t.Fatalf("failed to rank trending entries: %v", err)
+	}
+
+	var tests = []struct {
+		explanation   string
+		query         string
+		token         string
+		datesExpected []string
+	}{
+		{
+			"ranks recent entries by score",
+			"?start=0&limit=10",
+			"",
+			[]string{daysAgo(8), daysAgo(1), daysAgo(15)},
+		},
+		{
+			"includes the author's private entry when they're logged in",
+			"?start=0&limit=10",
+			"mock_token_A",
+			[]string{daysAgo(3), daysAgo(8), daysAgo(1), daysAgo(15)},
+		},
+		{
+			"paginates results",
+			"?start=1&limit=1",
+			"",
+			[]string{daysAgo(1)},
+		},
+		{
+			"start beyond the end returns no entries",
+			"?start=5&limit=10",
+			"",
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		w := sendTeamsRequest(s, http.MethodGet, "/api/trending"+tt.query, tt.token, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status=%d, want %d", tt.explanation, w.Code, http.StatusOK)
+		}
+		var response []trendingEntry
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: response is not valid JSON: %s", tt.explanation, w.Body.String())
+		}
+		dates := []string{}
+		for _, e := range response {
+			dates = append(dates, e.Date)
+			// Only bob's reaction counts: alice reacted to her own entry, and
+			// carol cleared her reaction.
+			if e.Reactions != 1 {
+				t.Errorf("%s: entry %s has %d reactions, want 1", tt.explanation, e.Date, e.Reactions)
+			}
+		}
+		if !reflect.DeepEqual(dates, tt.datesExpected) {
+			t.Errorf("%s: got %v, want %v", tt.explanation, dates, tt.datesExpected)
+		}
+	}
+}
+
+func TestTrendingGetRejectsInvalidParameters(t *testing.T) {
+	ds := mockDatastore{}
+	s := newTeamsTestServer(&ds)
+	for _, query := range []string{"", "?start=0", "?limit=10", "?start=-1&limit=10", "?start=0&limit=0"} {
+		w := sendTeamsRequest(s, http.MethodGet, "/api/trending"+query, "", "")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("query %q: status=%d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -27,6 +28,45 @@ func dateFromRequestPath(r *http.Request) (string, error) {
 	return date, nil
 }
 
+// isValidDateParameter returns true if the given query parameter value is a
+// date in YYYY-MM-DD format.
+func isValidDateParameter(date string) bool {
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+func tagFromRequestPath(r *http.Request) (string, error) {
+	tag := mux.Vars(r)["tag"]
+	if !validate.Tag(tag) {
+		return "", errors.New("Invalid tag")
+	}
+	return tag, nil
+}
+
+func teamIDFromRequestPath(r *http.Request) (string, error) {
+	teamID := mux.Vars(r)["teamID"]
+	if !validate.TeamID(teamID) {
+		return "", errors.New("Invalid team ID")
+	}
+	return teamID, nil
+}
+
+func webhookIDFromRequestPath(r *http.Request) (string, error) {
+	webhookID := mux.Vars(r)["webhookID"]
+	if !regexp.MustCompile("^[0-9a-f]{16}$").MatchString(webhookID) {
+		return "", errors.New("Invalid webhook ID")
+	}
+	return webhookID, nil
+}
+
+func commentIDFromRequestPath(r *http.Request) (string, error) {
+	commentID := mux.Vars(r)["commentID"]
+	if !regexp.MustCompile("^[0-9a-f]{16}$").MatchString(commentID) {
+		return "", errors.New("Invalid comment ID")
+	}
+	return commentID, nil
+}
+
 func projectFromRequestPath(r *http.Request) (string, error) {
 	return mux.Vars(r)["project"], nil
 }
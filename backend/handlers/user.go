@@ -35,15 +35,17 @@ func (s defaultServer) userGet() http.HandlerFunc {
 		}
 
 		type userResponse struct {
-			AboutMarkdown string `json:"aboutMarkdown"`
-			TwitterHandle string `json:"twitterHandle"`
-			EmailAddress  string `json:"emailAddress"`
+			AboutMarkdown     string           `json:"aboutMarkdown"`
+			TwitterHandle     string           `json:"twitterHandle"`
+			EmailAddress      string           `json:"emailAddress"`
+			DefaultVisibility types.Visibility `json:"defaultVisibility"`
 		}
 
 		resp := userResponse{
-			AboutMarkdown: p.AboutMarkdown,
-			TwitterHandle: p.TwitterHandle,
-			EmailAddress:  p.EmailAddress,
+			AboutMarkdown:     p.AboutMarkdown,
+			TwitterHandle:     p.TwitterHandle,
+			EmailAddress:      p.EmailAddress,
+			DefaultVisibility: p.DefaultVisibility,
 		}
 
 		if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -82,6 +84,11 @@ func (s defaultServer) userPost() http.HandlerFunc {
 			return
 		}
 
+		if !isValidDefaultVisibility(userProfile.DefaultVisibility) {
+			http.Error(w, "Invalid default visibility", http.StatusBadRequest)
+			return
+		}
+
 		err = s.datastore.SetUserProfile(username, userProfile)
 		if err != nil {
 			log.Printf("Failed to update user profile: %s", err)
@@ -125,9 +132,10 @@ func (s defaultServer) userMeGet() http.HandlerFunc {
 
 func profileFromRequest(r *http.Request) (types.UserProfile, error) {
 	type profileUpdateRequest struct {
-		AboutMarkdown string `json:"aboutMarkdown"`
-		EmailAddress  string `json:"emailAddress"`
-		TwitterHandle string `json:"twitterHandle"`
+		AboutMarkdown     string           `json:"aboutMarkdown"`
+		EmailAddress      string           `json:"emailAddress"`
+		TwitterHandle     string           `json:"twitterHandle"`
+		DefaultVisibility types.Visibility `json:"defaultVisibility"`
 	}
 	var pur profileUpdateRequest
 	decoder := json.NewDecoder(r.Body)
@@ -136,8 +144,9 @@ func profileFromRequest(r *http.Request) (types.UserProfile, error) {
 		return types.UserProfile{}, err
 	}
 	return types.UserProfile{
-		AboutMarkdown: strings.TrimSpace(pur.AboutMarkdown),
-		EmailAddress:  pur.EmailAddress,
-		TwitterHandle: pur.TwitterHandle,
+		AboutMarkdown:     strings.TrimSpace(pur.AboutMarkdown),
+		EmailAddress:      pur.EmailAddress,
+		TwitterHandle:     pur.TwitterHandle,
+		DefaultVisibility: pur.DefaultVisibility,
 	}, nil
 }
@@ -16,6 +16,10 @@ func (ds mockDatastore) GetUserProfile(username string) (types.UserProfile, erro
 	return ds.userProfile, nil
 }
 
+func (ds mockDatastore) DeleteUserProfile(username string) error {
+	return nil
+}
+
 func (ds *mockDatastore) SetUserProfile(username string, p types.UserProfile) error {
 	ds.userProfile = p
 	return nil
@@ -71,6 +75,15 @@ func TestUserPost(t *testing.T) {
 				EmailAddress: "hi@example.com",
 			},
 		},
+		// Accept an update with a default visibility.
+		{
+			`{ "aboutMarkdown": "I'm a little teapot", "defaultVisibility": "signedIn" }`,
+			http.StatusOK,
+			types.UserProfile{
+				AboutMarkdown:     "I'm a little teapot",
+				DefaultVisibility: types.VisibilitySignedIn,
+			},
+		},
 		// When request body is empty dict, store empty profile.
 		{
 			`{ }`,
@@ -101,6 +114,12 @@ func TestUserPost(t *testing.T) {
 			http.StatusBadRequest,
 			types.UserProfile{},
 		},
+		// If the request contains an unrecognized default visibility, reject it.
+		{
+			`{ "aboutMarkdown": "I'm a little teapot", "defaultVisibility": "friends" }`,
+			http.StatusBadRequest,
+			types.UserProfile{},
+		},
 		// If the request contains an illegal bio, reject it.
 		{
 			`{ "aboutMarkdown": "# Headings are invalid", "twitterHandle": "someTweeter", "emailAddress": "hi@example.com" }`,
@@ -0,0 +1,33 @@
+package validate
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CommentMaxLength is the maximum allowable length of a comment (in
+// characters).
+const CommentMaxLength = 2000
+
+// Comment validates that a comment's markdown body is valid. Comments follow
+// the same formatting restrictions as user bios.
+func Comment(markdown string) bool {
+	if strings.TrimSpace(markdown) == "" {
+		return false
+	}
+	if utf8.RuneCountInString(markdown) > CommentMaxLength {
+		return false
+	}
+	invalidPatterns := []string{
+		"```",       // fenced code block
+		"!\\[.*\\]", // image
+		"(?m)^#",    // heading
+	}
+	for _, p := range invalidPatterns {
+		if regexp.MustCompile(p).MatchString(markdown) {
+			return false
+		}
+	}
+	return true
+}
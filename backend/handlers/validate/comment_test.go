@@ -0,0 +1,72 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComment(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		markdown      string
+		validExpected bool
+	}{
+		{
+			"plain text is valid",
+			"How did you decide on the pricing?",
+			true,
+		},
+		{
+			"markdown formatting and links are valid",
+			"Nice work on **billing**! See [the docs](https://example.com).",
+			true,
+		},
+		{
+			"multiline comment is valid",
+			"Two questions:\n\n* Who owns this?\n* When does it ship?",
+			true,
+		},
+		{
+			"empty comment is invalid",
+			"",
+			false,
+		},
+		{
+			"whitespace-only comment is invalid",
+			"  \n ",
+			false,
+		},
+		{
+			"comment at max length is valid",
+			strings.Repeat("a", CommentMaxLength),
+			true,
+		},
+		{
+			"comment over max length is invalid",
+			strings.Repeat("a", CommentMaxLength+1),
+			false,
+		},
+		{
+			"comment with heading is invalid",
+			"Great update\n# Questions",
+			false,
+		},
+		{
+			"comment with image is invalid",
+			"![cat](https://example.com/cat.png)",
+			false,
+		},
+		{
+			"comment with fenced code block is invalid",
+			"```\ncode\n```",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		validActual := Comment(tt.markdown)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.markdown, validActual, tt.validExpected)
+		}
+	}
+}
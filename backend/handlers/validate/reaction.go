@@ -0,0 +1,47 @@
+package validate
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxReactionSymbols is the maximum number of symbols in a reaction set.
+const MaxReactionSymbols = 12
+
+// maxReactionSymbolLength is the maximum length of a reaction symbol in
+// runes. Some emoji are sequences of several code points joined together, so
+// this allows more than a single rune.
+const maxReactionSymbolLength = 8
+
+// ReactionSymbol validates that a reaction symbol is a short sequence of
+// non-ASCII, non-whitespace characters, such as an emoji.
+func ReactionSymbol(symbol string) bool {
+	if symbol == "" || !utf8.ValidString(symbol) {
+		return false
+	}
+	if utf8.RuneCountInString(symbol) > maxReactionSymbolLength {
+		return false
+	}
+	for _, c := range symbol {
+		if c < utf8.RuneSelf || unicode.IsSpace(c) || unicode.IsControl(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReactionSymbols validates that a reaction set is non-empty, contains no
+// duplicates, and contains only valid reaction symbols.
+func ReactionSymbols(symbols []string) bool {
+	if len(symbols) == 0 || len(symbols) > MaxReactionSymbols {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, s := range symbols {
+		if !ReactionSymbol(s) || seen[s] {
+			return false
+		}
+		seen[s] = true
+	}
+	return true
+}
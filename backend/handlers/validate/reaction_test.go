@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"testing"
+)
+
+func TestReactionSymbol(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		symbol        string
+		validExpected bool
+	}{
+		{
+			"single emoji is valid",
+			"👍",
+			true,
+		},
+		{
+			"emoji with skin tone modifier is valid",
+			"👍🏽",
+			true,
+		},
+		{
+			"emoji joined with zero-width joiners is valid",
+			"👩‍💻",
+			true,
+		},
+		{
+			"empty string is invalid",
+			"",
+			false,
+		},
+		{
+			"ASCII punctuation is invalid",
+			"!",
+			false,
+		},
+		{
+			"ASCII letters are invalid",
+			"lol",
+			false,
+		},
+		{
+			"emoji with whitespace is invalid",
+			"👍 ",
+			false,
+		},
+		{
+			"long sequence of emoji is invalid",
+			"🎉🎉🎉🎉🎉🎉🎉🎉🎉",
+			false,
+		},
+		{
+			"invalid UTF-8 is invalid",
+			"\xff",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		valid := ReactionSymbol(tt.symbol)
+		if valid != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.symbol, valid, tt.validExpected)
+		}
+	}
+}
+
+func TestReactionSymbols(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		symbols       []string
+		validExpected bool
+	}{
+		{
+			"default set is valid",
+			[]string{"👍", "🙁", "🎉"},
+			true,
+		},
+		{
+			"single symbol is valid",
+			[]string{"🚀"},
+			true,
+		},
+		{
+			"empty set is invalid",
+			[]string{},
+			false,
+		},
+		{
+			"set with duplicates is invalid",
+			[]string{"👍", "🎉", "👍"},
+			false,
+		},
+		{
+			"set with an invalid symbol is invalid",
+			[]string{"👍", "+1"},
+			false,
+		},
+		{
+			"set with too many symbols is invalid",
+			[]string{"😀", "😁", "😂", "😃", "😄", "😅", "😆", "😇", "😈", "😉", "😊", "😋", "😌"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		valid := ReactionSymbols(tt.symbols)
+		if valid != tt.validExpected {
+			t.Errorf("%s: input %v, got %v, want %v", tt.explanation, tt.symbols, valid, tt.validExpected)
+		}
+	}
+}
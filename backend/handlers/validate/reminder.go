@@ -0,0 +1,24 @@
+package validate
+
+import (
+	"time"
+)
+
+// ReminderTime validates that a reminder send time is a valid time of day in
+// 24-hour HH:MM format.
+func ReminderTime(t string) bool {
+	if len(t) != len("15:04") {
+		return false
+	}
+	_, err := time.Parse("15:04", t)
+	return err == nil
+}
+
+// TimeZone validates that a time zone is a valid IANA time zone name.
+func TimeZone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
@@ -0,0 +1,102 @@
+package validate
+
+import (
+	"testing"
+)
+
+func TestReminderTime(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		sendTime      string
+		validExpected bool
+	}{
+		{
+			"afternoon time is valid",
+			"17:30",
+			true,
+		},
+		{
+			"midnight is valid",
+			"00:00",
+			true,
+		},
+		{
+			"empty string is invalid",
+			"",
+			false,
+		},
+		{
+			"time without leading zero is invalid",
+			"9:00",
+			false,
+		},
+		{
+			"12-hour time is invalid",
+			"5:30pm",
+			false,
+		},
+		{
+			"out of range hour is invalid",
+			"24:00",
+			false,
+		},
+		{
+			"time with seconds is invalid",
+			"17:30:00",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		validActual := ReminderTime(tt.sendTime)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.sendTime, validActual, tt.validExpected)
+		}
+	}
+}
+
+func TestTimeZone(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		timeZone      string
+		validExpected bool
+	}{
+		{
+			"UTC is valid",
+			"UTC",
+			true,
+		},
+		{
+			"IANA time zone name is valid",
+			"America/New_York",
+			true,
+		},
+		{
+			"empty string is invalid",
+			"",
+			false,
+		},
+		{
+			"server local time zone is invalid",
+			"Local",
+			false,
+		},
+		{
+			"unknown time zone is invalid",
+			"Mars/Olympus_Mons",
+			false,
+		},
+		{
+			"path traversal is invalid",
+			"../../etc/passwd",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		validActual := TimeZone(tt.timeZone)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.timeZone, validActual, tt.validExpected)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package validate
+
+import (
+	"strings"
+)
+
+// slackWebhookURLPrefix is the prefix of every Slack incoming webhook URL.
+const slackWebhookURLPrefix = "https://hooks.slack.com/"
+
+// SlackWebhookURL validates that a URL is a Slack incoming webhook URL.
+func SlackWebhookURL(u string) bool {
+	return WebhookURL(u) && strings.HasPrefix(u, slackWebhookURLPrefix)
+}
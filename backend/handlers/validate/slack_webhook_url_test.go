@@ -0,0 +1,51 @@
+package validate
+
+import (
+	"testing"
+)
+
+func TestSlackWebhookURL(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		url           string
+		validExpected bool
+	}{
+		{
+			"Slack incoming webhook URL is valid",
+			"https://hooks.slack.com/services/T000/B000/XXXX",
+			true,
+		},
+		{
+			"HTTP Slack URL is invalid",
+			"http://hooks.slack.com/services/T000/B000/XXXX",
+			false,
+		},
+		{
+			"non-Slack URL is invalid",
+			"https://example.com/services/T000/B000/XXXX",
+			false,
+		},
+		{
+			"host that starts with Slack's host is invalid",
+			"https://hooks.slack.com.example.com/services/T000/B000/XXXX",
+			false,
+		},
+		{
+			"internal URL is invalid",
+			"http://169.254.169.254/latest/meta-data/",
+			false,
+		},
+		{
+			"empty string is invalid",
+			"",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		validActual := SlackWebhookURL(tt.url)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.url, validActual, tt.validExpected)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package validate
+
+import "github.com/mtlynch/whatgotdone/backend/search"
+
+// Tag validates that a hashtag (without its leading #) is valid. Tags must
+// start with a lowercase letter and contain only lowercase letters, numbers,
+// hyphens, and underscores. Valid tags are exactly the ones that the tag index
+// can hold.
+func Tag(tag string) bool {
+	return search.ValidTag(tag)
+}
@@ -0,0 +1,55 @@
+package validate
+
+import (
+	"testing"
+)
+
+func TestTag(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		tag           string
+		validExpected bool
+	}{
+		{
+			"simple tag is valid",
+			"hiring",
+			true,
+		},
+		{
+			"tag with digits, hyphens, and underscores is valid",
+			"q4-planning_2019",
+			true,
+		},
+		{
+			"empty string is invalid",
+			"",
+			false,
+		},
+		{
+			"tag with leading # is invalid",
+			"#hiring",
+			false,
+		},
+		{
+			"tag starting with a digit is invalid",
+			"2019",
+			false,
+		},
+		{
+			"tag with uppercase letters is invalid",
+			"Hiring",
+			false,
+		},
+		{
+			"tag with spaces is invalid",
+			"job post",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		validActual := Tag(tt.tag)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.tag, validActual, tt.validExpected)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package validate
+
+import "regexp"
+
+// TeamID validates that a team ID is valid. Team IDs appear in URLs, so they
+// are limited to 2-40 lowercase letters, numbers, and hyphens, and they must
+// start with a letter.
+func TeamID(id string) bool {
+	re := regexp.MustCompile("^[a-z][a-z0-9-]{1,39}$")
+	return re.MatchString(id)
+}
@@ -0,0 +1,60 @@
+package validate
+
+import (
+	"testing"
+)
+
+func TestTeamID(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		id            string
+		validExpected bool
+	}{
+		{
+			"simple team ID is valid",
+			"acme",
+			true,
+		},
+		{
+			"team ID with digits and hyphens is valid",
+			"acme-platform-2",
+			true,
+		},
+		{
+			"empty string is invalid",
+			"",
+			false,
+		},
+		{
+			"single-character team ID is invalid",
+			"a",
+			false,
+		},
+		{
+			"team ID with more than 40 characters is invalid",
+			"abcdefghijklmnopqrstuvwxyzabcdefghijklmno",
+			false,
+		},
+		{
+			"team ID starting with a digit is invalid",
+			"2acme",
+			false,
+		},
+		{
+			"team ID with uppercase letters is invalid",
+			"Acme",
+			false,
+		},
+		{
+			"team ID with underscores is invalid",
+			"acme_platform",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		validActual := TeamID(tt.id)
+		if validActual != tt.validExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.id, validActual, tt.validExpected)
+		}
+	}
+}
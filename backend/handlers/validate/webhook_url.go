@@ -0,0 +1,23 @@
+package validate
+
+import (
+	"net/url"
+)
+
+// maxWebhookURLLength is the maximum length of a webhook URL.
+const maxWebhookURLLength = 2000
+
+// WebhookURL validates that a webhook URL is an absolute HTTP or HTTPS URL.
+func WebhookURL(u string) bool {
+	if len(u) > maxWebhookURLLength {
+		return false
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+	return parsed.Host != "" && parsed.User == nil
+}
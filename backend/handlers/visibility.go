@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// anonymousViewer represents a reader who is not logged in.
+const anonymousViewer = ""
+
+// viewerFromRequest returns the username of the logged-in user making the
+// request or anonymousViewer if the request has no valid session.
+func (s defaultServer) viewerFromRequest(r *http.Request) string {
+	username, err := s.loggedInUser(r)
+	if err != nil {
+		return anonymousViewer
+	}
+	return username
+}
+
+// filterVisibleEntries returns the subset of an author's entries that the
+// given viewer is allowed to read.
+func filterVisibleEntries(ds datastore.Datastore, viewer string, author string, entries []types.JournalEntry) ([]types.JournalEntry, error) {
+	if viewer != anonymousViewer && viewer == author {
+		return entries, nil
+	}
+	defaultVisibility, err := authorDefaultVisibility(ds, author)
+	if err != nil {
+		return nil, err
+	}
+	membership := teamMembershipChecker{ds: ds, teams: map[string]types.Team{}}
+	visible := []types.JournalEntry{}
+	for _, j := range entries {
+		v := j.Visibility
+		if v == types.VisibilityDefault {
+			v = defaultVisibility
+		}
+		if v == types.VisibilityTeam {
+			isMember, err := membership.isMember(j.TeamID, viewer)
+			if err != nil {
+				return nil, err
+			}
+			if isMember {
+				visible = append(visible, j)
+			}
+			continue
+		}
+		if canView(viewer, author, v) {
+			visible = append(visible, j)
+		}
+	}
+	return visible, nil
+}
+
+// getVisibleEntries retrieves all of an author's published entries that the
+// given viewer is allowed to read.
+func getVisibleEntries(ds datastore.Datastore, viewer string, author string) ([]types.JournalEntry, error) {
+	entries, err := ds.GetEntries(author)
+	if err != nil {
+		return nil, err
+	}
+	return filterVisibleEntries(ds, viewer, author, entries)
+}
+
+// isEntryHidden returns true if the given entry exists but the viewer is not
+// allowed to read it.
+func isEntryHidden(ds datastore.Datastore, viewer string, author string, date string) (bool, error) {
+	entries, err := ds.GetEntries(author)
+	if err != nil {
+		return false, err
+	}
+	j, ok := findEntryByDate(entries, date)
+	if !ok {
+		return false, nil
+	}
+	visible, err := filterVisibleEntries(ds, viewer, author, []types.JournalEntry{j})
+	if err != nil {
+		return false, err
+	}
+	return len(visible) == 0, nil
+}
+
+func authorDefaultVisibility(ds datastore.Datastore, author string) (types.Visibility, error) {
+	p, err := ds.GetUserProfile(author)
+	if _, ok := err.(datastore.UserProfileNotFoundError); ok {
+		return types.VisibilityPublic, nil
+	} else if err != nil {
+		return types.VisibilityDefault, err
+	}
+	if p.DefaultVisibility == types.VisibilityDefault {
+		return types.VisibilityPublic, nil
+	}
+	return p.DefaultVisibility, nil
+}
+
+func canView(viewer string, author string, v types.Visibility) bool {
+	switch v {
+	case types.VisibilityPublic:
+		return true
+	case types.VisibilitySignedIn:
+		return viewer != anonymousViewer
+	case types.VisibilityPrivate:
+		return viewer != anonymousViewer && viewer == author
+	}
+	return false
+}
+
+// isValidDefaultVisibility returns true if v is a valid default visibility for
+// a user's entries. Team visibility can only apply to individual entries
+// because each entry specifies its own team.
+func isValidDefaultVisibility(v types.Visibility) bool {
+	switch v {
+	case types.VisibilityDefault, types.VisibilityPublic, types.VisibilitySignedIn, types.VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// teamMembershipChecker checks whether users belong to teams, retrieving each
+// team from the datastore at most once.
+type teamMembershipChecker struct {
+	ds    datastore.Datastore
+	teams map[string]types.Team
+}
+
+func (c teamMembershipChecker) isMember(teamID string, username string) (bool, error) {
+	if username == anonymousViewer || teamID == "" {
+		return false, nil
+	}
+	team, ok := c.teams[teamID]
+	if !ok {
+		var err error
+		team, err = c.ds.GetTeam(teamID)
+		if _, ok := err.(datastore.TeamNotFoundError); ok {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		c.teams[teamID] = team
+	}
+	_, isMember := team.Member(username)
+	return isMember, nil
+}
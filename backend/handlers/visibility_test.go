@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+func TestEntriesHandlerObservesVisibility(t *testing.T) {
+	publicEntry := types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29", Markdown: "Shipped the billing page", Visibility: types.VisibilityPublic}
+	signedInEntry := types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22", Markdown: "Negotiated with a vendor", Visibility: types.VisibilitySignedIn}
+	privateEntry := types.JournalEntry{Date: "2019-11-15", LastModified: "2019-11-15", Markdown: "Prepared for layoffs", Visibility: types.VisibilityPrivate}
+	defaultEntry := types.JournalEntry{Date: "2019-11-08", LastModified: "2019-11-08", Markdown: "Took a nap"}
+
+	var tests = []struct {
+		explanation       string
+		defaultVisibility types.Visibility
+		authToken         string
+		entriesExpected   []types.JournalEntry
+	}{
+		{
+			"anonymous readers see only public entries",
+			types.VisibilityDefault,
+			"",
+			[]types.JournalEntry{publicEntry, defaultEntry},
+		},
+		{
+			"signed-in readers see public and signed-in entries",
+			types.VisibilityDefault,
+			"mock_token_A",
+			[]types.JournalEntry{publicEntry, signedInEntry, defaultEntry},
+		},
+		{
+			"authors see all of their own entries",
+			types.VisibilityDefault,
+			"mock_token_B",
+			[]types.JournalEntry{publicEntry, signedInEntry, privateEntry, defaultEntry},
+		},
+		{
+			"entries without visibility inherit the author's default",
+			types.VisibilityPrivate,
+			"mock_token_A",
+			[]types.JournalEntry{publicEntry, signedInEntry},
+		},
+	}
+	for _, tt := range tests {
+		ds := mockDatastore{
+			journalEntries: []types.JournalEntry{publicEntry, signedInEntry, privateEntry, defaultEntry},
+			userProfile: types.UserProfile{
+				DefaultVisibility: tt.defaultVisibility,
+			},
+		}
+		router := mux.NewRouter()
+		s := defaultServer{
+			authenticator: mockAuthenticator{
+				tokensToUsers: map[string]string{
+					"mock_token_A": "alice",
+					"mock_token_B": "bob",
+				},
+			},
+			datastore:      &ds,
+			router:         router,
+			csrfMiddleware: dummyCsrfMiddleware(),
+		}
+		s.routes()
+
+		req, err := http.NewRequest("GET", "/api/entries/bob", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.authToken != "" {
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", userKitAuthCookieName, tt.authToken))
+		}
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if status := w.Code; status != http.StatusOK {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				tt.explanation, status, http.StatusOK)
+		}
+		var response []types.JournalEntry
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+		if !reflect.DeepEqual(response, tt.entriesExpected) {
+			t.Fatalf("%s: Unexpected response: got %v want %v", tt.explanation, response, tt.entriesExpected)
+		}
+	}
+}
+
+func TestSitemapExcludesNonPublicEntries(t *testing.T) {
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-29", Markdown: "Shipped the billing page"},
+			types.JournalEntry{Date: "2019-11-22", Markdown: "Negotiated with a vendor", Visibility: types.VisibilitySignedIn},
+			types.JournalEntry{Date: "2019-11-15", Markdown: "Prepared for layoffs", Visibility: types.VisibilityPrivate},
+		},
+		users: []string{"bob"},
+	}
+
+	sitemap := string(buildSitemap(&ds).XMLContent())
+
+	if !strings.Contains(sitemap, "https://whatgotdone.com/bob/2019-11-29") {
+		t.Errorf("Sitemap is missing public entry: %s", sitemap)
+	}
+	for _, hidden := range []string{"/bob/2019-11-22", "/bob/2019-11-15"} {
+		if strings.Contains(sitemap, hidden) {
+			t.Errorf("Sitemap contains non-public entry %s", hidden)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+	"github.com/mtlynch/whatgotdone/backend/webhooks"
+)
+
+// maxWebhooksPerUser is the maximum number of webhooks a user can register.
+const maxWebhooksPerUser = 10
+
+// maxWebhookDeliveries is the maximum number of delivery log records to
+// return in a single page.
+const maxWebhookDeliveries = 100
+
+// entryEventData is the payload data for entry webhook events.
+type entryEventData struct {
+	Author     string           `json:"author"`
+	Date       string           `json:"date"`
+	URL        string           `json:"url"`
+	Markdown   string           `json:"markdown"`
+	Visibility types.Visibility `json:"visibility,omitempty"`
+}
+
+// reactionEventData is the payload data for reaction webhook events.
+type reactionEventData struct {
+	EntryAuthor string `json:"entryAuthor"`
+	EntryDate   string `json:"entryDate"`
+	Username    string `json:"username"`
+	Symbol      string `json:"symbol"`
+	Timestamp   string `json:"timestamp"`
+}
+
+func (s defaultServer) webhooksOptions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func (s defaultServer) webhooksGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view your webhooks", http.StatusForbidden)
+			return
+		}
+
+		hooks, err := s.datastore.GetWebhooks(username)
+		if err != nil {
+			log.Printf("Failed to retrieve webhooks for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve webhooks", http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(hooks, func(i, j int) bool {
+			return hooks[i].CreatedAt < hooks[j].CreatedAt
+		})
+
+		if err := json.NewEncoder(w).Encode(hooks); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) webhooksPost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to register webhooks", http.StatusForbidden)
+			return
+		}
+
+		type webhookRequest struct {
+			URL    string               `json:"url"`
+			Events []types.WebhookEvent `json:"events"`
+		}
+		var wr webhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&wr); err != nil {
+			log.Printf("Invalid webhook request: %v", err)
+			http.Error(w, "Invalid webhook request", http.StatusBadRequest)
+			return
+		}
+		if !validate.WebhookURL(wr.URL) {
+			http.Error(w, "Invalid webhook URL: must be an http or https URL", http.StatusBadRequest)
+			return
+		}
+		if len(wr.Events) == 0 {
+			http.Error(w, "Webhook must subscribe to at least one event", http.StatusBadRequest)
+			return
+		}
+		events := []types.WebhookEvent{}
+		for _, e := range wr.Events {
+			if !isValidWebhookEvent(e) {
+				http.Error(w, fmt.Sprintf("Invalid webhook event: %s", e), http.StatusBadRequest)
+				return
+			}
+			if !(types.Webhook{Events: events}).Subscribes(e) {
+				events = append(events, e)
+			}
+		}
+
+		existing, err := s.datastore.GetWebhooks(username)
+		if err != nil {
+			log.Printf("Failed to retrieve webhooks for %s: %s", username, err)
+			http.Error(w, "Failed to register webhook", http.StatusInternalServerError)
+			return
+		}
+		if len(existing) >= maxWebhooksPerUser {
+			http.Error(w, fmt.Sprintf("You can't register more than %d webhooks", maxWebhooksPerUser), http.StatusBadRequest)
+			return
+		}
+
+		hook, err := webhooks.NewWebhook(username, wr.URL, events)
+		if err != nil {
+			log.Printf("Failed to create webhook: %s", err)
+			http.Error(w, "Failed to register webhook", http.StatusInternalServerError)
+			return
+		}
+		if err := s.datastore.SetWebhook(hook); err != nil {
+			log.Printf("Failed to save webhook for %s: %s", username, err)
+			http.Error(w, "Failed to register webhook", http.StatusInternalServerError)
+			return
+		}
+
+		// This is the only response that includes the secret, so the user must
+		// save it now to verify signatures.
+		type webhookResponse struct {
+			types.Webhook
+			Secret string `json:"secret"`
+		}
+		if err := json.NewEncoder(w).Encode(webhookResponse{Webhook: hook, Secret: hook.Secret}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) webhookDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to delete webhooks", http.StatusForbidden)
+			return
+		}
+
+		webhookID, err := webhookIDFromRequestPath(r)
+		if err != nil {
+			log.Printf("Failed to retrieve webhook ID from request path: %s", err)
+			http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.datastore.DeleteWebhook(username, webhookID); err != nil {
+			log.Printf("Failed to delete webhook %s: %s", webhookID, err)
+			http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+			return
+		}
+
+		type webhookDeleteResponse struct {
+			Ok bool `json:"ok"`
+		}
+		if err := json.NewEncoder(w).Encode(webhookDeleteResponse{Ok: true}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s defaultServer) webhookDeliveriesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := s.loggedInUser(r)
+		if err != nil {
+			http.Error(w, "You must log in to view webhook deliveries", http.StatusForbidden)
+			return
+		}
+
+		webhookID, err := webhookIDFromRequestPath(r)
+		if err != nil {
+			log.Printf("Failed to retrieve webhook ID from request path: %s", err)
+			http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+			return
+		}
+
+		start := 0
+		if v := r.URL.Query().Get("start"); v != "" {
+			start, err = parseStart(v)
+			if err != nil {
+				http.Error(w, "Invalid start parameter", http.StatusBadRequest)
+				return
+			}
+		}
+		limit := maxWebhookDeliveries
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err = parseLimit(v)
+			if err != nil {
+				http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+				return
+			}
+			if limit > maxWebhookDeliveries {
+				limit = maxWebhookDeliveries
+			}
+		}
+
+		hooks, err := s.datastore.GetWebhooks(username)
+		if err != nil {
+			log.Printf("Failed to retrieve webhooks for %s: %s", username, err)
+			http.Error(w, "Failed to retrieve webhook deliveries", http.StatusInternalServerError)
+			return
+		}
+		owned := false
+		for _, hook := range hooks {
+			if hook.ID == webhookID {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			http.Error(w, "Webhook not found", http.StatusNotFound)
+			return
+		}
+
+		deliveries, err := s.datastore.GetWebhookDeliveries(webhookID, start, limit)
+		if err != nil {
+			log.Printf("Failed to retrieve deliveries for webhook %s: %s", webhookID, err)
+			http.Error(w, "Failed to retrieve webhook deliveries", http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// dispatchWebhook sends an event to the user's webhooks, if webhooks are
+// enabled on this server.
+func (s defaultServer) dispatchWebhook(owner string, event types.WebhookEvent, data interface{}) {
+	if s.webhookDispatcher == nil {
+		return
+	}
+	s.webhookDispatcher.Dispatch(owner, event, data)
+}
+
+func isValidWebhookEvent(e types.WebhookEvent) bool {
+	switch e {
+	case types.WebhookEntryPublished, types.WebhookEntryUpdated, types.WebhookReactionAdded:
+		return true
+	}
+	return false
+}
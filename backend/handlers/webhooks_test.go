@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mtlynch/whatgotdone/backend/types"
+	"github.com/mtlynch/whatgotdone/backend/webhooks"
+)
+
+func (ds mockDatastore) GetWebhooks(owner string) ([]types.Webhook, error) {
+	hooks := []types.Webhook{}
+	for _, hook := range ds.webhooks {
+		if hook.Owner == owner {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (ds *mockDatastore) SetWebhook(hook types.Webhook) error {
+	ds.webhooks = append(ds.webhooks, hook)
+	return nil
+}
+
+func (ds *mockDatastore) DeleteWebhook(owner string, webhookID string) error {
+	hooks := []types.Webhook{}
+	for _, hook := range ds.webhooks {
+		if hook.Owner != owner || hook.ID != webhookID {
+			hooks = append(hooks, hook)
+		}
+	}
+	ds.webhooks = hooks
+	return nil
+}
+
+func (ds *mockDatastore) AddWebhookDelivery(d types.WebhookDelivery) error {
+	// Deliveries happen on background goroutines, so the mock discards them
+	// rather than racing with the test's reads.
+	return nil
+}
+
+func (ds mockDatastore) GetWebhookDeliveries(webhookID string, start, limit int) ([]types.WebhookDelivery, error) {
+	deliveries := []types.WebhookDelivery{}
+	for _, d := range ds.deliveries {
+		if d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].Timestamp > deliveries[j].Timestamp
+	})
+	if start > len(deliveries) {
+		start = len(deliveries)
+	}
+	deliveries = deliveries[start:]
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (ds *mockDatastore) PruneWebhookDeliveries(webhookID string, keep int) error {
+	return nil
+}
+
+func TestWebhookRegistration(t *testing.T) {
+	ds := mockDatastore{}
+	s := newTeamsTestServer(&ds)
+
+	steps := []struct {
+		explanation        string
+		authToken          string
+		body               string
+		httpStatusExpected int
+	}{
+		{"anonymous users can't register webhooks", "", `{"url": "https://example.com/hook", "events": ["entry.published"]}`, http.StatusForbidden},
+		{"rejects non-HTTP URL", "mock_token_A", `{"url": "file:///etc/passwd", "events": ["entry.published"]}`, http.StatusBadRequest},
+		{"rejects unknown event", "mock_token_A", `{"url": "https://example.com/hook", "events": ["entry.deleted"]}`, http.StatusBadRequest},
+		{"rejects webhook without events", "mock_token_A", `{"url": "https://example.com/hook", "events": []}`, http.StatusBadRequest},
+		{"registers valid webhook", "mock_token_A", `{"url": "https://example.com/hook", "events": ["entry.published", "reaction.added"]}`, http.StatusOK},
+	}
+	for _, step := range steps {
+		w := sendTeamsRequest(s, "POST", "/api/user/me/webhooks", step.authToken, step.body)
+		if status := w.Code; status != step.httpStatusExpected {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v",
+				step.explanation, status, step.httpStatusExpected)
+		}
+	}
+
+	if len(ds.webhooks) != 1 {
+		t.Fatalf("expected one registered webhook, got %d", len(ds.webhooks))
+	}
+	hook := ds.webhooks[0]
+	if hook.Owner != "alice" || hook.Secret == "" {
+		t.Fatalf("unexpected webhook: %+v", hook)
+	}
+
+	// Listing webhooks doesn't reveal their secrets.
+	w := sendTeamsRequest(s, "GET", "/api/user/me/webhooks", "mock_token_A", "")
+	var listed []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if len(listed) != 1 || listed[0]["id"] != hook.ID {
+		t.Fatalf("unexpected webhooks: %v", listed)
+	}
+	if _, ok := listed[0]["secret"]; ok {
+		t.Fatalf("webhook list should not include secrets")
+	}
+
+	// Bob can't view or delete alice's webhook.
+	if w := sendTeamsRequest(s, "GET", "/api/user/me/webhooks/"+hook.ID+"/deliveries", "mock_token_B", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("bob should not see alice's deliveries: got %v", w.Code)
+	}
+	sendTeamsRequest(s, "DELETE", "/api/user/me/webhooks/"+hook.ID, "mock_token_B", "")
+	if len(ds.webhooks) != 1 {
+		t.Fatalf("bob deleted alice's webhook")
+	}
+
+	if w := sendTeamsRequest(s, "DELETE", "/api/user/me/webhooks/"+hook.ID, "mock_token_A", ""); w.Code != http.StatusOK {
+		t.Fatalf("failed to delete webhook: got %v", w.Code)
+	}
+	if len(ds.webhooks) != 0 {
+		t.Fatalf("webhook still exists after deletion")
+	}
+}
+
+func TestWebhookDeliveriesGet(t *testing.T) {
+	ds := mockDatastore{
+		webhooks: []types.Webhook{
+			types.Webhook{ID: "0123456789abcdef", Owner: "alice"},
+		},
+		deliveries: []types.WebhookDelivery{
+			types.WebhookDelivery{ID: "d1", WebhookID: "0123456789abcdef", Timestamp: "2019-11-29T10:00:00Z", Attempts: 1, Success: true},
+			types.WebhookDelivery{ID: "d2", WebhookID: "0123456789abcdef", Timestamp: "2019-11-29T12:00:00Z", Attempts: 5, Error: "receiver responded with status 500"},
+			types.WebhookDelivery{ID: "d3", WebhookID: "fedcba9876543210", Timestamp: "2019-11-29T11:00:00Z", Attempts: 1, Success: true},
+		},
+	}
+	s := newTeamsTestServer(&ds)
+
+	w := sendTeamsRequest(s, "GET", "/api/user/me/webhooks/0123456789abcdef/deliveries", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to retrieve deliveries: got %v", w.Code)
+	}
+	var deliveries []types.WebhookDelivery
+	if err := json.Unmarshal(w.Body.Bytes(), &deliveries); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if len(deliveries) != 2 || deliveries[0].ID != "d2" || deliveries[1].ID != "d1" {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+
+	w = sendTeamsRequest(s, "GET", "/api/user/me/webhooks/0123456789abcdef/deliveries?start=1&limit=1", "mock_token_A", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to retrieve page of deliveries: got %v", w.Code)
+	}
+	deliveries = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &deliveries); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+	}
+	if len(deliveries) != 1 || deliveries[0].ID != "d1" {
+		t.Fatalf("unexpected page of deliveries: %+v", deliveries)
+	}
+
+	w = sendTeamsRequest(s, "GET", "/api/user/me/webhooks/0123456789abcdef/deliveries?limit=0", "mock_token_A", "")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid limit to be rejected: got %v", w.Code)
+	}
+}
+
+func TestEntryPostDispatchesWebhooks(t *testing.T) {
+	received := make(chan webhooks.Payload, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(webhooks.SignatureHeader) != webhooks.Sign("dummy-secret", body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		var p webhooks.Payload
+		json.Unmarshal(body, &p)
+		received <- p
+	}))
+	defer receiver.Close()
+
+	ds := mockDatastore{
+		journalEntries: []types.JournalEntry{
+			types.JournalEntry{Date: "2019-11-22", LastModified: "2019-11-22T20:00:00Z", Markdown: "Wrote docs"},
+		},
+		webhooks: []types.Webhook{
+			types.Webhook{
+				ID:     "0123456789abcdef",
+				Owner:  "alice",
+				URL:    receiver.URL,
+				Secret: "dummy-secret",
+				Events: []types.WebhookEvent{types.WebhookEntryPublished, types.WebhookEntryUpdated, types.WebhookReactionAdded},
+			},
+		},
+	}
+	dispatcher := webhooks.New(&ds)
+	// The receiver listens on loopback, which the default client refuses.
+	dispatcher.Client = receiver.Client()
+	dispatcher.InitialBackoff = time.Millisecond
+	s := defaultServer{
+		authenticator: mockAuthenticator{
+			tokensToUsers: map[string]string{
+				"mock_token_A": "alice",
+				"mock_token_B": "bob",
+			},
+		},
+		datastore:         &ds,
+		router:            mux.NewRouter(),
+		csrfMiddleware:    dummyCsrfMiddleware(),
+		webhookDispatcher: dispatcher,
+	}
+	s.routes()
+
+	var tests = []struct {
+		explanation   string
+		method        string
+		path          string
+		authToken     string
+		body          string
+		eventExpected types.WebhookEvent
+	}{
+		{"publishing a new date sends entry.published", "POST", "/api/entry/2019-11-29", "mock_token_A", `{"entryContent": "Shipped billing"}`, types.WebhookEntryPublished},
+		{"editing an existing date sends entry.updated", "POST", "/api/entry/2019-11-22", "mock_token_A", `{"entryContent": "Wrote more docs"}`, types.WebhookEntryUpdated},
+		{"reacting sends reaction.added", "POST", "/api/reactions/entry/alice/2019-11-22", "mock_token_B", `{"reactionSymbol": "🎉"}`, types.WebhookReactionAdded},
+	}
+	for _, tt := range tests {
+		w := sendTeamsRequest(s, tt.method, tt.path, tt.authToken, tt.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: request failed with status %v", tt.explanation, w.Code)
+		}
+		select {
+		case p := <-received:
+			if p.Event != tt.eventExpected {
+				t.Fatalf("%s: unexpected event: got %v want %v", tt.explanation, p.Event, tt.eventExpected)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: timed out waiting for webhook", tt.explanation)
+		}
+	}
+}
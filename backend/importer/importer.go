@@ -0,0 +1,212 @@
+// Package importer publishes a user's past updates from markdown files in bulk.
+//
+// Each markdown file holds one entry. The entry's date comes from a "date"
+// field in the file's YAML front matter or, failing that, from the file's name
+// (e.g., 2019-11-29.md). Front matter may also set the entry's "visibility",
+// "teamId", and "lastModified" fields, so the markdown files from a user data
+// export import as they were.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/dates"
+	"github.com/mtlynch/whatgotdone/backend/handlers/entry"
+	"github.com/mtlynch/whatgotdone/backend/handlers/validate"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// ErrInvalidFiles occurs when publishing a plan with invalid files. An import
+// publishes either every valid entry or none of them, so that a user can fix
+// their files and retry without duplicating work.
+var ErrInvalidFiles = errors.New("import has invalid files")
+
+// Plan describes what an import would change.
+type Plan struct {
+	Entries []PlannedEntry `json:"entries"`
+	// Invalid lists files that look like entries but can't be imported.
+	Invalid []InvalidFile `json:"invalid"`
+	// Skipped lists files that aren't entries, such as drafts or images.
+	Skipped []string `json:"skipped"`
+}
+
+// PlannedEntry is an entry that an import would publish.
+type PlannedEntry struct {
+	File string `json:"file"`
+	Date string `json:"date"`
+	// Conflict is true if the user has already published an entry for the same
+	// date.
+	Conflict             bool               `json:"conflict"`
+	ExistingLastModified string             `json:"existingLastModified,omitempty"`
+	Entry                types.JournalEntry `json:"-"`
+}
+
+// InvalidFile is a file that an import can't publish, along with the reason.
+type InvalidFile struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// Prepare reads entries from the files and compares them with the user's
+// published entries. It doesn't change the datastore.
+func Prepare(ds datastore.Datastore, username string, files []File, now time.Time) (Plan, error) {
+	existing, err := ds.GetEntries(username)
+	if err != nil {
+		return Plan{}, err
+	}
+	existingByDate := map[string]types.JournalEntry{}
+	for _, j := range existing {
+		existingByDate[j.Date] = j
+	}
+	users, err := ds.Users()
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{
+		Entries: []PlannedEntry{},
+		Invalid: []InvalidFile{},
+		Skipped: []string{},
+	}
+	teams := map[string]types.Team{}
+	fileForDate := map[string]string{}
+	for _, f := range files {
+		if !isMarkdownFile(f.Name) {
+			plan.Skipped = append(plan.Skipped, f.Name)
+			continue
+		}
+		frontMatter, body, err := parseMarkdown(string(f.Contents))
+		if err != nil {
+			plan.Invalid = append(plan.Invalid, InvalidFile{File: f.Name, Reason: err.Error()})
+			continue
+		}
+		if frontMatter["draft"] == "true" {
+			plan.Skipped = append(plan.Skipped, f.Name)
+			continue
+		}
+
+		j, reason := entryFromFile(f.Name, frontMatter, body, now)
+		if reason == "" {
+			reason, err = checkTeamVisibility(ds, teams, username, j)
+			if err != nil {
+				return Plan{}, err
+			}
+		}
+		if reason == "" {
+			if other, ok := fileForDate[j.Date]; ok {
+				reason = fmt.Sprintf("%s already has an entry for %s", other, j.Date)
+			}
+		}
+		if reason != "" {
+			plan.Invalid = append(plan.Invalid, InvalidFile{File: f.Name, Reason: reason})
+			continue
+		}
+		fileForDate[j.Date] = f.Name
+		j.Mentions = entry.MentionedUsers(username, j.Markdown, users)
+
+		planned := PlannedEntry{
+			File:  f.Name,
+			Date:  j.Date,
+			Entry: j,
+		}
+		if previous, ok := existingByDate[j.Date]; ok {
+			planned.Conflict = true
+			planned.ExistingLastModified = previous.LastModified
+		}
+		plan.Entries = append(plan.Entries, planned)
+	}
+	return plan, nil
+}
+
+// entryFromFile builds an entry from a markdown file's contents. If the file
+// doesn't describe a valid entry, it returns the reason.
+func entryFromFile(name string, frontMatter map[string]string, body string, now time.Time) (types.JournalEntry, string) {
+	date, ok := frontMatter["date"]
+	if !ok {
+		date = dateFromFilename(name)
+	}
+	if !dates.IsEntryDate(date) {
+		return types.JournalEntry{}, fmt.Sprintf("%s is not a valid entry date: entries must be dated on a Friday in YYYY-MM-DD format, no earlier than 2019", date)
+	}
+	if body == "" {
+		return types.JournalEntry{}, "entry is empty"
+	}
+
+	j := types.JournalEntry{
+		Date:         date,
+		LastModified: now.Format(time.RFC3339),
+		Markdown:     body,
+		Visibility:   types.Visibility(frontMatter["visibility"]),
+		TeamID:       frontMatter["teamId"],
+	}
+	if lastModified, ok := frontMatter["lastModified"]; ok {
+		if _, err := time.Parse(time.RFC3339, lastModified); err != nil {
+			return types.JournalEntry{}, fmt.Sprintf("invalid lastModified time: %s", lastModified)
+		}
+		j.LastModified = lastModified
+	}
+
+	switch j.Visibility {
+	case types.VisibilityDefault, types.VisibilityPublic, types.VisibilitySignedIn, types.VisibilityPrivate:
+		if j.TeamID != "" {
+			return types.JournalEntry{}, "only entries with team visibility can specify a team"
+		}
+	case types.VisibilityTeam:
+		if !validate.TeamID(j.TeamID) {
+			return types.JournalEntry{}, fmt.Sprintf("invalid team ID: %s", j.TeamID)
+		}
+	default:
+		return types.JournalEntry{}, fmt.Sprintf("invalid visibility: %s", j.Visibility)
+	}
+	return j, ""
+}
+
+// checkTeamVisibility verifies that a user belongs to the team that can see
+// their entry, caching teams that it retrieves.
+func checkTeamVisibility(ds datastore.Datastore, teams map[string]types.Team, username string, j types.JournalEntry) (string, error) {
+	if j.Visibility != types.VisibilityTeam {
+		return "", nil
+	}
+	team, ok := teams[j.TeamID]
+	if !ok {
+		var err error
+		team, err = ds.GetTeam(j.TeamID)
+		if _, ok := err.(datastore.TeamNotFoundError); ok {
+			return fmt.Sprintf("team %s does not exist", j.TeamID), nil
+		} else if err != nil {
+			return "", err
+		}
+		teams[j.TeamID] = team
+	}
+	if _, ok := team.Member(username); !ok {
+		return fmt.Sprintf("you must be a member of team %s to publish to it", j.TeamID), nil
+	}
+	return "", nil
+}
+
+// Publish saves the plan's entries as the user's published entries. It
+// replaces existing entries with the same dates only if overwrite is true, and
+// it publishes nothing if any of the plan's files are invalid. It returns the
+// number of entries that it published.
+//
+// Publishing imported entries doesn't notify mentioned users, followers,
+// Slack, or webhooks, since the entries describe past weeks.
+func Publish(ds datastore.Datastore, username string, plan Plan, overwrite bool) (int, error) {
+	if len(plan.Invalid) > 0 {
+		return 0, ErrInvalidFiles
+	}
+	published := 0
+	for _, p := range plan.Entries {
+		if p.Conflict && !overwrite {
+			continue
+		}
+		if err := ds.InsertEntry(username, p.Entry); err != nil {
+			return published, fmt.Errorf("failed to publish %s: %s", p.File, err)
+		}
+		published++
+	}
+	return published, nil
+}
@@ -0,0 +1,317 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that imports use.
+	datastore.Datastore
+	users    []string
+	entries  []types.JournalEntry
+	teams    map[string]types.Team
+	inserted []types.JournalEntry
+}
+
+func (ds *mockDatastore) Users() ([]string, error) {
+	return ds.users, nil
+}
+
+func (ds *mockDatastore) GetEntries(username string) ([]types.JournalEntry, error) {
+	return ds.entries, nil
+}
+
+func (ds *mockDatastore) GetTeam(teamID string) (types.Team, error) {
+	team, ok := ds.teams[teamID]
+	if !ok {
+		return types.Team{}, datastore.TeamNotFoundError{TeamID: teamID}
+	}
+	return team, nil
+}
+
+func (ds *mockDatastore) InsertEntry(username string, j types.JournalEntry) error {
+	ds.inserted = append(ds.inserted, j)
+	return nil
+}
+
+func TestParseMarkdown(t *testing.T) {
+	var tests = []struct {
+		explanation         string
+		contents            string
+		frontMatterExpected map[string]string
+		bodyExpected        string
+	}{
+		{
+			"no front matter",
+			"\n# Shipped it\n\n* Wrote tests\n\n",
+			map[string]string{},
+			"# Shipped it\n\n* Wrote tests",
+		},
+		{
+			"front matter with plain and quoted values",
+			"---\ndate: 2019-11-29\nvisibility: \"private\"\ntitle: 'It''s done'\nreactions:\n  - username: \"bob\"\n---\n\nShipped it\n",
+			map[string]string{"date": "2019-11-29", "visibility": "private", "title": "It's done", "reactions": ""},
+			"Shipped it",
+		},
+		{
+			"Windows line endings",
+			"---\r\ndate: 2019-11-29\r\n---\r\nShipped it\r\n",
+			map[string]string{"date": "2019-11-29"},
+			"Shipped it",
+		},
+	}
+	for _, tt := range tests {
+		frontMatter, body, err := parseMarkdown(tt.contents)
+		if err != nil {
+			t.Errorf("%s: parseMarkdown failed: %v", tt.explanation, err)
+			continue
+		}
+		if !reflect.DeepEqual(frontMatter, tt.frontMatterExpected) {
+			t.Errorf("%s: front matter=%v, want %v", tt.explanation, frontMatter, tt.frontMatterExpected)
+		}
+		if body != tt.bodyExpected {
+			t.Errorf("%s: body=%q, want %q", tt.explanation, body, tt.bodyExpected)
+		}
+	}
+
+	if _, _, err := parseMarkdown("---\ndate: 2019-11-29\nShipped it\n"); err == nil {
+		t.Errorf("expected unterminated front matter to fail")
+	}
+}
+
+func TestPrepareAndPublish(t *testing.T) {
+	ds := &mockDatastore{
+		users: []string{"alice", "bob"},
+		entries: []types.JournalEntry{
+			{Date: "2019-11-22", LastModified: "2019-11-22T18:00:00Z", Markdown: "Already here"},
+		},
+		teams: map[string]types.Team{
+			"lighthouse": {ID: "lighthouse", Members: []types.TeamMember{{Username: "alice"}}},
+			"skunkworks": {ID: "skunkworks", Members: []types.TeamMember{{Username: "bob"}}},
+		},
+	}
+	now := time.Date(2019, 12, 2, 9, 0, 0, 0, time.UTC)
+	files := []File{
+		{Name: "notes/2019-11-15.md", Contents: []byte("Planned the offsite with @bob and @dave")},
+		{Name: "notes/2019-11-22.md", Contents: []byte("Replacement for an existing entry")},
+		{Name: "notes/week-48.md", Contents: []byte("---\ndate: 2019-11-29\nvisibility: team\nteamId: lighthouse\nlastModified: 2019-11-29T20:00:00Z\n---\nShipped it")},
+		{Name: "notes/photo.png", Contents: []byte{0x89, 0x50}},
+		{Name: "drafts/2019-12-06.md", Contents: []byte("---\ndraft: true\n---\nStill writing")},
+	}
+
+	plan, err := Prepare(ds, "alice", files, now)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	entriesExpected := []PlannedEntry{
+		{
+			File:  "notes/2019-11-15.md",
+			Date:  "2019-11-15",
+			Entry: types.JournalEntry{Date: "2019-11-15", LastModified: "2019-12-02T09:00:00Z", Markdown: "Planned the offsite with @bob and @dave", Mentions: []string{"bob"}},
+		},
+		{
+			File:                 "notes/2019-11-22.md",
+			Date:                 "2019-11-22",
+			Conflict:             true,
+			ExistingLastModified: "2019-11-22T18:00:00Z",
+			Entry:                types.JournalEntry{Date: "2019-11-22", LastModified: "2019-12-02T09:00:00Z", Markdown: "Replacement for an existing entry", Mentions: []string{}},
+		},
+		{
+			File:  "notes/week-48.md",
+			Date:  "2019-11-29",
+			Entry: types.JournalEntry{Date: "2019-11-29", LastModified: "2019-11-29T20:00:00Z", Markdown: "Shipped it", Visibility: types.VisibilityTeam, TeamID: "lighthouse", Mentions: []string{}},
+		},
+	}
+	if !reflect.DeepEqual(plan.Entries, entriesExpected) {
+		t.Errorf("entries=%+v, want %+v", plan.Entries, entriesExpected)
+	}
+	if len(plan.Invalid) != 0 {
+		t.Errorf("unexpected invalid files: %+v", plan.Invalid)
+	}
+	if !reflect.DeepEqual(plan.Skipped, []string{"notes/photo.png", "drafts/2019-12-06.md"}) {
+		t.Errorf("unexpected skipped files: %v", plan.Skipped)
+	}
+	if len(ds.inserted) != 0 {
+		t.Fatalf("Prepare must not publish entries")
+	}
+
+	published, err := Publish(ds, "alice", plan, false)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if published != 2 || len(ds.inserted) != 2 || ds.inserted[0].Date != "2019-11-15" || ds.inserted[1].Date != "2019-11-29" {
+		t.Errorf("expected conflicting entry to be skipped, published %d: %+v", published, ds.inserted)
+	}
+
+	ds.inserted = nil
+	published, err = Publish(ds, "alice", plan, true)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if published != 3 || len(ds.inserted) != 3 {
+		t.Errorf("expected overwrite to publish every entry, published %d", published)
+	}
+}
+
+func TestPrepareReportsInvalidFiles(t *testing.T) {
+	ds := &mockDatastore{
+		teams: map[string]types.Team{
+			"skunkworks": {ID: "skunkworks", Members: []types.TeamMember{{Username: "bob"}}},
+		},
+	}
+	files := []File{
+		{Name: "2019-11-28.md", Contents: []byte("Thursday isn't an entry date")},
+		{Name: "2018-11-30.md", Contents: []byte("Before What Got Done existed")},
+		{Name: "monday-notes.md", Contents: []byte("No date anywhere")},
+		{Name: "2019-11-29.md", Contents: []byte("\n\n")},
+		{Name: "a/2019-11-22.md", Contents: []byte("First copy")},
+		{Name: "b/2019-11-22.md", Contents: []byte("Second copy")},
+		{Name: "secret.md", Contents: []byte("---\ndate: 2019-11-15\nvisibility: team\nteamId: skunkworks\n---\nNot my team")},
+		{Name: "hidden.md", Contents: []byte("---\ndate: 2019-11-08\nvisibility: hidden\n---\nUnknown visibility")},
+	}
+	plan, err := Prepare(ds, "alice", files, time.Now())
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	invalidFiles := []string{}
+	for _, f := range plan.Invalid {
+		invalidFiles = append(invalidFiles, f.File)
+	}
+	invalidExpected := []string{"2019-11-28.md", "2018-11-30.md", "monday-notes.md", "2019-11-29.md", "b/2019-11-22.md", "secret.md", "hidden.md"}
+	if !reflect.DeepEqual(invalidFiles, invalidExpected) {
+		t.Errorf("invalid files=%v, want %v", invalidFiles, invalidExpected)
+	}
+
+	if _, err := Publish(ds, "alice", plan, false); err != ErrInvalidFiles {
+		t.Errorf("Publish error=%v, want %v", err, ErrInvalidFiles)
+	}
+	if len(ds.inserted) != 0 {
+		t.Errorf("expected no entries to be published, got %+v", ds.inserted)
+	}
+}
+
+func TestReadZipAndDir(t *testing.T) {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, name := range []string{"2019-11-29.md", "notes/2019-11-22.md"} {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte("Entry for " + name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fromZip, err := ReadZip(b.Bytes())
+	if err != nil {
+		t.Fatalf("ReadZip failed: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "notes"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"2019-11-29.md", "notes/2019-11-22.md"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("Entry for "+name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fromDir, err := ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+
+	expected := []File{
+		{Name: "2019-11-29.md", Contents: []byte("Entry for 2019-11-29.md")},
+		{Name: "notes/2019-11-22.md", Contents: []byte("Entry for notes/2019-11-22.md")},
+	}
+	if !reflect.DeepEqual(fromZip, expected) {
+		t.Errorf("ReadZip=%+v, want %+v", fromZip, expected)
+	}
+	if !reflect.DeepEqual(fromDir, expected) {
+		t.Errorf("ReadDir=%+v, want %+v", fromDir, expected)
+	}
+
+	if _, err := ReadZip([]byte("not a zip")); err == nil {
+		t.Errorf("expected invalid zip to fail")
+	}
+}
+
+func TestReadZipEnforcesLimits(t *testing.T) {
+	var tests = []struct {
+		explanation string
+		files       map[string]int
+	}{
+		{
+			"rejects files larger than the per-file limit",
+			map[string]int{"2019-11-29.md": maxFileSize + 1},
+		},
+		{
+			"rejects archives with too many files",
+			func() map[string]int {
+				files := map[string]int{}
+				for i := 0; i <= maxFileCount; i++ {
+					files[fmt.Sprintf("%d.md", i)] = 1
+				}
+				return files
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		zw := zip.NewWriter(&b)
+		for name, size := range tt.files {
+			f, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Write(bytes.Repeat([]byte("a"), size))
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadZip(b.Bytes()); err == nil {
+			t.Errorf("%s: expected ReadZip to fail", tt.explanation)
+		}
+	}
+}
+
+func TestReadZipIgnoresMisreportedSizes(t *testing.T) {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	f, err := zw.Create("2019-11-29.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(bytes.Repeat([]byte("a"), maxFileSize+1))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Rewrite the central directory's uncompressed size so that the archive
+	// claims the file is tiny.
+	archive := b.Bytes()
+	header := bytes.LastIndex(archive, []byte("PK\x01\x02"))
+	binary.LittleEndian.PutUint32(archive[header+24:], 1)
+
+	if _, err := ReadZip(archive); err == nil {
+		t.Errorf("expected ReadZip to reject a file larger than its header claims")
+	}
+}
@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxFileSize is the size of the largest file that an import accepts.
+	maxFileSize = 1024 * 1024
+	// maxTotalSize is the largest total size of the files in an import.
+	maxTotalSize = 100 * 1024 * 1024
+	// maxFileCount is the largest number of files that an import accepts.
+	maxFileCount = 5000
+)
+
+// File is a file to import, named by its path within the zip archive or
+// directory that contained it.
+type File struct {
+	Name     string
+	Contents []byte
+}
+
+// ReadZip returns the files in a zip archive. It reads no more than the import
+// limits allow, even if the archive misreports the sizes of its files.
+func ReadZip(b []byte) ([]File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	if len(zr.File) > maxFileCount {
+		return nil, fmt.Errorf("archive has more than the %d file limit", maxFileCount)
+	}
+	files := []File{}
+	totalSize := 0
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.UncompressedSize64 > maxFileSize {
+			return nil, fmt.Errorf("%s is larger than the %d byte limit", f.Name, maxFileSize)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		// Read one byte past the limit to detect files that are larger than
+		// their headers claim.
+		contents, err := ioutil.ReadAll(io.LimitReader(rc, maxFileSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(contents) > maxFileSize {
+			return nil, fmt.Errorf("%s is larger than the %d byte limit", f.Name, maxFileSize)
+		}
+		totalSize += len(contents)
+		if totalSize > maxTotalSize {
+			return nil, fmt.Errorf("archive is larger than the %d byte limit when uncompressed", maxTotalSize)
+		}
+		files = append(files, File{Name: f.Name, Contents: contents})
+	}
+	sortFiles(files)
+	return files, nil
+}
+
+// ReadDir returns the files in a directory and its subdirectories.
+func ReadDir(dir string) ([]File, error) {
+	files := []File{}
+	totalSize := int64(0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if len(files) >= maxFileCount {
+			return fmt.Errorf("%s has more than the %d file limit", dir, maxFileCount)
+		}
+		if info.Size() > maxFileSize {
+			return fmt.Errorf("%s is larger than the %d byte limit", path, maxFileSize)
+		}
+		totalSize += info.Size()
+		if totalSize > maxTotalSize {
+			return fmt.Errorf("%s is larger than the %d byte limit", dir, maxTotalSize)
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Name: filepath.ToSlash(name), Contents: contents})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortFiles(files)
+	return files, nil
+}
+
+func sortFiles(files []File) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+}
+
+func isMarkdownFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// dateFromFilename returns the file's name without its directory or extension,
+// which is the entry's date if the file is named by date.
+func dateFromFilename(name string) string {
+	base := filepath.Base(filepath.FromSlash(name))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// parseMarkdown splits a markdown file into its YAML front matter and body. It
+// understands only the subset of YAML that entry front matter needs: top-level
+// "key: value" pairs with plain or quoted string values. It ignores nested
+// values, such as lists.
+func parseMarkdown(contents string) (map[string]string, string, error) {
+	contents = strings.Replace(contents, "\r\n", "\n", -1)
+	frontMatter := map[string]string{}
+	if !strings.HasPrefix(contents, "---\n") {
+		return frontMatter, trimBody(contents), nil
+	}
+
+	lines := strings.Split(strings.TrimPrefix(contents, "---\n"), "\n")
+	for i, line := range lines {
+		if line == "---" {
+			return frontMatter, trimBody(strings.Join(lines[i+1:], "\n")), nil
+		}
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, "", fmt.Errorf("invalid front matter line: %s", line)
+		}
+		value, err := parseYAMLString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid value for %s: %s", parts[0], err)
+		}
+		frontMatter[strings.TrimSpace(parts[0])] = value
+	}
+	return nil, "", fmt.Errorf("front matter has no closing ---")
+}
+
+func parseYAMLString(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	if strings.HasPrefix(s, "'") {
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string: %s", s)
+		}
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	return s, nil
+}
+
+func trimBody(body string) string {
+	return strings.TrimRight(strings.TrimLeft(body, "\n"), "\n")
+}
@@ -0,0 +1,102 @@
+// Package mail sends email messages to What Got Done users.
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+type (
+	// Mailer sends plaintext email messages.
+	Mailer interface {
+		Send(to string, subject string, body string, headers ...Header) error
+	}
+
+	// Header is an extra header for an email message, such as
+	// List-Unsubscribe.
+	Header struct {
+		Name  string
+		Value string
+	}
+
+	// smtpMailer implements Mailer by sending messages through an SMTP relay.
+	smtpMailer struct {
+		addr string
+		auth smtp.Auth
+		from string
+	}
+)
+
+// New creates a Mailer that sends messages through the SMTP server specified
+// by the SMTP_HOST and SMTP_PORT environment variables. If SMTP_USERNAME is
+// set, the mailer authenticates with SMTP_USERNAME and SMTP_PASSWORD. Messages
+// come from the address in SMTP_FROM.
+func New() (Mailer, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, errors.New("Can't create Mailer without an SMTP host")
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "25"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "What Got Done <noreply@whatgotdone.com>"
+	}
+
+	var auth smtp.Auth
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	return NewSMTPMailer(fmt.Sprintf("%s:%s", host, port), auth, from), nil
+}
+
+// NewSMTPMailer creates a Mailer that sends messages through the SMTP server
+// at addr (in host:port format). auth may be nil for servers that don't
+// require authentication, such as a local development mail sink.
+func NewSMTPMailer(addr string, auth smtp.Auth, from string) Mailer {
+	return smtpMailer{
+		addr: addr,
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send delivers a plaintext email message to a single recipient.
+func (m smtpMailer) Send(to string, subject string, body string, headers ...Header) error {
+	return smtp.SendMail(m.addr, m.auth, envelopeAddress(m.from), []string{to}, formatMessage(m.from, to, subject, body, headers))
+}
+
+func formatMessage(from string, to string, subject string, body string, extraHeaders []Header) []byte {
+	headers := []string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	for _, h := range extraHeaders {
+		headers = append(headers, h.Name+": "+h.Value)
+	}
+	// SMTP requires CRLF line endings.
+	body = strings.Replace(body, "\r\n", "\n", -1)
+	body = strings.Replace(body, "\n", "\r\n", -1)
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body)
+}
+
+// envelopeAddress extracts the bare email address from an address that might
+// contain a display name (e.g., "Name <user@example.com>").
+func envelopeAddress(address string) string {
+	start := strings.LastIndex(address, "<")
+	end := strings.LastIndex(address, ">")
+	if start < 0 || end < start {
+		return address
+	}
+	return address[start+1 : end]
+}
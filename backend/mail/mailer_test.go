@@ -0,0 +1,140 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+type receivedMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPSink starts a minimal SMTP server on a local port that accepts a
+// single message and reports it on the returned channel.
+func startSMTPSink(t *testing.T) (string, <-chan receivedMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make(chan receivedMessage, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost SMTP sink")
+		var msg receivedMessage
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				msg.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				tp.PrintfLine("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				tp.PrintfLine("250 OK")
+			case command == "DATA":
+				tp.PrintfLine("354 Go ahead")
+				lines, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				msg.data = strings.Join(lines, "\n")
+				tp.PrintfLine("250 OK")
+				messages <- msg
+			case command == "QUIT":
+				tp.PrintfLine("221 Bye")
+				return
+			default:
+				tp.PrintfLine("502 Unsupported")
+			}
+		}
+	}()
+	return listener.Addr().String(), messages
+}
+
+func TestSMTPMailerSendsMessage(t *testing.T) {
+	addr, messages := startSMTPSink(t)
+	m := NewSMTPMailer(addr, nil, "What Got Done <noreply@example.com>")
+
+	err := m.Send("jimmy@example.com", "Reminder", "Hello!\nTime to write your update.", Header{Name: "List-Unsubscribe", Value: "<https://example.com/unsubscribe>"})
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+
+	msg := <-messages
+	if msg.from != "noreply@example.com" {
+		t.Errorf("Unexpected envelope sender: got %v want %v", msg.from, "noreply@example.com")
+	}
+	if len(msg.to) != 1 || msg.to[0] != "jimmy@example.com" {
+		t.Errorf("Unexpected envelope recipients: got %v want %v", msg.to, []string{"jimmy@example.com"})
+	}
+	headers, body := splitMessage(msg.data)
+	for _, h := range []string{
+		"From: What Got Done <noreply@example.com>",
+		"To: jimmy@example.com",
+		"Subject: Reminder",
+		"Content-Type: text/plain; charset=UTF-8",
+		"List-Unsubscribe: <https://example.com/unsubscribe>",
+	} {
+		if !strings.Contains(headers, h) {
+			t.Errorf("Message is missing header [%s]: %s", h, headers)
+		}
+	}
+	if body != "Hello!\nTime to write your update." {
+		t.Errorf("Unexpected message body: got [%s]", body)
+	}
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	var tests = []struct {
+		address         string
+		addressExpected string
+	}{
+		{"noreply@example.com", "noreply@example.com"},
+		{"What Got Done <noreply@example.com>", "noreply@example.com"},
+	}
+	for _, tt := range tests {
+		addressActual := envelopeAddress(tt.address)
+		if addressActual != tt.addressExpected {
+			t.Errorf("input [%s], got %v, want %v", tt.address, addressActual, tt.addressExpected)
+		}
+	}
+}
+
+func splitMessage(data string) (string, string) {
+	r := bufio.NewReader(strings.NewReader(data))
+	headers := []string{}
+	for {
+		line, err := r.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+		if line == "" || err != nil {
+			break
+		}
+		headers = append(headers, line)
+	}
+	body := []string{}
+	for {
+		line, err := r.ReadString('\n')
+		body = append(body, strings.TrimRight(line, "\n"))
+		if err != nil {
+			break
+		}
+	}
+	return strings.Join(headers, "\n"), strings.Join(body, "\n")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -14,8 +15,11 @@ import (
 func main() {
 	log.Print("Starting whatgotdone server")
 
+	// Open the access log before creating the server so that the log exists by
+	// the time the server first reads page views from it.
+	accessLog := accessLogWriter()
 	s := handlers.New()
-	http.Handle("/", muxHandlers.LoggingHandler(os.Stdout, s.Router()))
+	http.Handle("/", muxHandlers.CombinedLoggingHandler(accessLog, s.Router()))
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -25,3 +29,18 @@ func main() {
 
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", port), nil))
 }
+
+// accessLogWriter returns the destination for the HTTP access log. The server
+// always logs to stdout, and it also appends to the file at ACCESS_LOG_PATH if
+// the variable is set, so that the access log analytics source can read it.
+func accessLogWriter() io.Writer {
+	path := os.Getenv("ACCESS_LOG_PATH")
+	if path == "" {
+		return os.Stdout
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatalf("Failed to open access log %s: %s", path, err)
+	}
+	return io.MultiWriter(os.Stdout, f)
+}
@@ -0,0 +1,110 @@
+// Package notifications emails users a summary of their unread
+// notifications.
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/site"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// Sender batches unread notifications into emails.
+type Sender struct {
+	datastore datastore.Datastore
+	mailer    mail.Mailer
+}
+
+// New creates a Sender that emails notifications through the given mailer.
+func New(ds datastore.Datastore, m mail.Mailer) Sender {
+	return Sender{
+		datastore: ds,
+		mailer:    m,
+	}
+}
+
+// SendPending emails each opted-in user a single message that lists their
+// unread notifications that haven't appeared in a previous email.
+func (s Sender) SendPending() error {
+	subscribers, err := s.datastore.GetNotificationEmailSubscribers()
+	if err != nil {
+		return err
+	}
+	for _, p := range subscribers {
+		if err := s.sendPendingForUser(p.Username); err != nil {
+			log.Printf("Failed to email notifications to %s: %s", p.Username, err)
+		}
+	}
+	return nil
+}
+
+func (s Sender) sendPendingForUser(username string) error {
+	unread, err := s.datastore.GetUnreadNotifications(username)
+	if err != nil {
+		return err
+	}
+	pending := []types.Notification{}
+	for _, n := range unread {
+		if !n.Emailed {
+			pending = append(pending, n)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Timestamp > pending[j].Timestamp
+	})
+
+	profile, err := s.datastore.GetUserProfile(username)
+	if _, ok := err.(datastore.UserProfileNotFoundError); ok {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if profile.EmailAddress == "" {
+		return nil
+	}
+
+	subject := fmt.Sprintf("You have %d new notifications on What Got Done", len(pending))
+	if len(pending) == 1 {
+		subject = "You have a new notification on What Got Done"
+	}
+	if err := s.mailer.Send(profile.EmailAddress, subject, render(pending)); err != nil {
+		return err
+	}
+
+	for _, n := range pending {
+		n.Emailed = true
+		if err := s.datastore.SetNotification(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Describe returns a one-line, human-readable summary of a notification.
+func Describe(n types.Notification) string {
+	switch n.Type {
+	case types.NotificationReaction:
+		return fmt.Sprintf("%s reacted %s to your update for the week ending %s", n.Actor, n.Symbol, n.EntryDate)
+	case types.NotificationMention:
+		return fmt.Sprintf("%s mentioned you in their update for the week ending %s", n.Actor, n.EntryDate)
+	}
+	return fmt.Sprintf("%s interacted with the update for the week ending %s", n.Actor, n.EntryDate)
+}
+
+func render(notifications []types.Notification) string {
+	var b strings.Builder
+	b.WriteString("Here's what happened since we last emailed you:\n\n")
+	for _, n := range notifications {
+		fmt.Fprintf(&b, "* %s\n  %s\n", Describe(n), site.EntryURL(n.EntryAuthor, n.EntryDate))
+	}
+	b.WriteString("\nTo stop receiving these emails, turn off notification emails in your profile settings.\n")
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that the notification sender calls.
+	datastore.Datastore
+	subscribers   []types.NotificationPreferences
+	notifications map[string]types.Notification
+	userProfiles  map[string]types.UserProfile
+}
+
+func (ds mockDatastore) GetNotificationEmailSubscribers() ([]types.NotificationPreferences, error) {
+	return ds.subscribers, nil
+}
+
+func (ds mockDatastore) GetUnreadNotifications(recipient string) ([]types.Notification, error) {
+	unread := []types.Notification{}
+	for _, n := range ds.notifications {
+		if n.Recipient == recipient && !n.Read {
+			unread = append(unread, n)
+		}
+	}
+	return unread, nil
+}
+
+func (ds mockDatastore) SetNotification(n types.Notification) error {
+	ds.notifications[n.ID] = n
+	return nil
+}
+
+func (ds mockDatastore) GetUserProfile(username string) (types.UserProfile, error) {
+	p, ok := ds.userProfiles[username]
+	if !ok {
+		return types.UserProfile{}, datastore.UserProfileNotFoundError{Username: username}
+	}
+	return p, nil
+}
+
+type sentMessage struct {
+	to      string
+	subject string
+	body    string
+}
+
+type mockMailer struct {
+	sent []sentMessage
+}
+
+func (m *mockMailer) Send(to string, subject string, body string, headers ...mail.Header) error {
+	m.sent = append(m.sent, sentMessage{to: to, subject: subject, body: body})
+	return nil
+}
+
+func TestSendPending(t *testing.T) {
+	ds := mockDatastore{
+		subscribers: []types.NotificationPreferences{
+			types.NotificationPreferences{Username: "bob", EmailEnabled: true},
+			types.NotificationPreferences{Username: "carol", EmailEnabled: true},
+		},
+		notifications: map[string]types.Notification{
+			"n1": types.Notification{ID: "n1", Recipient: "bob", Type: types.NotificationReaction, Actor: "alice", EntryAuthor: "bob", EntryDate: "2019-11-29", Symbol: "🎉", Timestamp: "2019-11-29T20:00:00Z"},
+			"n2": types.Notification{ID: "n2", Recipient: "bob", Type: types.NotificationReaction, Actor: "carol", EntryAuthor: "bob", EntryDate: "2019-11-22", Symbol: "👍", Timestamp: "2019-11-29T21:00:00Z"},
+			"n3": types.Notification{ID: "n3", Recipient: "bob", Type: types.NotificationReaction, Actor: "dave", EntryAuthor: "bob", EntryDate: "2019-11-22", Symbol: "👍", Timestamp: "2019-11-29T19:00:00Z", Read: true},
+			"n4": types.Notification{ID: "n4", Recipient: "bob", Type: types.NotificationReaction, Actor: "erin", EntryAuthor: "bob", EntryDate: "2019-11-22", Symbol: "👍", Timestamp: "2019-11-29T18:00:00Z", Emailed: true},
+			"n5": types.Notification{ID: "n5", Recipient: "dave", Type: types.NotificationReaction, Actor: "alice", EntryAuthor: "dave", EntryDate: "2019-11-29", Symbol: "👍", Timestamp: "2019-11-29T18:00:00Z"},
+		},
+		userProfiles: map[string]types.UserProfile{
+			"bob":   types.UserProfile{EmailAddress: "bob@example.com"},
+			"carol": types.UserProfile{EmailAddress: "carol@example.com"},
+			"dave":  types.UserProfile{EmailAddress: "dave@example.com"},
+		},
+	}
+	m := mockMailer{}
+	s := New(ds, &m)
+
+	if err := s.SendPending(); err != nil {
+		t.Fatalf("SendPending failed: %v", err)
+	}
+	if len(m.sent) != 1 {
+		t.Fatalf("expected exactly one email, got %d", len(m.sent))
+	}
+	msg := m.sent[0]
+	if msg.to != "bob@example.com" {
+		t.Errorf("unexpected recipient: %s", msg.to)
+	}
+	if msg.subject != "You have 2 new notifications on What Got Done" {
+		t.Errorf("unexpected subject: %s", msg.subject)
+	}
+	for _, s := range []string{
+		"carol reacted 👍 to your update for the week ending 2019-11-22",
+		"alice reacted 🎉 to your update for the week ending 2019-11-29",
+		"https://whatgotdone.com/bob/2019-11-29",
+	} {
+		if !strings.Contains(msg.body, s) {
+			t.Errorf("expected email to contain %q, got: %s", s, msg.body)
+		}
+	}
+	for _, s := range []string{"dave", "erin"} {
+		if strings.Contains(msg.body, s) {
+			t.Errorf("expected email not to contain %q, got: %s", s, msg.body)
+		}
+	}
+	if strings.Index(msg.body, "carol") > strings.Index(msg.body, "alice") {
+		t.Errorf("expected newest notification first, got: %s", msg.body)
+	}
+	if !ds.notifications["n1"].Emailed || !ds.notifications["n2"].Emailed {
+		t.Errorf("expected emailed notifications to be marked as emailed")
+	}
+
+	// Sending again doesn't repeat notifications that were already emailed.
+	if err := s.SendPending(); err != nil {
+		t.Fatalf("SendPending failed: %v", err)
+	}
+	if len(m.sent) != 1 {
+		t.Fatalf("expected no additional emails, got %d", len(m.sent)-1)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	var tests = []struct {
+		explanation string
+		n           types.Notification
+		expected    string
+	}{
+		{
+			"describes reactions",
+			types.Notification{Type: types.NotificationReaction, Actor: "alice", Symbol: "🎉", EntryDate: "2019-11-29"},
+			"alice reacted 🎉 to your update for the week ending 2019-11-29",
+		},
+		{
+			"describes mentions",
+			types.Notification{Type: types.NotificationMention, Actor: "alice", EntryAuthor: "alice", EntryDate: "2019-11-29"},
+			"alice mentioned you in their update for the week ending 2019-11-29",
+		},
+	}
+	for _, tt := range tests {
+		if got := Describe(tt.n); got != tt.expected {
+			t.Errorf("%s: got %q, want %q", tt.explanation, got, tt.expected)
+		}
+	}
+}
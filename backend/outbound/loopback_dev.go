@@ -0,0 +1,7 @@
+// +build dev
+
+package outbound
+
+// allowLoopback lets dev builds send requests to local receivers, such as a
+// webhook listener on localhost.
+const allowLoopback = true
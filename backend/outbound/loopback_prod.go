@@ -0,0 +1,7 @@
+// +build !dev
+
+package outbound
+
+// allowLoopback is false outside dev builds so that user-supplied URLs can't
+// reach services listening on the server itself.
+const allowLoopback = false
@@ -0,0 +1,102 @@
+// Package outbound makes HTTP requests to URLs that users supply, such as
+// webhooks, without letting those URLs reach What Got Done's own network.
+package outbound
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress occurs when a request would connect to an address that
+// user-supplied URLs may not reach, such as a private network address or the
+// cloud metadata server.
+var ErrForbiddenAddress = errors.New("destination address is not publicly routable")
+
+// forbiddenNetworks are the address ranges, beyond loopback, link-local,
+// multicast, and unspecified addresses, that aren't on the public internet.
+var forbiddenNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"fc00::/7",
+)
+
+// NewClient creates an HTTP client for requests to user-supplied URLs. The
+// client refuses to connect to addresses that aren't publicly routable. It
+// checks each address as it dials, after DNS resolution, so that a hostname
+// can't pass a check and then resolve to an internal address. It also doesn't
+// follow redirects, so a public URL can't redirect it to an internal one.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			return checkAddress(address)
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// Ignore proxy settings, since the dialer would check the proxy's
+			// address rather than the destination's.
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func checkAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address: %s", host)
+	}
+	if !isAllowed(ip) {
+		return fmt.Errorf("%s: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+func isAllowed(ip net.IP) bool {
+	if ip.IsLoopback() {
+		return allowLoopback
+	}
+	if ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range forbiddenNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, n)
+	}
+	return networks
+}
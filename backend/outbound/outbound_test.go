@@ -0,0 +1,71 @@
+package outbound
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsAllowed(t *testing.T) {
+	var tests = []struct {
+		ip              string
+		allowedExpected bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", allowLoopback},
+		{"::1", allowLoopback},
+		{"169.254.169.254", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:10.1.2.3", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		allowedActual := isAllowed(net.ParseIP(tt.ip))
+		if allowedActual != tt.allowedExpected {
+			t.Errorf("input [%s], got %v, want %v", tt.ip, allowedActual, tt.allowedExpected)
+		}
+	}
+}
+
+func TestClientRefusesForbiddenAddresses(t *testing.T) {
+	if allowLoopback {
+		t.Skip("dev builds allow loopback addresses")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), ErrForbiddenAddress.Error()) {
+		t.Fatalf("expected request to loopback address to fail, got %v", err)
+	}
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	client := NewClient(time.Second)
+	// Skip the address check so that the test can use a local server.
+	client.Transport = http.DefaultTransport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected the redirect response, got status %d", resp.StatusCode)
+	}
+}
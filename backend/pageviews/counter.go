@@ -0,0 +1,160 @@
+// Package pageviews counts views of What Got Done entries on the server, so
+// that view counts work without a third-party analytics service.
+package pageviews
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+)
+
+// Counter tallies entry page views in memory and periodically adds them to
+// the datastore.
+//
+// To protect reader privacy, the Counter never stores IP addresses or user
+// agents. It identifies a visitor by a keyed hash of their IP address, user
+// agent, and the page they viewed. The hash key is random and changes every
+// day, so visitor keys can't be linked across days, and the Counter forgets
+// them when the day ends.
+type Counter struct {
+	datastore datastore.Datastore
+	mu        sync.Mutex
+	day       string
+	salt      []byte
+	seen      map[string]bool
+	pending   map[pageKey]int
+}
+
+// pageKey identifies the views of a single page on a single day.
+type pageKey struct {
+	path string
+	date string
+}
+
+// New creates a Counter that stores page view counts in the given datastore.
+func New(ds datastore.Datastore) *Counter {
+	return &Counter{
+		datastore: ds,
+		seen:      map[string]bool{},
+		pending:   map[pageKey]int{},
+	}
+}
+
+// Start adds pending page views to the datastore at the given interval in a
+// background goroutine.
+func (c *Counter) Start(interval time.Duration) {
+	go func() {
+		for {
+			time.Sleep(interval)
+			if err := c.Flush(); err != nil {
+				log.Printf("Failed to save page views: %s", err)
+			}
+		}
+	}()
+}
+
+// Record counts a view of the given path unless the same visitor already
+// viewed it today.
+func (c *Counter) Record(path string, ip string, userAgent string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	day := now.UTC().Format("2006-01-02")
+	if day != c.day {
+		c.rotate(day)
+	}
+	key := c.visitorKey(path, ip, userAgent)
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.pending[pageKey{path: path, date: day}]++
+}
+
+// Flush adds all pending page views to the stored daily counts and lifetime
+// totals. It increments the stored counts atomically, so flushes from several
+// server instances can overlap without losing views. It discards views of
+// entries from users who don't exist, as those paths can't be entries.
+func (c *Counter) Flush() error {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = map[pageKey]int{}
+	c.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	users, err := c.datastore.Users()
+	if err != nil {
+		c.restore(pending)
+		return err
+	}
+	knownUsers := map[string]bool{}
+	for _, u := range users {
+		knownUsers[u] = true
+	}
+
+	for k, views := range pending {
+		if !knownUsers[usernameFromPath(k.path)] {
+			delete(pending, k)
+			continue
+		}
+		if err := c.datastore.IncrementDailyPageViews(k.path, k.date, views); err != nil {
+			c.restore(pending)
+			return err
+		}
+		// The daily count is already saved, so don't retry these views even if
+		// the total fails to update. Otherwise, the retry would count them twice.
+		delete(pending, k)
+		if err := c.datastore.IncrementPageViews(k.path, views); err != nil {
+			c.restore(pending)
+			return err
+		}
+	}
+	return nil
+}
+
+// restore returns unsaved page views to the pending counts so that the next
+// flush can retry them.
+func (c *Counter) restore(unsaved map[pageKey]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, views := range unsaved {
+		c.pending[k] += views
+	}
+}
+
+// rotate starts a new day with a fresh hash key and forgets the previous day's
+// visitors. Callers must hold c.mu.
+func (c *Counter) rotate(day string) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		panic(err)
+	}
+	c.day = day
+	c.salt = salt
+	c.seen = map[string]bool{}
+}
+
+func (c *Counter) visitorKey(path string, ip string, userAgent string) string {
+	h := hmac.New(sha256.New, c.salt)
+	h.Write([]byte(ip + "\x00" + userAgent + "\x00" + path))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func usernameFromPath(path string) string {
+	// Paths have the form /{username}/{date}.
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
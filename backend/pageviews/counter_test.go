@@ -0,0 +1,146 @@
+package pageviews
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	datastore.Datastore
+	users     []string
+	pageViews map[string]int
+	daily     map[string]int
+	failWrite bool
+}
+
+func (ds *mockDatastore) Users() ([]string, error) {
+	return ds.users, nil
+}
+
+func (ds *mockDatastore) GetPageViews(path string) (int, error) {
+	views, ok := ds.pageViews[path]
+	if !ok {
+		return 0, datastore.PageViewsNotFoundError{Path: path}
+	}
+	return views, nil
+}
+
+func (ds *mockDatastore) IncrementPageViews(path string, n int) error {
+	if ds.failWrite {
+		return errors.New("dummy write failure")
+	}
+	ds.pageViews[path] += n
+	return nil
+}
+
+func (ds *mockDatastore) GetDailyPageViews(path string, from string, to string) ([]types.DailyPageViews, error) {
+	daily := []types.DailyPageViews{}
+	for key, views := range ds.daily {
+		parts := strings.SplitN(key, " ", 2)
+		if parts[0] == path && parts[1] >= from && parts[1] <= to {
+			daily = append(daily, types.DailyPageViews{Path: path, Date: parts[1], Views: views})
+		}
+	}
+	return daily, nil
+}
+
+func (ds *mockDatastore) IncrementDailyPageViews(path string, date string, n int) error {
+	if ds.failWrite {
+		return errors.New("dummy write failure")
+	}
+	ds.daily[path+" "+date] += n
+	return nil
+}
+
+func mustParseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCounterDeduplicatesVisitorsPerDay(t *testing.T) {
+	ds := mockDatastore{
+		users: []string{"alice", "bob"},
+		pageViews: map[string]int{
+			"/alice/2019-11-29": 10,
+		},
+		daily: map[string]int{
+			"/alice/2019-11-29 2019-11-30": 4,
+		},
+	}
+	c := New(&ds)
+
+	morning := mustParseTime("2019-11-30T09:00:00Z")
+	evening := mustParseTime("2019-11-30T21:00:00Z")
+	nextDay := mustParseTime("2019-12-01T09:00:00Z")
+
+	c.Record("/alice/2019-11-29", "203.0.113.1", "Firefox", morning)
+	// The same visitor reloading the page later that day doesn't count again.
+	c.Record("/alice/2019-11-29", "203.0.113.1", "Firefox", evening)
+	// A different browser on the same network counts as a different visitor.
+	c.Record("/alice/2019-11-29", "203.0.113.1", "Chrome", evening)
+	// The same visitor viewing a different entry counts.
+	c.Record("/bob/2019-11-29", "203.0.113.1", "Firefox", evening)
+	// Views of paths from users who don't exist are discarded.
+	c.Record("/mallory/2019-11-29", "203.0.113.1", "Firefox", evening)
+	// The same visitor returning the next day counts again.
+	c.Record("/alice/2019-11-29", "203.0.113.1", "Firefox", nextDay)
+
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	expected := map[string]int{
+		"/alice/2019-11-29": 13,
+		"/bob/2019-11-29":   1,
+	}
+	if !reflect.DeepEqual(ds.pageViews, expected) {
+		t.Fatalf("unexpected page views: got %v want %v", ds.pageViews, expected)
+	}
+	dailyExpected := map[string]int{
+		"/alice/2019-11-29 2019-11-30": 6,
+		"/alice/2019-11-29 2019-12-01": 1,
+		"/bob/2019-11-29 2019-11-30":   1,
+	}
+	if !reflect.DeepEqual(ds.daily, dailyExpected) {
+		t.Fatalf("unexpected daily page views: got %v want %v", ds.daily, dailyExpected)
+	}
+
+	// Flushing again without new views doesn't change the totals.
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if !reflect.DeepEqual(ds.pageViews, expected) {
+		t.Fatalf("unexpected page views after second flush: got %v want %v", ds.pageViews, expected)
+	}
+}
+
+func TestCounterRetriesFailedWrites(t *testing.T) {
+	ds := mockDatastore{
+		users:     []string{"alice"},
+		pageViews: map[string]int{},
+		daily:     map[string]int{},
+		failWrite: true,
+	}
+	c := New(&ds)
+
+	c.Record("/alice/2019-11-29", "203.0.113.1", "Firefox", mustParseTime("2019-11-30T09:00:00Z"))
+	if err := c.Flush(); err == nil {
+		t.Fatalf("expected Flush to fail")
+	}
+
+	ds.failWrite = false
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if views := ds.pageViews["/alice/2019-11-29"]; views != 1 {
+		t.Fatalf("unexpected page views: got %d want 1", views)
+	}
+}
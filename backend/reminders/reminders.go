@@ -0,0 +1,146 @@
+// Package reminders emails users who haven't yet published their What Got
+// Done update for the current week.
+package reminders
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/dates"
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/site"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// DefaultSendTime is the time of day that we send reminders to users who
+// haven't chosen a time.
+const DefaultSendTime = "17:00"
+
+// DefaultTimeZone is the time zone we use for users who haven't chosen one.
+const DefaultTimeZone = "UTC"
+
+// Sender sends weekly reminder emails.
+type Sender struct {
+	datastore datastore.Datastore
+	mailer    mail.Mailer
+}
+
+// New creates a Sender that emails reminders through the given mailer.
+func New(ds datastore.Datastore, m mail.Mailer) Sender {
+	return Sender{
+		datastore: ds,
+		mailer:    m,
+	}
+}
+
+// SendDue emails a reminder to every opted-in user whose send time has passed
+// on Friday in their time zone and who has not yet published an entry for the
+// current week. Each user receives at most one reminder per week.
+func (s Sender) SendDue(now time.Time) error {
+	prefs, err := s.datastore.GetEnabledReminders()
+	if err != nil {
+		return err
+	}
+	for _, p := range prefs {
+		if err := s.sendIfDue(p, now); err != nil {
+			// Log and continue so that one bad user record doesn't block reminders
+			// for everyone else.
+			log.Printf("Failed to send reminder to %s: %s", p.Username, err)
+		}
+	}
+	return nil
+}
+
+func (s Sender) sendIfDue(p types.ReminderPreferences, now time.Time) error {
+	date, due, err := isDue(p, now)
+	if err != nil || !due {
+		return err
+	}
+
+	published, err := hasPublished(s.datastore, p.Username, date)
+	if err != nil {
+		return err
+	}
+	if published {
+		return nil
+	}
+
+	profile, err := s.datastore.GetUserProfile(p.Username)
+	if _, ok := err.(datastore.UserProfileNotFoundError); ok {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if profile.EmailAddress == "" {
+		return nil
+	}
+
+	if err := s.mailer.Send(profile.EmailAddress, "Reminder: Share what you got done this week", reminderBody(date)); err != nil {
+		return err
+	}
+	log.Printf("Sent reminder to %s for %s", p.Username, date)
+
+	p.LastSentFor = date
+	return s.datastore.SetReminderPreferences(p.Username, p)
+}
+
+func reminderBody(date string) string {
+	return fmt.Sprintf(`Hi there,
+
+You haven't published your What Got Done update for the week ending %s yet. Take a few minutes to write down what you accomplished this week:
+
+%s/entry/edit/%s
+
+To stop receiving these reminders, turn them off in your profile settings.
+`, date, site.URL, date)
+}
+
+// isDue returns the entry date for the current week in the user's time zone
+// and whether the user should receive a reminder for that date.
+func isDue(p types.ReminderPreferences, now time.Time) (string, bool, error) {
+	tz := p.TimeZone
+	if tz == "" {
+		tz = DefaultTimeZone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return "", false, err
+	}
+	local := now.In(loc)
+	if local.Weekday() != time.Friday {
+		return "", false, nil
+	}
+
+	sendTime := p.SendTime
+	if sendTime == "" {
+		sendTime = DefaultSendTime
+	}
+	st, err := time.Parse("15:04", sendTime)
+	if err != nil {
+		return "", false, err
+	}
+	if local.Hour()*60+local.Minute() < st.Hour()*60+st.Minute() {
+		return "", false, nil
+	}
+
+	date := dates.WeekEnding(local)
+	if p.LastSentFor == date {
+		return "", false, nil
+	}
+	return date, true, nil
+}
+
+func hasPublished(ds datastore.Datastore, username string, date string) (bool, error) {
+	entries, err := ds.GetEntries(username)
+	if err != nil {
+		return false, err
+	}
+	for _, j := range entries {
+		if j.Date == date {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,189 @@
+package reminders
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/mail"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that the reminder sender calls.
+	datastore.Datastore
+	reminders      map[string]types.ReminderPreferences
+	journalEntries map[string][]types.JournalEntry
+	userProfiles   map[string]types.UserProfile
+}
+
+func (ds mockDatastore) GetEnabledReminders() ([]types.ReminderPreferences, error) {
+	prefs := []types.ReminderPreferences{}
+	for _, p := range ds.reminders {
+		if p.Enabled {
+			prefs = append(prefs, p)
+		}
+	}
+	return prefs, nil
+}
+
+func (ds mockDatastore) SetReminderPreferences(username string, p types.ReminderPreferences) error {
+	ds.reminders[username] = p
+	return nil
+}
+
+func (ds mockDatastore) GetEntries(username string) ([]types.JournalEntry, error) {
+	return ds.journalEntries[username], nil
+}
+
+func (ds mockDatastore) GetUserProfile(username string) (types.UserProfile, error) {
+	p, ok := ds.userProfiles[username]
+	if !ok {
+		return types.UserProfile{}, datastore.UserProfileNotFoundError{Username: username}
+	}
+	return p, nil
+}
+
+type mockMailer struct {
+	recipients []string
+}
+
+func (m *mockMailer) Send(to string, subject string, body string, headers ...mail.Header) error {
+	m.recipients = append(m.recipients, to)
+	return nil
+}
+
+func TestSendDue(t *testing.T) {
+	friday := time.Date(2019, time.November, 29, 18, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		explanation         string
+		now                 time.Time
+		prefs               types.ReminderPreferences
+		entries             []types.JournalEntry
+		emailAddress        string
+		recipientsExpected  []string
+		lastSentForExpected string
+	}{
+		{
+			"sends reminder after send time on Friday",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "17:00", TimeZone: "UTC"},
+			[]types.JournalEntry{},
+			"bob@example.com",
+			[]string{"bob@example.com"},
+			"2019-11-29",
+		},
+		{
+			"uses default send time and time zone",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true},
+			[]types.JournalEntry{},
+			"bob@example.com",
+			[]string{"bob@example.com"},
+			"2019-11-29",
+		},
+		{
+			"does not send before send time",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "19:00", TimeZone: "UTC"},
+			[]types.JournalEntry{},
+			"bob@example.com",
+			nil,
+			"",
+		},
+		{
+			"respects user's time zone",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "17:00", TimeZone: "America/New_York"},
+			[]types.JournalEntry{},
+			"bob@example.com",
+			nil,
+			"",
+		},
+		{
+			"does not send on other days of the week",
+			friday.AddDate(0, 0, -1),
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "17:00", TimeZone: "UTC"},
+			[]types.JournalEntry{},
+			"bob@example.com",
+			nil,
+			"",
+		},
+		{
+			"does not send if user already published this week",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "17:00", TimeZone: "UTC"},
+			[]types.JournalEntry{
+				types.JournalEntry{Date: "2019-11-29", Markdown: "Wrote some code"},
+			},
+			"bob@example.com",
+			nil,
+			"",
+		},
+		{
+			"sends if user only published previous weeks",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "17:00", TimeZone: "UTC"},
+			[]types.JournalEntry{
+				types.JournalEntry{Date: "2019-11-22", Markdown: "Wrote some code"},
+			},
+			"bob@example.com",
+			[]string{"bob@example.com"},
+			"2019-11-29",
+		},
+		{
+			"does not send twice in the same week",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "17:00", TimeZone: "UTC", LastSentFor: "2019-11-29"},
+			[]types.JournalEntry{},
+			"bob@example.com",
+			nil,
+			"2019-11-29",
+		},
+		{
+			"does not send if user has no email address",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: true, SendTime: "17:00", TimeZone: "UTC"},
+			[]types.JournalEntry{},
+			"",
+			nil,
+			"",
+		},
+		{
+			"does not send if reminders are disabled",
+			friday,
+			types.ReminderPreferences{Username: "bob", Enabled: false, SendTime: "17:00", TimeZone: "UTC"},
+			[]types.JournalEntry{},
+			"bob@example.com",
+			nil,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		ds := mockDatastore{
+			reminders: map[string]types.ReminderPreferences{
+				"bob": tt.prefs,
+			},
+			journalEntries: map[string][]types.JournalEntry{
+				"bob": tt.entries,
+			},
+			userProfiles: map[string]types.UserProfile{
+				"bob": types.UserProfile{EmailAddress: tt.emailAddress},
+			},
+		}
+		m := mockMailer{}
+		s := New(ds, &m)
+
+		if err := s.SendDue(tt.now); err != nil {
+			t.Fatalf("%s: SendDue failed: %v", tt.explanation, err)
+		}
+		if !reflect.DeepEqual(m.recipients, tt.recipientsExpected) {
+			t.Errorf("%s: unexpected recipients: got %v want %v", tt.explanation, m.recipients, tt.recipientsExpected)
+		}
+		if lastSentFor := ds.reminders["bob"].LastSentFor; lastSentFor != tt.lastSentForExpected {
+			t.Errorf("%s: unexpected lastSentFor: got %v want %v", tt.explanation, lastSentFor, tt.lastSentForExpected)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Package scheduler runs the server's periodic background jobs. When several
+// server instances share a datastore, each job holds a lease in the datastore
+// while it runs so that only one instance runs it per interval.
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+// Job is a unit of periodic work.
+type Job struct {
+	// Name identifies the job across server instances, so it must be unique.
+	Name string
+	// Interval is the minimum time between the starts of consecutive runs.
+	Interval time.Duration
+	// Run performs the job's work.
+	Run func(now time.Time) error
+}
+
+// Scheduler runs registered jobs at their intervals.
+type Scheduler struct {
+	datastore  datastore.Datastore
+	instanceID string
+	mu         sync.Mutex
+	jobs       []Job
+	running    map[string]bool
+}
+
+// New creates a Scheduler that coordinates with other server instances
+// through the given datastore.
+func New(ds datastore.Datastore) *Scheduler {
+	return &Scheduler{
+		datastore:  ds,
+		instanceID: newInstanceID(),
+		running:    map[string]bool{},
+	}
+}
+
+// Register adds a job to the schedule. Register jobs before calling Start.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+}
+
+// Start checks for due jobs every tick in a background goroutine. Each due job
+// runs in its own goroutine so that a slow job doesn't delay the others.
+func (s *Scheduler) Start(tick time.Duration) {
+	go func() {
+		for {
+			s.StartDue(time.Now())
+			time.Sleep(tick)
+		}
+	}()
+}
+
+// StartDue starts every job that is due at the given time, each in its own
+// goroutine, and returns without waiting for them to finish.
+func (s *Scheduler) StartDue(now time.Time) {
+	for _, job := range s.registeredJobs() {
+		go s.runIfDue(job, now)
+	}
+}
+
+// RunDue runs every job that is due at the given time, one after another.
+func (s *Scheduler) RunDue(now time.Time) {
+	for _, job := range s.registeredJobs() {
+		s.runIfDue(job, now)
+	}
+}
+
+// Statuses returns the status of every registered job, sorted by name. Jobs
+// that haven't run yet have only a name and interval.
+func (s *Scheduler) Statuses() ([]types.JobStatus, error) {
+	stored, err := s.datastore.GetJobStatuses()
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]types.JobStatus{}
+	for _, st := range stored {
+		byName[st.Name] = st
+	}
+	statuses := []types.JobStatus{}
+	for _, job := range s.registeredJobs() {
+		st, ok := byName[job.Name]
+		if !ok {
+			st = types.JobStatus{Name: job.Name}
+		}
+		st.Interval = job.Interval.String()
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses, nil
+}
+
+func (s *Scheduler) registeredJobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, len(s.jobs))
+	copy(jobs, s.jobs)
+	return jobs
+}
+
+// runIfDue runs the job if no instance has run it within its interval. The
+// lease it acquires lasts for the whole interval, so it also prevents any
+// instance from starting the job again too soon.
+func (s *Scheduler) runIfDue(job Job, now time.Time) {
+	if !s.markRunning(job.Name) {
+		return
+	}
+	defer s.markFinished(job.Name)
+
+	acquired, err := s.datastore.AcquireJobLease(job.Name, s.instanceID, now, now.Add(job.Interval))
+	if err != nil {
+		log.Printf("Failed to acquire lease for job %s: %s", job.Name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	status := s.previousStatus(job.Name)
+	status.Name = job.Name
+	status.Interval = job.Interval.String()
+	status.Instance = s.instanceID
+	status.LastStarted = now.UTC().Format(time.RFC3339)
+
+	runErr := job.Run(now)
+
+	finished := time.Now().UTC().Format(time.RFC3339)
+	status.LastFinished = finished
+	if runErr != nil {
+		log.Printf("Job %s failed: %s", job.Name, runErr)
+		status.Failing = true
+		status.LastError = runErr.Error()
+	} else {
+		status.Failing = false
+		status.LastError = ""
+		status.LastSucceeded = finished
+	}
+	if err := s.datastore.SetJobStatus(status); err != nil {
+		log.Printf("Failed to save status of job %s: %s", job.Name, err)
+	}
+}
+
+// previousStatus returns the job's stored status so that a new run preserves
+// the time of its last success.
+func (s *Scheduler) previousStatus(name string) types.JobStatus {
+	statuses, err := s.datastore.GetJobStatuses()
+	if err != nil {
+		log.Printf("Failed to retrieve job statuses: %s", err)
+		return types.JobStatus{}
+	}
+	for _, st := range statuses {
+		if st.Name == name {
+			return st
+		}
+	}
+	return types.JobStatus{}
+}
+
+// markRunning records that this instance is running the job. It returns false
+// if the job is already running here.
+func (s *Scheduler) markRunning(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[name] {
+		return false
+	}
+	s.running[name] = true
+	return true
+}
+
+func (s *Scheduler) markFinished(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, name)
+}
+
+// newInstanceID returns an identifier for this server process that is unique
+// among instances sharing the datastore.
+func newInstanceID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return hostname + "-" + hex.EncodeToString(b)
+}
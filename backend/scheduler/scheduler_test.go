@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that the scheduler calls.
+	datastore.Datastore
+	mu       sync.Mutex
+	leases   map[string]types.JobLease
+	statuses map[string]types.JobStatus
+}
+
+func newMockDatastore() *mockDatastore {
+	return &mockDatastore{
+		leases:   map[string]types.JobLease{},
+		statuses: map[string]types.JobStatus{},
+	}
+}
+
+func (ds *mockDatastore) AcquireJobLease(job string, holder string, now time.Time, until time.Time) (bool, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if lease, ok := ds.leases[job]; ok && lease.Until.After(now) {
+		return false, nil
+	}
+	ds.leases[job] = types.JobLease{Job: job, Holder: holder, Until: until}
+	return true, nil
+}
+
+func (ds *mockDatastore) GetJobStatuses() ([]types.JobStatus, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	statuses := []types.JobStatus{}
+	for _, s := range ds.statuses {
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func (ds *mockDatastore) SetJobStatus(s types.JobStatus) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.statuses[s.Name] = s
+	return nil
+}
+
+func TestOnlyOneInstanceRunsJobPerInterval(t *testing.T) {
+	ds := newMockDatastore()
+	runs := map[string]int{}
+	newInstance := func(name string) *Scheduler {
+		s := New(ds)
+		s.Register(Job{
+			Name:     "send-digests",
+			Interval: 15 * time.Minute,
+			Run: func(now time.Time) error {
+				runs[name]++
+				return nil
+			},
+		})
+		return s
+	}
+	a := newInstance("a")
+	b := newInstance("b")
+
+	start := time.Date(2019, 11, 29, 12, 0, 0, 0, time.UTC)
+	a.RunDue(start)
+	b.RunDue(start.Add(time.Minute))
+	a.RunDue(start.Add(5 * time.Minute))
+	if runs["a"] != 1 || runs["b"] != 0 {
+		t.Fatalf("expected only the first run within the interval, got %v", runs)
+	}
+
+	// Once the interval passes, whichever instance checks first runs the job.
+	b.RunDue(start.Add(16 * time.Minute))
+	a.RunDue(start.Add(17 * time.Minute))
+	if runs["a"] != 1 || runs["b"] != 1 {
+		t.Fatalf("expected the second instance to run after the interval, got %v", runs)
+	}
+}
+
+func TestRunRecordsJobStatus(t *testing.T) {
+	ds := newMockDatastore()
+	s := New(ds)
+	fail := false
+	s.Register(Job{
+		Name:     "refresh-analytics",
+		Interval: 10 * time.Minute,
+		Run: func(now time.Time) error {
+			if fail {
+				return errors.New("dummy analytics error")
+			}
+			return nil
+		},
+	})
+	s.Register(Job{
+		Name:     "email-notifications",
+		Interval: time.Hour,
+		Run: func(now time.Time) error {
+			return nil
+		},
+	})
+
+	start := time.Date(2019, 11, 29, 12, 0, 0, 0, time.UTC)
+	s.RunDue(start)
+	succeeded := ds.statuses["refresh-analytics"]
+	if succeeded.Failing || succeeded.LastSucceeded == "" || succeeded.LastStarted != "2019-11-29T12:00:00Z" {
+		t.Fatalf("unexpected status after success: %+v", succeeded)
+	}
+
+	fail = true
+	s.RunDue(start.Add(10 * time.Minute))
+	failed := ds.statuses["refresh-analytics"]
+	if !failed.Failing || failed.LastError != "dummy analytics error" {
+		t.Errorf("unexpected status after failure: %+v", failed)
+	}
+	if failed.LastSucceeded != succeeded.LastSucceeded {
+		t.Errorf("expected failed run to keep last success time %s, got %s", succeeded.LastSucceeded, failed.LastSucceeded)
+	}
+
+	statuses, err := s.Statuses()
+	if err != nil {
+		t.Fatalf("Statuses failed: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "email-notifications" || statuses[1].Name != "refresh-analytics" {
+		t.Fatalf("unexpected statuses: %+v", statuses)
+	}
+	if statuses[0].Interval != "1h0m0s" || statuses[1].Interval != "10m0s" {
+		t.Errorf("unexpected intervals: %s, %s", statuses[0].Interval, statuses[1].Interval)
+	}
+}
+
+func TestJobDoesNotOverlapItselfOnOneInstance(t *testing.T) {
+	ds := newMockDatastore()
+	s := New(ds)
+	started := make(chan bool)
+	release := make(chan bool)
+	runs := 0
+	job := Job{
+		Name:     "slow-job",
+		Interval: time.Millisecond,
+		Run: func(now time.Time) error {
+			runs++
+			started <- true
+			<-release
+			return nil
+		},
+	}
+	s.Register(job)
+
+	start := time.Date(2019, 11, 29, 12, 0, 0, 0, time.UTC)
+	go s.RunDue(start)
+	<-started
+	// The lease has expired, but the first run is still in progress.
+	s.RunDue(start.Add(time.Hour))
+	close(release)
+	if runs != 1 {
+		t.Errorf("expected one run, got %d", runs)
+	}
+}
@@ -0,0 +1,184 @@
+// Package search provides the text-processing functions that back What Got
+// Done's full-text search over published journal entries.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minimumTermLength is the shortest word (in runes) that we add to the search
+// index. Shorter words are too common to be useful search terms.
+const minimumTermLength = 2
+
+// snippetRadius is the approximate number of bytes of context to include on
+// either side of the first match in a search snippet.
+const snippetRadius = 80
+
+type wordSpan struct {
+	start int
+	end   int
+}
+
+// Tokenize splits text into the unique, lowercase search terms it contains,
+// sorted alphabetically.
+func Tokenize(text string) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, span := range findWords(text) {
+		term := strings.ToLower(text[span.start:span.end])
+		if utf8.RuneCountInString(term) < minimumTermLength || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+// MostSelectiveTerm returns the term to look up in the search index for a
+// query. Longer words tend to appear in fewer entries, so it picks the longest
+// term, breaking ties alphabetically. Callers must check the remaining terms
+// against each result.
+func MostSelectiveTerm(terms []string) string {
+	best := ""
+	for _, t := range terms {
+		n, bestN := utf8.RuneCountInString(t), utf8.RuneCountInString(best)
+		if n > bestN || (n == bestN && t < best) {
+			best = t
+		}
+	}
+	return best
+}
+
+// MatchesAll returns true if text contains every one of the given terms.
+func MatchesAll(text string, terms []string) bool {
+	tokens := map[string]bool{}
+	for _, t := range Tokenize(text) {
+		tokens[t] = true
+	}
+	for _, t := range terms {
+		if !tokens[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// Snippet returns a short excerpt of text around the first occurrence of any
+// of the given terms. Each matching word in the excerpt is wrapped in markdown
+// bold markers so that clients can render the highlights.
+func Snippet(text string, terms []string) string {
+	termSet := map[string]bool{}
+	for _, t := range terms {
+		termSet[strings.ToLower(t)] = true
+	}
+
+	words := findWords(text)
+	matches := []wordSpan{}
+	for _, span := range words {
+		if termSet[strings.ToLower(text[span.start:span.end])] {
+			matches = append(matches, span)
+		}
+	}
+	if len(matches) == 0 {
+		return collapseWhitespace(truncate(text, 2*snippetRadius))
+	}
+
+	start := snapToWordStart(words, matches[0].start-snippetRadius)
+	end := snapToWordEnd(text, words, matches[0].end+snippetRadius)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	cursor := start
+	for _, m := range matches {
+		if m.start < start || m.end > end {
+			continue
+		}
+		b.WriteString(text[cursor:m.start])
+		b.WriteString("**")
+		b.WriteString(text[m.start:m.end])
+		b.WriteString("**")
+		cursor = m.end
+	}
+	b.WriteString(text[cursor:end])
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return collapseWhitespace(b.String())
+}
+
+func findWords(text string) []wordSpan {
+	spans := []wordSpan{}
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			spans = append(spans, wordSpan{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, wordSpan{start, len(text)})
+	}
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// snapToWordStart returns the start of the first word that begins at or after
+// pos, so that snippets never begin in the middle of a word.
+func snapToWordStart(words []wordSpan, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+	for _, w := range words {
+		if w.start >= pos {
+			return w.start
+		}
+	}
+	return pos
+}
+
+// snapToWordEnd returns the end of the last word that ends at or before pos,
+// so that snippets never end in the middle of a word. If pos is past the final
+// word, the snippet extends to the end of text.
+func snapToWordEnd(text string, words []wordSpan, pos int) int {
+	if len(words) > 0 && pos >= words[len(words)-1].end {
+		return len(text)
+	}
+	end := 0
+	for _, w := range words {
+		if w.end > pos {
+			break
+		}
+		end = w.end
+	}
+	return end
+}
+
+func truncate(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	for maxLength > 0 && !utf8.RuneStart(text[maxLength]) {
+		maxLength--
+	}
+	return text[:maxLength] + "…"
+}
+
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,161 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	var tests = []struct {
+		explanation   string
+		text          string
+		termsExpected []string
+	}{
+		{
+			"splits on whitespace and punctuation",
+			"Shipped the new billing-page.",
+			[]string{"billing", "new", "page", "shipped", "the"},
+		},
+		{
+			"lowercases and removes duplicates",
+			"Fixed the Bug, then fixed another bug",
+			[]string{"another", "bug", "fixed", "the", "then"},
+		},
+		{
+			"ignores markdown syntax",
+			"# Project X\n\n* **Wrote** [docs](https://example.com)",
+			[]string{"com", "docs", "example", "https", "project", "wrote"},
+		},
+		{
+			"skips single-character words",
+			"I ate a sandwich",
+			[]string{"ate", "sandwich"},
+		},
+		{
+			"keeps non-English letters",
+			"Déployé le café",
+			[]string{"café", "déployé", "le"},
+		},
+		{
+			"empty text has no terms",
+			"",
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		termsActual := Tokenize(tt.text)
+		if !reflect.DeepEqual(termsActual, tt.termsExpected) {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.text, termsActual, tt.termsExpected)
+		}
+	}
+}
+
+func TestMostSelectiveTerm(t *testing.T) {
+	var tests = []struct {
+		explanation  string
+		terms        []string
+		termExpected string
+	}{
+		{
+			"picks the longest term",
+			[]string{"and", "kubernetes", "the"},
+			"kubernetes",
+		},
+		{
+			"breaks ties alphabetically",
+			[]string{"fixed", "build"},
+			"build",
+		},
+		{
+			"counts characters rather than bytes",
+			[]string{"café", "tests"},
+			"tests",
+		},
+		{
+			"single term",
+			[]string{"outage"},
+			"outage",
+		},
+	}
+	for _, tt := range tests {
+		term := MostSelectiveTerm(tt.terms)
+		if term != tt.termExpected {
+			t.Errorf("%s: MostSelectiveTerm(%v)=%s, want %s", tt.explanation, tt.terms, term, tt.termExpected)
+		}
+	}
+}
+
+func TestMatchesAll(t *testing.T) {
+	var tests = []struct {
+		explanation     string
+		text            string
+		terms           []string
+		matchesExpected bool
+	}{
+		{
+			"matches when all terms are present",
+			"Shipped the new billing page",
+			[]string{"billing", "shipped"},
+			true,
+		},
+		{
+			"does not match when a term is missing",
+			"Shipped the new billing page",
+			[]string{"billing", "invoices"},
+			false,
+		},
+		{
+			"does not match partial words",
+			"Shipped the new billing page",
+			[]string{"bill"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		matchesActual := MatchesAll(tt.text, tt.terms)
+		if matchesActual != tt.matchesExpected {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.text, matchesActual, tt.matchesExpected)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	var tests = []struct {
+		explanation     string
+		text            string
+		terms           []string
+		snippetExpected string
+	}{
+		{
+			"highlights every matching word",
+			"Shipped the billing page. Billing now supports coupons.",
+			[]string{"billing"},
+			"Shipped the **billing** page. **Billing** now supports coupons.",
+		},
+		{
+			"collapses newlines",
+			"# Billing\n\n* Shipped the new page",
+			[]string{"shipped"},
+			"# Billing * **Shipped** the new page",
+		},
+		{
+			"trims long text around the first match",
+			strings.Repeat("lorem ", 30) + "billing" + strings.Repeat(" ipsum", 30),
+			[]string{"billing"},
+			"…" + strings.TrimSpace(strings.Repeat("lorem ", 13)) + " **billing** " + strings.TrimSpace(strings.Repeat("ipsum ", 13)) + "…",
+		},
+		{
+			"returns the beginning of the text when nothing matches",
+			"Shipped the billing page",
+			[]string{"coupons"},
+			"Shipped the billing page",
+		},
+	}
+	for _, tt := range tests {
+		snippetActual := Snippet(tt.text, tt.terms)
+		if snippetActual != tt.snippetExpected {
+			t.Errorf("%s: got [%s], want [%s]", tt.explanation, snippetActual, tt.snippetExpected)
+		}
+	}
+}
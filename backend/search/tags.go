@@ -0,0 +1,62 @@
+package search
+
+import (
+	"bufio"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mtlynch/whatgotdone/backend/codefence"
+)
+
+var (
+	tagPattern        = regexp.MustCompile(`(?:^|[^\w&/#])#([A-Za-z][A-Za-z0-9_-]*)`)
+	validTagPattern   = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,99}$`)
+	inlineCodePattern = regexp.MustCompile("`[^`]*`")
+	headingPattern    = regexp.MustCompile(`^#{1,6}(\s|$)`)
+)
+
+// ValidTag returns true if a hashtag (without its leading #) is one that
+// ReadTags can return. Tags must start with a lowercase letter, contain only
+// lowercase letters, numbers, hyphens, and underscores, and be at most 100
+// characters long.
+func ValidTag(tag string) bool {
+	return validTagPattern.MatchString(tag)
+}
+
+// ReadTags returns the unique hashtags (e.g., #hiring) that appear in an
+// entry's markdown, lowercased and sorted alphabetically. Hashtags inside
+// headings, fenced code blocks, or inline code don't count, and neither do
+// hashtags too long to be valid tags.
+func ReadTags(markdown string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(markdown))
+	fence := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		if fence != "" {
+			if codefence.Closes(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if fence = codefence.Opening(line); fence != "" {
+			continue
+		}
+		if headingPattern.MatchString(line) {
+			continue
+		}
+		line = inlineCodePattern.ReplaceAllString(line, "")
+		for _, match := range tagPattern.FindAllStringSubmatch(line, -1) {
+			tag := strings.ToLower(match[1])
+			if seen[tag] || !ValidTag(tag) {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTags(t *testing.T) {
+	var tests = []struct {
+		explanation  string
+		markdown     string
+		tagsExpected []string
+	}{
+		{
+			"finds tags in body text",
+			"* Fixed the outage #incident\n* Interviewed two candidates #hiring",
+			[]string{"hiring", "incident"},
+		},
+		{
+			"lowercases and removes duplicate tags",
+			"#Hiring is going well. More #hiring next week.",
+			[]string{"hiring"},
+		},
+		{
+			"allows hyphens, underscores, and digits after the first letter",
+			"Wrapped up #q4-planning and #team_offsite2",
+			[]string{"q4-planning", "team_offsite2"},
+		},
+		{
+			"ignores headings",
+			"# Project\n\n## Incident review\n\n* Nothing to report",
+			[]string{},
+		},
+		{
+			"ignores tags inside headings",
+			"# Hiring #recruiting\n\n* Posted a job #jobs",
+			[]string{"jobs"},
+		},
+		{
+			"ignores tags in fenced code blocks",
+			"```\n#include <stdio.h>\n```\n\n* Fixed the build #c",
+			[]string{"c"},
+		},
+		{
+			"ignores tags in tilde-fenced code blocks",
+			"~~~c\n#include <stdio.h>\n```\n#define DEBUG\n~~~\n\n* Fixed the build #c",
+			[]string{"c"},
+		},
+		{
+			"ignores tags in indented code fences",
+			"  ```\n#include <stdio.h>\n  ```\n\n* Fixed the build #c",
+			[]string{"c"},
+		},
+		{
+			"ignores tags in inline code",
+			"* Set `#define DEBUG` in the build #tooling",
+			[]string{"tooling"},
+		},
+		{
+			"ignores issue numbers, URL fragments, and HTML entities",
+			"* Fixed #123 (see https://example.com/docs#setup) &#39;",
+			[]string{},
+		},
+		{
+			"ignores tags longer than 100 characters",
+			"* Named a variable #" + strings.Repeat("a", 101) + " #ok",
+			[]string{"ok"},
+		},
+		{
+			"empty entry has no tags",
+			"",
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		tagsActual := ReadTags(tt.markdown)
+		if !reflect.DeepEqual(tagsActual, tt.tagsExpected) {
+			t.Errorf("%s: input [%s], got %v, want %v", tt.explanation, tt.markdown, tagsActual, tt.tagsExpected)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Package site builds links to pages on the production What Got Done site, for
+// use in emails, chat messages, and other places outside the web app.
+package site
+
+import "fmt"
+
+// URL is the root URL of the production What Got Done site.
+const URL = "https://whatgotdone.com"
+
+// EntryURL returns the link to a published entry.
+func EntryURL(author string, date string) string {
+	return fmt.Sprintf("%s/%s/%s", URL, author, date)
+}
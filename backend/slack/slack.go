@@ -0,0 +1,72 @@
+// Package slack posts messages to Slack-compatible incoming webhooks.
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/outbound"
+)
+
+// Message is the JSON body of a Slack incoming webhook request.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Poster sends messages to incoming webhooks.
+type Poster struct {
+	// Client sends the webhook requests. By default, it only connects to public
+	// addresses and doesn't follow redirects.
+	Client *http.Client
+}
+
+// New creates a Poster with a default HTTP timeout.
+func New() Poster {
+	return Poster{
+		Client: outbound.NewClient(10 * time.Second),
+	}
+}
+
+// EntryMessage formats a message announcing a new entry. entryURL links to
+// the published entry, and projects lists the entry's project headings.
+func EntryMessage(author string, date string, entryURL string, projects []string) Message {
+	text := fmt.Sprintf("*%s* published <%s|their update for the week ending %s>", escape(author), entryURL, date)
+	if len(projects) > 0 {
+		escaped := []string{}
+		for _, p := range projects {
+			escaped = append(escaped, escape(p))
+		}
+		text += "\nProjects: " + strings.Join(escaped, ", ")
+	}
+	return Message{Text: text}
+}
+
+// Post sends a message to the given incoming webhook URL.
+func (p Poster) Post(webhookURL string, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	resp, err := p.Client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("incoming webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// escape encodes the characters that have special meaning in Slack's message
+// formatting.
+func escape(s string) string {
+	s = strings.Replace(s, "&", "&amp;", -1)
+	s = strings.Replace(s, "<", "&lt;", -1)
+	s = strings.Replace(s, ">", "&gt;", -1)
+	return s
+}
@@ -0,0 +1,65 @@
+package slack
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEntryMessage(t *testing.T) {
+	var tests = []struct {
+		explanation  string
+		projects     []string
+		textExpected string
+	}{
+		{
+			"lists project headings",
+			[]string{"Billing", "Hiring"},
+			"*alice* published <https://whatgotdone.com/alice/2019-11-29|their update for the week ending 2019-11-29>\nProjects: Billing, Hiring",
+		},
+		{
+			"omits projects line when entry has no headings",
+			[]string{},
+			"*alice* published <https://whatgotdone.com/alice/2019-11-29|their update for the week ending 2019-11-29>",
+		},
+		{
+			"escapes Slack control characters",
+			[]string{"R&D <internal>"},
+			"*alice* published <https://whatgotdone.com/alice/2019-11-29|their update for the week ending 2019-11-29>\nProjects: R&amp;D &lt;internal&gt;",
+		},
+	}
+	for _, tt := range tests {
+		m := EntryMessage("alice", "2019-11-29", "https://whatgotdone.com/alice/2019-11-29", tt.projects)
+		if m.Text != tt.textExpected {
+			t.Errorf("%s: got %q, want %q", tt.explanation, m.Text, tt.textExpected)
+		}
+	}
+}
+
+func TestPost(t *testing.T) {
+	var received Message
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		if received.Text == "fail" {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer stub.Close()
+
+	// The stub listens on loopback, which the outbound client refuses.
+	p := Poster{Client: stub.Client()}
+	if err := p.Post(stub.URL, Message{Text: "hello"}); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if received.Text != "hello" {
+		t.Fatalf("stub received unexpected message: %+v", received)
+	}
+	if err := p.Post(stub.URL, Message{Text: "fail"}); err == nil {
+		t.Fatalf("expected error when webhook rejects the message")
+	}
+}
@@ -0,0 +1,138 @@
+// Package trending ranks recently published entries by their page views and
+// reactions.
+package trending
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+const (
+	// windowDays is how far back to look for entries to rank. Older entries
+	// never trend, no matter how popular they are.
+	windowDays = 28
+	// reactionWeight is how many page views a single reaction is worth.
+	reactionWeight = 5
+	// gravity controls how quickly an entry's score decays with age. Higher
+	// values favor newer entries.
+	gravity = 1.5
+	// maxEntries is the number of top-ranked entries to keep, which limits the
+	// size of the cached ranking.
+	maxEntries = 500
+)
+
+// Ranker calculates the trending ranking and caches it in the datastore.
+type Ranker struct {
+	datastore     datastore.Datastore
+	minimumLength int
+}
+
+// New creates a Ranker that ignores entries shorter than minimumLength.
+func New(ds datastore.Datastore, minimumLength int) Ranker {
+	return Ranker{
+		datastore:     ds,
+		minimumLength: minimumLength,
+	}
+}
+
+// Update ranks every entry published in the last four weeks, regardless of
+// who can read it, and caches the ranking. Readers of the ranking must filter
+// out entries that the viewer isn't allowed to read.
+func (r Ranker) Update(now time.Time) error {
+	users, err := r.datastore.Users()
+	if err != nil {
+		return err
+	}
+	oldest := now.AddDate(0, 0, -windowDays).Format("2006-01-02")
+	entries := []types.TrendingEntry{}
+	for _, username := range users {
+		userEntries, err := r.datastore.GetEntries(username)
+		if err != nil {
+			return err
+		}
+		for _, j := range userEntries {
+			if j.Date < oldest || len(j.Markdown) < r.minimumLength {
+				continue
+			}
+			e, err := r.scoreEntry(username, j, now)
+			if err != nil {
+				// Log and continue so that one bad entry doesn't keep the others
+				// from trending.
+				log.Printf("Failed to score entry %s/%s: %s", username, j.Date, err)
+				continue
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	entries = sortEntries(entries)
+	if len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+	return r.datastore.SetTrendingEntries(types.TrendingEntries{
+		ComputedAt: now.UTC().Format(time.RFC3339),
+		Entries:    entries,
+	})
+}
+
+func (r Ranker) scoreEntry(author string, j types.JournalEntry, now time.Time) (types.TrendingEntry, error) {
+	views, err := r.datastore.GetPageViews("/" + author + "/" + j.Date)
+	if _, ok := err.(datastore.PageViewsNotFoundError); ok {
+		views = 0
+	} else if err != nil {
+		return types.TrendingEntry{}, err
+	}
+
+	reactions, err := r.datastore.GetReactions(author, j.Date)
+	if err != nil {
+		return types.TrendingEntry{}, err
+	}
+	reactionCount := 0
+	for _, reaction := range reactions {
+		// Skip cleared reactions and authors' reactions to their own entries.
+		if reaction.Symbol != "" && reaction.Username != author {
+			reactionCount++
+		}
+	}
+
+	published, err := time.Parse("2006-01-02", j.Date)
+	if err != nil {
+		return types.TrendingEntry{}, err
+	}
+	return types.TrendingEntry{
+		Author:    author,
+		Date:      j.Date,
+		Views:     views,
+		Reactions: reactionCount,
+		Score:     score(views, reactionCount, now.Sub(published)),
+	}, nil
+}
+
+// score ranks an entry by its engagement, discounted by its age. The decay is
+// steep enough that a new entry with modest engagement outranks an older entry
+// that has had weeks to accumulate views.
+func score(views int, reactions int, age time.Duration) float64 {
+	days := math.Max(0, age.Hours()/24)
+	engagement := float64(views + reactionWeight*reactions)
+	return engagement / math.Pow(days+2, gravity)
+}
+
+// sortEntries sorts entries so that the highest scores come first. Entries
+// with the same score are ordered by newest date first.
+func sortEntries(entries []types.TrendingEntry) []types.TrendingEntry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date > entries[j].Date
+		}
+		return entries[i].Author < entries[j].Author
+	})
+	return entries
+}
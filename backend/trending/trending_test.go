@@ -0,0 +1,112 @@
+package trending
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that the ranker calls.
+	datastore.Datastore
+	journalEntries map[string][]types.JournalEntry
+	pageViews      map[string]int
+	reactions      map[string][]types.Reaction
+	trending       *types.TrendingEntries
+}
+
+func (ds mockDatastore) Users() ([]string, error) {
+	users := []string{}
+	for username := range ds.journalEntries {
+		users = append(users, username)
+	}
+	return users, nil
+}
+
+func (ds mockDatastore) GetEntries(username string) ([]types.JournalEntry, error) {
+	return ds.journalEntries[username], nil
+}
+
+func (ds mockDatastore) GetPageViews(path string) (int, error) {
+	views, ok := ds.pageViews[path]
+	if !ok {
+		return 0, datastore.PageViewsNotFoundError{Path: path}
+	}
+	return views, nil
+}
+
+func (ds mockDatastore) GetReactions(entryAuthor string, entryDate string) ([]types.Reaction, error) {
+	return ds.reactions[entryAuthor+"/"+entryDate], nil
+}
+
+func (ds mockDatastore) SetTrendingEntries(t types.TrendingEntries) error {
+	*ds.trending = t
+	return nil
+}
+
+func TestScore(t *testing.T) {
+	day := 24 * time.Hour
+	if score(10, 0, day) <= score(5, 0, day) {
+		t.Errorf("expected more views to score higher")
+	}
+	if score(0, 1, day) != score(reactionWeight, 0, day) {
+		t.Errorf("expected a reaction to be worth %d views", reactionWeight)
+	}
+	if score(10, 0, 14*day) >= score(10, 0, day) {
+		t.Errorf("expected older entries to score lower")
+	}
+	if score(10, 0, -day) != score(10, 0, 0) {
+		t.Errorf("expected entries dated in the future to score as if they were new")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	now := time.Date(2019, 12, 6, 12, 0, 0, 0, time.UTC)
+	ds := mockDatastore{
+		journalEntries: map[string][]types.JournalEntry{
+			"alice": {
+				{Date: "2019-12-05", Markdown: "Wrote a short story about a lighthouse"},
+				{Date: "2019-11-28", Markdown: "Launched my new website to the world"},
+				{Date: "2019-12-04", Markdown: "Too short"},
+				{Date: "2019-10-04", Markdown: "Wrote a viral post that everyone read"},
+			},
+			"bob": {
+				{Date: "2019-11-21", Markdown: "Repainted the kitchen a nice shade of blue"},
+			},
+		},
+		pageViews: map[string]int{
+			"/alice/2019-12-05": 10,
+			"/alice/2019-11-28": 200,
+			"/alice/2019-12-04": 1000,
+			"/alice/2019-10-04": 100000,
+		},
+		reactions: map[string][]types.Reaction{
+			"alice/2019-12-05": {
+				{Username: "bob", Symbol: "👍"},
+				{Username: "alice", Symbol: "🎉"},
+				{Username: "carol", Symbol: ""},
+			},
+		},
+		trending: &types.TrendingEntries{},
+	}
+
+	if err := New(ds, 30).Update(now); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	expected := types.TrendingEntries{
+		ComputedAt: "2019-12-06T12:00:00Z",
+		Entries: []types.TrendingEntry{
+			{Author: "alice", Date: "2019-11-28", Views: 200, Reactions: 0, Score: score(200, 0, 8*24*time.Hour+12*time.Hour)},
+			{Author: "alice", Date: "2019-12-05", Views: 10, Reactions: 1, Score: score(10, 1, 36*time.Hour)},
+			{Author: "bob", Date: "2019-11-21", Views: 0, Reactions: 0, Score: 0},
+		},
+	}
+	if !reflect.DeepEqual(*ds.trending, expected) {
+		t.Errorf("got %+v, want %+v", *ds.trending, expected)
+	}
+}
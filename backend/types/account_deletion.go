@@ -0,0 +1,32 @@
+package types
+
+// AccountDeletionStatus is the progress of a request to delete an account.
+type AccountDeletionStatus string
+
+const (
+	// AccountDeletionPending means that the user requested deletion but has not
+	// yet confirmed it.
+	AccountDeletionPending AccountDeletionStatus = "pending"
+	// AccountDeletionConfirmed means that the user confirmed deletion and their
+	// data is waiting to be purged.
+	AccountDeletionConfirmed AccountDeletionStatus = "confirmed"
+	// AccountDeletionCompleted means that the user's data has been purged.
+	AccountDeletionCompleted AccountDeletionStatus = "completed"
+)
+
+// AccountDeletion is a request to delete a user's account and all their data.
+// It remains in the datastore after the purge as an audit record.
+type AccountDeletion struct {
+	ID       string                `json:"id" firestore:"id,omitempty"`
+	Username string                `json:"-" firestore:"username,omitempty"`
+	Status   AccountDeletionStatus `json:"status" firestore:"status,omitempty"`
+	// ConfirmationToken is the secret that the user must present to confirm the
+	// request.
+	ConfirmationToken     string `json:"-" firestore:"confirmationToken,omitempty"`
+	ConfirmationExpiresAt string `json:"confirmationExpiresAt,omitempty" firestore:"confirmationExpiresAt,omitempty"`
+	RequestedAt           string `json:"requestedAt" firestore:"requestedAt,omitempty"`
+	ConfirmedAt           string `json:"confirmedAt,omitempty" firestore:"confirmedAt,omitempty"`
+	CompletedAt           string `json:"completedAt,omitempty" firestore:"completedAt,omitempty"`
+	// Deleted counts the records that the purge removed, by kind.
+	Deleted map[string]int `json:"deleted,omitempty" firestore:"deleted,omitempty"`
+}
@@ -0,0 +1,26 @@
+package types
+
+// Comment represents a reader's comment on a published journal entry.
+// Comments form threads through ParentID.
+type Comment struct {
+	ID string `json:"id" firestore:"id,omitempty"`
+	// Username is the comment's author, which may differ from the entry's
+	// author.
+	Username string `json:"username" firestore:"username,omitempty"`
+	// ParentID is the ID of the comment that this comment replies to, or empty
+	// for top-level comments.
+	ParentID     string `json:"parentId,omitempty" firestore:"parentId,omitempty"`
+	Markdown     string `json:"markdown" firestore:"markdown,omitempty"`
+	Timestamp    string `json:"timestamp" firestore:"timestamp,omitempty"`
+	LastModified string `json:"lastModified,omitempty" firestore:"lastModified,omitempty"`
+	// Deleted comments remain in storage without their content so that their
+	// replies stay in the thread.
+	Deleted bool `json:"deleted,omitempty" firestore:"deleted,omitempty"`
+}
+
+// EntryComment is a comment along with the journal entry that it comments on.
+type EntryComment struct {
+	EntryAuthor string `json:"entryAuthor"`
+	EntryDate   string `json:"entryDate"`
+	Comment
+}
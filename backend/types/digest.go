@@ -0,0 +1,24 @@
+package types
+
+// DigestFrequency represents how often a user receives an email digest.
+type DigestFrequency string
+
+const (
+	// DigestDaily digests summarize the previous day's entries.
+	DigestDaily DigestFrequency = "daily"
+	// DigestWeekly digests summarize the previous week's entries.
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// DigestSubscription represents a user's subscription to an email digest of
+// other users' entries.
+type DigestSubscription struct {
+	Username  string          `json:"-" firestore:"username,omitempty"`
+	Frequency DigestFrequency `json:"frequency" firestore:"frequency,omitempty"`
+	// Authors are the users whose entries appear in the digest. If empty, the
+	// digest includes everyone the subscriber follows.
+	Authors []string `json:"authors" firestore:"authors"`
+	// LastSentAt is the time (in RFC3339 format) of the most recent digest,
+	// which marks the start of the next digest's time window.
+	LastSentAt string `json:"-" firestore:"lastSentAt,omitempty"`
+}
@@ -0,0 +1,7 @@
+package types
+
+// EntryRef identifies a published journal entry by its author and date.
+type EntryRef struct {
+	Author string `json:"author" firestore:"author,omitempty"`
+	Date   string `json:"date" firestore:"date,omitempty"`
+}
@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// JobLease grants one server instance the exclusive right to run a scheduled
+// job until the lease expires.
+type JobLease struct {
+	Job    string    `firestore:"job,omitempty"`
+	Holder string    `firestore:"holder,omitempty"`
+	Until  time.Time `firestore:"until"`
+}
+
+// JobStatus describes the most recent runs of a scheduled job.
+type JobStatus struct {
+	Name string `json:"name" firestore:"name,omitempty"`
+	// Interval is how often the job runs, as a Go duration string.
+	Interval string `json:"interval" firestore:"interval,omitempty"`
+	// LastStarted, LastFinished and LastSucceeded are timestamps in RFC3339
+	// format, or empty strings if the job hasn't reached that point yet.
+	LastStarted   string `json:"lastStarted" firestore:"lastStarted,omitempty"`
+	LastFinished  string `json:"lastFinished" firestore:"lastFinished,omitempty"`
+	LastSucceeded string `json:"lastSucceeded" firestore:"lastSucceeded,omitempty"`
+	// LastError is the error from the most recent run if it failed. Clients
+	// don't receive it because errors can contain internal details.
+	LastError string `json:"-" firestore:"lastError"`
+	// Failing is true if the most recent run failed.
+	Failing bool `json:"failing" firestore:"failing"`
+	// Instance identifies the server instance that ran the job most recently.
+	Instance string `json:"-" firestore:"instance,omitempty"`
+}
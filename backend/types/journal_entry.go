@@ -3,7 +3,15 @@ package types
 // JournalEntry represents a user's What Got Done update. The entry can be
 // public or a private draft that has not yet been published.
 type JournalEntry struct {
-	Date         string `json:"date" firestore:"date,omitempty"`
-	LastModified string `json:"lastModified" firestore:"lastModified,omitempty"`
-	Markdown     string `json:"markdown" firestore:"markdown,omitempty"`
+	Date         string     `json:"date" firestore:"date,omitempty"`
+	LastModified string     `json:"lastModified" firestore:"lastModified,omitempty"`
+	Markdown     string     `json:"markdown" firestore:"markdown,omitempty"`
+	Visibility   Visibility `json:"visibility,omitempty" firestore:"visibility,omitempty"`
+	// TeamID is the team that can read the entry when its visibility is
+	// VisibilityTeam.
+	TeamID string `json:"teamId,omitempty" firestore:"teamId,omitempty"`
+	// Mentions are the users whom the entry mentions (e.g., @alice). They
+	// include only usernames that belonged to existing users when the entry was
+	// published.
+	Mentions []string `json:"mentions,omitempty" firestore:"mentions,omitempty"`
 }
@@ -0,0 +1,41 @@
+package types
+
+// NotificationType represents the kind of event that generated a
+// notification.
+type NotificationType string
+
+const (
+	// NotificationReaction notifications occur when a user reacts to an entry.
+	NotificationReaction NotificationType = "reaction"
+	// NotificationMention notifications occur when a user mentions another user
+	// in an entry.
+	NotificationMention NotificationType = "mention"
+)
+
+// Notification represents an event that a user should know about, such as
+// another user reacting to their entry.
+type Notification struct {
+	ID        string           `json:"id" firestore:"id,omitempty"`
+	Recipient string           `json:"-" firestore:"recipient,omitempty"`
+	Type      NotificationType `json:"type" firestore:"type,omitempty"`
+	// Actor is the user whose action generated the notification.
+	Actor       string `json:"actor" firestore:"actor,omitempty"`
+	EntryAuthor string `json:"entryAuthor" firestore:"entryAuthor,omitempty"`
+	EntryDate   string `json:"entryDate" firestore:"entryDate,omitempty"`
+	// Symbol is the reaction symbol for reaction notifications.
+	Symbol    string `json:"symbol,omitempty" firestore:"symbol,omitempty"`
+	Timestamp string `json:"timestamp" firestore:"timestamp,omitempty"`
+	Read      bool   `json:"read" firestore:"read"`
+	// Emailed is true once the notification has been included in an email to
+	// the recipient.
+	Emailed bool `json:"-" firestore:"emailed"`
+}
+
+// NotificationPreferences represents a user's settings for how they receive
+// notifications.
+type NotificationPreferences struct {
+	Username string `json:"-" firestore:"username,omitempty"`
+	// EmailEnabled is true if the user wants unread notifications batched into
+	// periodic emails.
+	EmailEnabled bool `json:"emailEnabled" firestore:"emailEnabled"`
+}
@@ -0,0 +1,19 @@
+package types
+
+// DailyPageViews represents the number of times readers viewed a What Got Done
+// page on a single day.
+type DailyPageViews struct {
+	Path string `json:"path" firestore:"path,omitempty"`
+	// Date is the day of the views in YYYY-MM-DD format (UTC).
+	Date  string `json:"date" firestore:"date,omitempty"`
+	Views int    `json:"views" firestore:"views"`
+}
+
+// PageViewSync records how far the server has copied page views from its
+// analytics source into the datastore.
+type PageViewSync struct {
+	// LastSyncedDate is the most recent day in YYYY-MM-DD format (UTC) that the
+	// server has copied page views for. Views on that day might have been
+	// incomplete at the time.
+	LastSyncedDate string `json:"lastSyncedDate" firestore:"lastSyncedDate,omitempty"`
+}
@@ -6,3 +6,10 @@ type Reaction struct {
 	Symbol    string `json:"symbol" firestore:"symbol,omitempty"`
 	Timestamp string `json:"timestamp" firestore:"timestamp,omitempty"`
 }
+
+// EntryReaction is a reaction along with the journal entry that it reacts to.
+type EntryReaction struct {
+	EntryAuthor string `json:"entryAuthor"`
+	EntryDate   string `json:"entryDate"`
+	Reaction
+}
@@ -0,0 +1,17 @@
+package types
+
+// ReminderPreferences represents a user's settings for weekly email reminders
+// to publish their What Got Done update.
+type ReminderPreferences struct {
+	Username string `json:"-" firestore:"username,omitempty"`
+	Enabled  bool   `json:"enabled" firestore:"enabled"`
+	// SendTime is the time of day (in HH:MM format) to send the reminder on
+	// Fridays.
+	SendTime string `json:"sendTime" firestore:"sendTime,omitempty"`
+	// TimeZone is the IANA time zone name (e.g., America/New_York) for
+	// SendTime.
+	TimeZone string `json:"timeZone" firestore:"timeZone,omitempty"`
+	// LastSentFor is the entry date of the most recent reminder we sent, which
+	// prevents duplicate reminders for the same week.
+	LastSentFor string `json:"-" firestore:"lastSentFor,omitempty"`
+}
@@ -0,0 +1,23 @@
+package types
+
+import "fmt"
+
+// SlackIntegration posts a user's or team's new entries to a Slack-compatible
+// incoming webhook.
+type SlackIntegration struct {
+	// Owner identifies the user or team that the integration belongs to. Use
+	// UserSlackOwner or TeamSlackOwner to create it.
+	Owner      string `json:"-" firestore:"owner,omitempty"`
+	WebhookURL string `json:"-" firestore:"webhookUrl,omitempty"`
+	CreatedBy  string `json:"createdBy" firestore:"createdBy,omitempty"`
+}
+
+// UserSlackOwner returns the Slack integration owner for a user's own entries.
+func UserSlackOwner(username string) string {
+	return fmt.Sprintf("user:%s", username)
+}
+
+// TeamSlackOwner returns the Slack integration owner for a team's channel.
+func TeamSlackOwner(teamID string) string {
+	return fmt.Sprintf("team:%s", teamID)
+}
@@ -0,0 +1,49 @@
+package types
+
+// TeamRole represents a team member's permissions within a team.
+type TeamRole string
+
+const (
+	// TeamRoleAdmin members can invite users, change roles, and remove members.
+	TeamRoleAdmin TeamRole = "admin"
+	// TeamRoleMember members can read the team's entries and publish entries
+	// visible only to the team.
+	TeamRoleMember TeamRole = "member"
+)
+
+// TeamMember represents a user's membership in a team.
+type TeamMember struct {
+	Username string   `json:"username" firestore:"username,omitempty"`
+	Role     TeamRole `json:"role" firestore:"role,omitempty"`
+}
+
+// Team represents a group of What Got Done users who share updates with each
+// other.
+type Team struct {
+	ID      string       `json:"id" firestore:"id,omitempty"`
+	Name    string       `json:"name" firestore:"name,omitempty"`
+	Members []TeamMember `json:"members" firestore:"members,omitempty"`
+	// Invitees are users whom a team admin has invited but who have not yet
+	// joined the team.
+	Invitees []string `json:"invitees" firestore:"invitees,omitempty"`
+	// ReactionSymbols overrides the site's reaction symbols for entries shared
+	// with the team. If empty, the team uses the site's reaction symbols.
+	ReactionSymbols []string `json:"reactionSymbols,omitempty" firestore:"reactionSymbols,omitempty"`
+}
+
+// Member returns the membership record for the given user and whether the
+// user is a member of the team.
+func (t Team) Member(username string) (TeamMember, bool) {
+	for _, m := range t.Members {
+		if m.Username == username {
+			return m, true
+		}
+	}
+	return TeamMember{}, false
+}
+
+// IsAdmin returns true if the given user is an admin of the team.
+func (t Team) IsAdmin(username string) bool {
+	m, ok := t.Member(username)
+	return ok && m.Role == TeamRoleAdmin
+}
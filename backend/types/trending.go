@@ -0,0 +1,18 @@
+package types
+
+// TrendingEntry is a recently published entry's place in the trending ranking.
+type TrendingEntry struct {
+	Author    string  `json:"author" firestore:"author"`
+	Date      string  `json:"date" firestore:"date"`
+	Views     int     `json:"views" firestore:"views"`
+	Reactions int     `json:"reactions" firestore:"reactions"`
+	Score     float64 `json:"-" firestore:"score"`
+}
+
+// TrendingEntries is a ranking of recently published entries, with the highest
+// scores first.
+type TrendingEntries struct {
+	// ComputedAt is the time the ranking was calculated, in RFC3339 format.
+	ComputedAt string          `json:"computedAt" firestore:"computedAt,omitempty"`
+	Entries    []TrendingEntry `json:"entries" firestore:"entries"`
+}
@@ -5,4 +5,7 @@ type UserProfile struct {
 	AboutMarkdown string `json:"aboutMarkdown" firestore:"aboutMarkdown,omitempty"`
 	EmailAddress  string `json:"emailAddress" firestore:"emailAddress,omitempty"`
 	TwitterHandle string `json:"twitterHandle" firestore:"twitterHandle,omitempty"`
+	// DefaultVisibility applies to any of the user's entries that don't specify
+	// their own visibility.
+	DefaultVisibility Visibility `json:"defaultVisibility,omitempty" firestore:"defaultVisibility,omitempty"`
 }
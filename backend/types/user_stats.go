@@ -0,0 +1,48 @@
+package types
+
+// UserStats summarizes an author's publishing history and readership.
+type UserStats struct {
+	Username string `json:"-" firestore:"username,omitempty"`
+	// ComputedAt is the time the stats were calculated, in RFC3339 format.
+	ComputedAt string `json:"computedAt" firestore:"computedAt,omitempty"`
+	// EntryCount and LatestModified identify the version of the author's
+	// entries that the stats reflect.
+	EntryCount     int    `json:"entryCount" firestore:"entryCount"`
+	LatestModified string `json:"-" firestore:"latestModified,omitempty"`
+	// CurrentStreak is the number of consecutive weeks, up to the current week,
+	// for which the author published an entry. If the author hasn't yet
+	// published for the current week, the streak runs through the previous
+	// week.
+	CurrentStreak int `json:"currentStreak" firestore:"currentStreak"`
+	// LongestStreak is the largest number of consecutive weeks for which the
+	// author published an entry.
+	LongestStreak     int              `json:"longestStreak" firestore:"longestStreak"`
+	EntriesPerMonth   []MonthlyCount   `json:"entriesPerMonth" firestore:"entriesPerMonth"`
+	AverageEntryWords int              `json:"averageEntryWords" firestore:"averageEntryWords"`
+	TopProjects       []ProjectCount   `json:"topProjects" firestore:"topProjects"`
+	TotalPageViews    int              `json:"totalPageViews" firestore:"totalPageViews"`
+	EntryPageViews    []EntryPageViews `json:"entryPageViews" firestore:"entryPageViews"`
+	ReactionsReceived int              `json:"reactionsReceived" firestore:"reactionsReceived"`
+	ReactionsBySymbol map[string]int   `json:"reactionsBySymbol" firestore:"reactionsBySymbol"`
+}
+
+// MonthlyCount is the number of entries an author published in a month.
+type MonthlyCount struct {
+	// Month is in YYYY-MM format.
+	Month string `json:"month" firestore:"month"`
+	Count int    `json:"count" firestore:"count"`
+}
+
+// ProjectCount is the number of entries in which an author wrote about a
+// project.
+type ProjectCount struct {
+	Project string `json:"project" firestore:"project"`
+	Count   int    `json:"count" firestore:"count"`
+}
+
+// EntryPageViews is the number of times readers viewed one of an author's
+// entries.
+type EntryPageViews struct {
+	Date  string `json:"date" firestore:"date"`
+	Views int    `json:"views" firestore:"views"`
+}
@@ -0,0 +1,19 @@
+package types
+
+// Visibility controls which readers can see a published journal entry.
+type Visibility string
+
+const (
+	// VisibilityDefault means that an entry inherits its author's default
+	// visibility. When the author has no default, entries are public.
+	VisibilityDefault Visibility = ""
+	// VisibilityPublic entries are readable by anyone on the web.
+	VisibilityPublic Visibility = "public"
+	// VisibilitySignedIn entries are readable only by logged-in users.
+	VisibilitySignedIn Visibility = "signedIn"
+	// VisibilityPrivate entries are readable only by their author.
+	VisibilityPrivate Visibility = "private"
+	// VisibilityTeam entries are readable only by members of the team the
+	// author published them to.
+	VisibilityTeam Visibility = "team"
+)
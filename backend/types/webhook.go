@@ -0,0 +1,55 @@
+package types
+
+// WebhookEvent represents an event that triggers a webhook delivery.
+type WebhookEvent string
+
+const (
+	// WebhookEntryPublished events occur when a user publishes an entry for a
+	// new date.
+	WebhookEntryPublished WebhookEvent = "entry.published"
+	// WebhookEntryUpdated events occur when a user edits an entry they already
+	// published.
+	WebhookEntryUpdated WebhookEvent = "entry.updated"
+	// WebhookReactionAdded events occur when a reader reacts to one of the
+	// user's entries.
+	WebhookReactionAdded WebhookEvent = "reaction.added"
+)
+
+// Webhook represents a URL that receives a signed HTTP request when events
+// occur on a user's entries.
+type Webhook struct {
+	ID     string         `json:"id" firestore:"id,omitempty"`
+	Owner  string         `json:"-" firestore:"owner,omitempty"`
+	URL    string         `json:"url" firestore:"url,omitempty"`
+	Events []WebhookEvent `json:"events" firestore:"events"`
+	// Secret is the key that signs each delivery's payload so that receivers
+	// can verify that the request came from What Got Done.
+	Secret    string `json:"-" firestore:"secret,omitempty"`
+	CreatedAt string `json:"createdAt" firestore:"createdAt,omitempty"`
+}
+
+// Subscribes returns true if the webhook receives the given event.
+func (w Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records the outcome of sending an event to a webhook.
+type WebhookDelivery struct {
+	ID        string       `json:"id" firestore:"id,omitempty"`
+	WebhookID string       `json:"webhookId" firestore:"webhookId,omitempty"`
+	Event     WebhookEvent `json:"event" firestore:"event,omitempty"`
+	Timestamp string       `json:"timestamp" firestore:"timestamp,omitempty"`
+	// Attempts is the number of times we tried to deliver the event, including
+	// retries.
+	Attempts int `json:"attempts" firestore:"attempts"`
+	// StatusCode is the HTTP status of the final attempt, or zero if the
+	// receiver never responded.
+	StatusCode int    `json:"statusCode" firestore:"statusCode"`
+	Success    bool   `json:"success" firestore:"success"`
+	Error      string `json:"error,omitempty" firestore:"error,omitempty"`
+}
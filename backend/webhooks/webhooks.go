@@ -0,0 +1,197 @@
+// Package webhooks delivers signed event payloads to user-registered URLs.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/outbound"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+const (
+	// SignatureHeader contains the hex-encoded HMAC-SHA256 of the request body,
+	// keyed with the webhook's secret.
+	SignatureHeader = "X-WhatGotDone-Signature"
+	// EventHeader contains the name of the event that triggered the delivery.
+	EventHeader = "X-WhatGotDone-Event"
+	// DeliveryHeader contains the unique ID of the delivery, which stays the
+	// same across retries so that receivers can discard duplicates.
+	DeliveryHeader = "X-WhatGotDone-Delivery"
+)
+
+// deliveryLogSize is the number of recent deliveries to keep in each
+// webhook's delivery log.
+const deliveryLogSize = 100
+
+// Payload is the JSON body of each webhook request.
+type Payload struct {
+	ID        string             `json:"id"`
+	Event     types.WebhookEvent `json:"event"`
+	Timestamp string             `json:"timestamp"`
+	Data      interface{}        `json:"data"`
+}
+
+// Dispatcher sends events to the webhooks that subscribe to them.
+type Dispatcher struct {
+	datastore datastore.Datastore
+	// Client sends the webhook requests. By default, it only connects to public
+	// addresses and doesn't follow redirects.
+	Client *http.Client
+	// MaxAttempts is the number of times to try each delivery before giving up.
+	MaxAttempts int
+	// InitialBackoff is how long to wait before the first retry. The wait
+	// doubles after each subsequent failure.
+	InitialBackoff time.Duration
+}
+
+// New creates a Dispatcher that looks up webhooks in the given datastore.
+func New(ds datastore.Datastore) *Dispatcher {
+	return &Dispatcher{
+		datastore:      ds,
+		Client:         outbound.NewClient(10 * time.Second),
+		MaxAttempts:    5,
+		InitialBackoff: 5 * time.Second,
+	}
+}
+
+// NewWebhook creates a webhook with a random ID and signing secret.
+func NewWebhook(owner string, url string, events []types.WebhookEvent) (types.Webhook, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return types.Webhook{}, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return types.Webhook{}, err
+	}
+	return types.Webhook{
+		ID:        id,
+		Owner:     owner,
+		URL:       url,
+		Events:    events,
+		Secret:    secret,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// Sign returns the signature of a payload body for the given webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatch sends an event to each of the owner's webhooks that subscribe to
+// it. Deliveries happen in the background so that slow receivers don't delay
+// the caller.
+func (d *Dispatcher) Dispatch(owner string, event types.WebhookEvent, data interface{}) {
+	hooks, err := d.datastore.GetWebhooks(owner)
+	if err != nil {
+		log.Printf("Failed to retrieve webhooks for %s: %s", owner, err)
+		return
+	}
+	for _, hook := range hooks {
+		if !hook.Subscribes(event) {
+			continue
+		}
+		go func(hook types.Webhook) {
+			if _, err := d.Deliver(hook, event, data); err != nil {
+				log.Printf("Failed to deliver %s event to webhook %s: %s", event, hook.ID, err)
+			}
+		}(hook)
+	}
+}
+
+// Deliver sends an event to a single webhook, retrying with exponential
+// backoff until the receiver responds with a 2xx status or the delivery runs
+// out of attempts. It records the outcome in the webhook's delivery log and
+// drops the log's oldest records beyond deliveryLogSize.
+func (d *Dispatcher) Deliver(hook types.Webhook, event types.WebhookEvent, data interface{}) (types.WebhookDelivery, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return types.WebhookDelivery{}, err
+	}
+	now := time.Now().Format(time.RFC3339)
+	body, err := json.Marshal(Payload{
+		ID:        id,
+		Event:     event,
+		Timestamp: now,
+		Data:      data,
+	})
+	if err != nil {
+		return types.WebhookDelivery{}, err
+	}
+
+	delivery := types.WebhookDelivery{
+		ID:        id,
+		WebhookID: hook.ID,
+		Event:     event,
+		Timestamp: now,
+	}
+	backoff := d.InitialBackoff
+	for delivery.Attempts < d.MaxAttempts {
+		if delivery.Attempts > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		delivery.Attempts++
+		delivery.StatusCode, err = d.post(hook, id, event, body)
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+	}
+
+	if err := d.datastore.AddWebhookDelivery(delivery); err != nil {
+		return delivery, err
+	}
+	if err := d.datastore.PruneWebhookDeliveries(hook.ID, deliveryLogSize); err != nil {
+		return delivery, err
+	}
+	if !delivery.Success {
+		return delivery, fmt.Errorf("gave up after %d attempts: %s", delivery.Attempts, delivery.Error)
+	}
+	return delivery, nil
+}
+
+func (d *Dispatcher) post(hook types.Webhook, deliveryID string, event types.WebhookEvent, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WhatGotDone-Webhook/1.0")
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	req.Header.Set(EventHeader, string(event))
+	req.Header.Set(DeliveryHeader, deliveryID)
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
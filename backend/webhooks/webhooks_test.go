@@ -0,0 +1,289 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mtlynch/whatgotdone/backend/datastore"
+	"github.com/mtlynch/whatgotdone/backend/types"
+)
+
+type mockDatastore struct {
+	// Embed the interface so that the mock only needs to implement the methods
+	// that the dispatcher calls.
+	datastore.Datastore
+	hooks      []types.Webhook
+	mu         *sync.Mutex
+	deliveries *[]types.WebhookDelivery
+}
+
+func (ds mockDatastore) GetWebhooks(owner string) ([]types.Webhook, error) {
+	hooks := []types.Webhook{}
+	for _, hook := range ds.hooks {
+		if hook.Owner == owner {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (ds mockDatastore) AddWebhookDelivery(d types.WebhookDelivery) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	*ds.deliveries = append(*ds.deliveries, d)
+	return nil
+}
+
+func (ds mockDatastore) PruneWebhookDeliveries(webhookID string, keep int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	sort.Slice(*ds.deliveries, func(i, j int) bool {
+		return (*ds.deliveries)[i].Timestamp > (*ds.deliveries)[j].Timestamp
+	})
+	kept := []types.WebhookDelivery{}
+	for _, d := range *ds.deliveries {
+		if d.WebhookID == webhookID {
+			if keep == 0 {
+				continue
+			}
+			keep--
+		}
+		kept = append(kept, d)
+	}
+	*ds.deliveries = kept
+	return nil
+}
+
+func newMockDatastore(hooks []types.Webhook) mockDatastore {
+	return mockDatastore{
+		hooks:      hooks,
+		mu:         &sync.Mutex{},
+		deliveries: &[]types.WebhookDelivery{},
+	}
+}
+
+type receivedRequest struct {
+	signature string
+	event     string
+	delivery  string
+	body      []byte
+}
+
+// receiver is a local HTTP server that records webhook requests and fails the
+// first few of them.
+type receiver struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	failures int
+	requests []receivedRequest
+}
+
+func newReceiver(failures int) *receiver {
+	r := &receiver{failures: failures}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, receivedRequest{
+			signature: req.Header.Get(SignatureHeader),
+			event:     req.Header.Get(EventHeader),
+			delivery:  req.Header.Get(DeliveryHeader),
+			body:      body,
+		})
+		if len(r.requests) <= r.failures {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return r
+}
+
+func newTestDispatcher(ds mockDatastore) *Dispatcher {
+	d := New(ds)
+	// The test receivers listen on loopback, which the outbound client refuses.
+	d.Client = &http.Client{Timeout: time.Second}
+	d.MaxAttempts = 3
+	d.InitialBackoff = time.Millisecond
+	return d
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	r := newReceiver(0)
+	defer r.server.Close()
+
+	hook := types.Webhook{ID: "hook1", Owner: "alice", URL: r.server.URL, Secret: "dummy-secret", Events: []types.WebhookEvent{types.WebhookEntryPublished}}
+	ds := newMockDatastore([]types.Webhook{hook})
+	d := newTestDispatcher(ds)
+
+	delivery, err := d.Deliver(hook, types.WebhookEntryPublished, map[string]string{"author": "alice", "date": "2019-11-29"})
+	if err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+	if !delivery.Success || delivery.Attempts != 1 || delivery.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected delivery record: %+v", delivery)
+	}
+	if len(r.requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(r.requests))
+	}
+	req := r.requests[0]
+	if req.signature != Sign("dummy-secret", req.body) {
+		t.Errorf("signature does not match body: got %s", req.signature)
+	}
+	if req.signature == Sign("wrong-secret", req.body) {
+		t.Errorf("signature should depend on the secret")
+	}
+	if req.event != "entry.published" || req.delivery != delivery.ID {
+		t.Errorf("unexpected headers: event=%s delivery=%s", req.event, req.delivery)
+	}
+	var p struct {
+		ID    string            `json:"id"`
+		Event string            `json:"event"`
+		Data  map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &p); err != nil {
+		t.Fatalf("payload is not valid JSON: %s", req.body)
+	}
+	if p.ID != delivery.ID || p.Event != "entry.published" || p.Data["date"] != "2019-11-29" {
+		t.Errorf("unexpected payload: %s", req.body)
+	}
+	if len(*ds.deliveries) != 1 || (*ds.deliveries)[0].ID != delivery.ID {
+		t.Errorf("expected delivery to be logged, got %+v", *ds.deliveries)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	var tests = []struct {
+		explanation        string
+		failures           int
+		successExpected    bool
+		attemptsExpected   int
+		statusCodeExpected int
+	}{
+		{"succeeds after transient failures", 2, true, 3, http.StatusNoContent},
+		{"gives up after max attempts", 5, false, 3, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		r := newReceiver(tt.failures)
+		hook := types.Webhook{ID: "hook1", Owner: "alice", URL: r.server.URL, Secret: "dummy-secret"}
+		ds := newMockDatastore([]types.Webhook{hook})
+		d := newTestDispatcher(ds)
+
+		delivery, err := d.Deliver(hook, types.WebhookReactionAdded, nil)
+		r.server.Close()
+
+		if (err == nil) != tt.successExpected {
+			t.Errorf("%s: unexpected error: %v", tt.explanation, err)
+		}
+		if delivery.Success != tt.successExpected || delivery.Attempts != tt.attemptsExpected || delivery.StatusCode != tt.statusCodeExpected {
+			t.Errorf("%s: unexpected delivery record: %+v", tt.explanation, delivery)
+		}
+		if len(r.requests) != tt.attemptsExpected {
+			t.Errorf("%s: expected %d requests, got %d", tt.explanation, tt.attemptsExpected, len(r.requests))
+		}
+		for _, req := range r.requests {
+			if req.delivery != delivery.ID {
+				t.Errorf("%s: retries should reuse the delivery ID", tt.explanation)
+			}
+		}
+		if len(*ds.deliveries) != 1 {
+			t.Errorf("%s: expected one delivery log entry, got %d", tt.explanation, len(*ds.deliveries))
+		}
+	}
+}
+
+func TestDeliverPrunesDeliveryLog(t *testing.T) {
+	r := newReceiver(0)
+	defer r.server.Close()
+
+	hook := types.Webhook{ID: "hook1", Owner: "alice", URL: r.server.URL, Secret: "dummy-secret"}
+	ds := newMockDatastore([]types.Webhook{hook})
+	for i := 0; i < deliveryLogSize; i++ {
+		*ds.deliveries = append(*ds.deliveries, types.WebhookDelivery{
+			ID:        fmt.Sprintf("old-%d", i),
+			WebhookID: "hook1",
+			Timestamp: time.Date(2019, 11, 29, 0, 0, i, 0, time.UTC).Format(time.RFC3339),
+		})
+	}
+	*ds.deliveries = append(*ds.deliveries, types.WebhookDelivery{ID: "other", WebhookID: "hook2", Timestamp: "2019-11-28T00:00:00Z"})
+	d := newTestDispatcher(ds)
+
+	delivery, err := d.Deliver(hook, types.WebhookReactionAdded, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logged := map[string]bool{}
+	for _, d := range *ds.deliveries {
+		logged[d.ID] = true
+	}
+	if len(*ds.deliveries) != deliveryLogSize+1 {
+		t.Errorf("expected %d delivery log entries, got %d", deliveryLogSize+1, len(*ds.deliveries))
+	}
+	if !logged[delivery.ID] {
+		t.Errorf("expected the new delivery to be logged")
+	}
+	if logged["old-0"] {
+		t.Errorf("expected the oldest delivery to be pruned")
+	}
+	if !logged["other"] {
+		t.Errorf("pruning one webhook's log should not affect other webhooks")
+	}
+}
+
+func TestDispatchOnlySendsSubscribedEvents(t *testing.T) {
+	r := newReceiver(0)
+	defer r.server.Close()
+
+	ds := newMockDatastore([]types.Webhook{
+		types.Webhook{ID: "hook1", Owner: "alice", URL: r.server.URL, Events: []types.WebhookEvent{types.WebhookEntryPublished}},
+		types.Webhook{ID: "hook2", Owner: "alice", URL: r.server.URL, Events: []types.WebhookEvent{types.WebhookReactionAdded}},
+		types.Webhook{ID: "hook3", Owner: "bob", URL: r.server.URL, Events: []types.WebhookEvent{types.WebhookEntryPublished}},
+	})
+	d := newTestDispatcher(ds)
+	d.Dispatch("alice", types.WebhookEntryPublished, nil)
+
+	// Dispatch delivers in the background, so wait for the delivery log.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ds.mu.Lock()
+		n := len(*ds.deliveries)
+		ds.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for delivery")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Give any unexpected deliveries a chance to arrive.
+	time.Sleep(50 * time.Millisecond)
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if len(*ds.deliveries) != 1 || (*ds.deliveries)[0].WebhookID != "hook1" {
+		t.Fatalf("unexpected deliveries: %+v", *ds.deliveries)
+	}
+}
+
+func TestNewWebhookGeneratesUniqueSecrets(t *testing.T) {
+	a, err := NewWebhook("alice", "https://example.com/hook", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewWebhook("alice", "https://example.com/hook", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID == b.ID || a.Secret == b.Secret || len(a.Secret) != 64 {
+		t.Fatalf("expected unique IDs and 256-bit secrets: %+v %+v", a, b)
+	}
+}
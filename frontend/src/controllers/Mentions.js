@@ -0,0 +1,77 @@
+// Escape characters that have special meaning in regular expressions.
+function escapeRegExp(s) {
+  return s.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+// Matches a line that starts or ends a fenced code block: a run of at least
+// three backticks or tildes, indented by at most three spaces. This is the same
+// rule that the markdown renderer and the backend's codefence package follow.
+const fencePattern = /^ {0,3}(`{3,}|~{3,})(.*)$/;
+
+// Return the fence that starts a fenced code block on the given line, or an
+// empty string if the line doesn't start one.
+function openingFence(line) {
+  const match = fencePattern.exec(line);
+  if (!match) {
+    return '';
+  }
+  const [, fence, info] = match;
+  // A backtick fence's info string can't contain backticks.
+  if (fence[0] === '`' && info.includes('`')) {
+    return '';
+  }
+  return fence;
+}
+
+// Return true if the given line ends a fenced code block that started with
+// fence.
+function closesFence(line, fence) {
+  const match = fencePattern.exec(line);
+  if (!match) {
+    return false;
+  }
+  const [, closing, rest] = match;
+  return (
+    closing[0] === fence[0] &&
+    closing.length >= fence.length &&
+    rest.trim() === ''
+  );
+}
+
+// Replace @username mentions of the given users with links to their profiles.
+// Mentions inside fenced code blocks or inline code stay as they are.
+export function linkMentions(markdown, mentions) {
+  if (!mentions || mentions.length === 0) {
+    return markdown;
+  }
+  const pattern = new RegExp(
+    `(^|[^\\w@/.\\[])@(${mentions.map(escapeRegExp).join('|')})(?!\\w)`,
+    'g'
+  );
+  let fence = '';
+  return markdown
+    .split('\n')
+    .map(line => {
+      if (fence) {
+        if (closesFence(line, fence)) {
+          fence = '';
+        }
+        return line;
+      }
+      fence = openingFence(line);
+      if (fence) {
+        return line;
+      }
+      // Odd-numbered segments are inside inline code.
+      return line
+        .split('`')
+        .map((segment, i) => {
+          if (i % 2 === 1) {
+            return segment;
+          }
+          return segment.replace(pattern, '$1[@$2](/$2)');
+        })
+        .join('`');
+    })
+    .join('\n');
+}
@@ -33,10 +33,34 @@ export function getRecent(start, limit, callback) {
     const recentEntries = result.data.map(rawEntry => {
       return processEntry(rawEntry);
     });
-    callback(recentEntries);
+    addReactionCounts(recentEntries, () => {
+      callback(recentEntries);
+    });
   });
 }
 
+// Retrieve reaction counts for all entries in a single request.
+function addReactionCounts(entries, callback) {
+  if (entries.length === 0) {
+    callback();
+    return;
+  }
+  const keys = entries.map(entry => entry.key.slice(1)).join(',');
+  const url = `${process.env.VUE_APP_BACKEND_URL}/api/reactions/summary?entries=${keys}`;
+  axios
+    .get(url, {withCredentials: true})
+    .then(result => {
+      entries.forEach(entry => {
+        const summary = result.data[entry.key.slice(1)];
+        entry.reactionCounts = summary ? summary.counts : {};
+      });
+    })
+    .catch(() => {
+      // Ignore error for reactions, as they're non-essential.
+    })
+    .finally(callback);
+}
+
 function processEntry(entry) {
   const formattedDate = new Date(entry.date).toISOString().slice(0, 10);
   return {
@@ -44,6 +68,7 @@ function processEntry(entry) {
     author: entry.author,
     date: new Date(entry.date),
     markdown: entry.markdown,
+    reactionCounts: {},
   };
 }
 
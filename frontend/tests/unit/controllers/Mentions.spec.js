@@ -0,0 +1,40 @@
+import {linkMentions} from '@/controllers/Mentions.js';
+
+describe('linkMentions', () => {
+  test('links mentioned users', () => {
+    expect(linkMentions('Paired with @bob and @carol.', ['bob', 'carol'])).toBe(
+      'Paired with [@bob](/bob) and [@carol](/carol).'
+    );
+  });
+
+  test('ignores users who are not mentioned', () => {
+    expect(linkMentions('Thanks @bob and @nobody', ['bob'])).toBe(
+      'Thanks [@bob](/bob) and @nobody'
+    );
+  });
+
+  test('ignores partial usernames and email addresses', () => {
+    expect(linkMentions('@bobby wrote to alice@bob', ['bob'])).toBe(
+      '@bobby wrote to alice@bob'
+    );
+  });
+
+  test('ignores mentions in code', () => {
+    expect(linkMentions('```\n@bob\n```\n`@bob` and @bob', ['bob'])).toBe(
+      '```\n@bob\n```\n`@bob` and [@bob](/bob)'
+    );
+  });
+
+  test('ignores mentions in tilde and indented code fences', () => {
+    const markdown = '~~~\n@bob\n```\n@bob\n~~~\n  ```\n@bob\n  ```\n@bob';
+    expect(linkMentions(markdown, ['bob'])).toBe(
+      '~~~\n@bob\n```\n@bob\n~~~\n  ```\n@bob\n  ```\n[@bob](/bob)'
+    );
+  });
+
+  test('treats lines indented four spaces as text, not fences', () => {
+    expect(linkMentions('    ```\n@bob', ['bob'])).toBe(
+      '    ```\n[@bob](/bob)'
+    );
+  });
+});